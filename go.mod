@@ -1,13 +1,16 @@
 module github.com/hihoak/gocov
 
-go 1.12
+go 1.17
 
 require (
 	github.com/stretchr/testify v1.7.1
 	golang.org/x/tools v0.13.0
+	gopkg.in/yaml.v3 v3.0.0
 )
 
 require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/mod v0.20.0 // indirect
-	gopkg.in/yaml.v3 v3.0.0 // indirect
+	golang.org/x/sys v0.12.0 // indirect
 )
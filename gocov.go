@@ -23,6 +23,7 @@ package gocov
 
 import (
 	"fmt"
+	"sort"
 )
 
 type Package struct {
@@ -31,6 +32,48 @@ type Package struct {
 
 	// Functions is a list of functions registered with this package.
 	Functions []*Function
+
+	// Files optionally holds the coverprofile blocks recorded for each
+	// source file of the package, for consumers that need coverage at
+	// block rather than statement granularity.
+	Files []*File `json:",omitempty"`
+
+	// Language optionally names the language of the package's source
+	// files, such as "javascript", for coverage imported from other
+	// languages' tools; it is empty for Go. Languages are opaque to
+	// gocov, whose Go-specific analyses skip packages of other
+	// languages.
+	Language string `json:",omitempty"`
+}
+
+// IsGo reports whether the package's source files are Go.
+func (p *Package) IsGo() bool {
+	return p.Language == "" || p.Language == "go"
+}
+
+type File struct {
+	// File is the full path to the file.
+	File string
+
+	// Mode is the coverage mode of the profile: set, count or atomic.
+	Mode string `json:",omitempty"`
+
+	// Blocks are the file's coverprofile blocks, in profile order.
+	Blocks []*Block
+}
+
+type Block struct {
+	// StartLine and StartCol are the 1-based position of the block's start.
+	StartLine, StartCol int
+
+	// EndLine and EndCol are the 1-based position of the block's end.
+	EndLine, EndCol int
+
+	// NumStmt is the number of statements in the block.
+	NumStmt int
+
+	// Count is the number of times the block was executed.
+	Count int64
 }
 
 type Function struct {
@@ -38,6 +81,10 @@ type Function struct {
 	// name will be of the form T.N, where T is the type and N is the name.
 	Name string
 
+	// Parent is the name of the function enclosing this function, if it
+	// is a function literal.
+	Parent string `json:",omitempty"`
+
 	// File is the full path to the file in which the function is defined.
 	File string
 
@@ -47,10 +94,49 @@ type Function struct {
 	// End is the end offset of the function.
 	End int
 
+	// ID optionally holds the function's stable identity, which unlike
+	// its offsets survives declarations moving within their file, for
+	// matching the function across revisions; see gocovutil.FunctionID.
+	ID string `json:",omitempty"`
+
+	// The following fields are optionally recorded from type
+	// information when it is loaded during conversion.
+
+	// Receiver is the type of a method's receiver, qualified by import
+	// path, e.g. "*example.com/store.DB", with aliases resolved.
+	Receiver string `json:",omitempty"`
+
+	// Exported records whether the function is part of its package's
+	// API: an exported function, or an exported method in the method
+	// set of an exported type, including those promoted from embedded
+	// unexported types.
+	Exported *bool `json:",omitempty"`
+
+	// Implements lists the interfaces, declared by the method's package
+	// or by those it imports, that its receiver implements with it,
+	// e.g. "io.Reader".
+	Implements []string `json:",omitempty"`
+
 	// statements registered with this function.
 	Statements []*Statement
 }
 
+// SourceFile groups the functions of a package defined in one source file,
+// for consumers that report coverage per file.
+type SourceFile struct {
+	// File is the full path to the file.
+	File string
+
+	// Functions are the functions defined in the file, in package order.
+	Functions []*Function
+
+	// Statements is the total number of statements in the file's functions.
+	Statements int
+
+	// Reached is the number of those statements reached at least once.
+	Reached int
+}
+
 type Statement struct {
 	// Start is the start offset of the statement.
 	Start int
@@ -60,6 +146,28 @@ type Statement struct {
 
 	// Reached is the number of times the statement was reached.
 	Reached int64
+
+	// Kind optionally records the kind of the statement's syntax:
+	// "assign", "branch", "call", "decl", "defer", "expr", "for", "go",
+	// "if", "incdec", "range", "return", "select", "send", "switch" or
+	// "typeswitch". Statements standing for the header of a compound
+	// statement, such as an if statement's condition, have the compound
+	// statement's kind.
+	Kind string `json:",omitempty"`
+
+	// Attribution optionally records which inputs of a merge reached the
+	// statement: bit i is set if input i reached it at least once.
+	Attribution []uint64 `json:",omitempty"`
+
+	// Tags optionally holds labels attached to the statement when it was
+	// classified during conversion, e.g. "logging".
+	Tags []string `json:",omitempty"`
+
+	// Weight optionally holds the weight given to the statement when it
+	// was classified during conversion, for consumers such as policies
+	// that weigh statements unequally; zero means the default weight of
+	// one. gocov's own reports count each statement once.
+	Weight float64 `json:",omitempty"`
 }
 
 // Accumulate will accumulate the coverage information from the provided
@@ -77,6 +185,72 @@ func (p *Package) Accumulate(p2 *Package) error {
 			return err
 		}
 	}
+	return p.accumulateFiles(p2)
+}
+
+// SourceFiles returns the package's functions grouped by the file in which
+// they are defined, sorted by file name.
+func (p *Package) SourceFiles() []*SourceFile {
+	var files []*SourceFile
+	byName := make(map[string]*SourceFile)
+	for _, fn := range p.Functions {
+		file := byName[fn.File]
+		if file == nil {
+			file = &SourceFile{File: fn.File}
+			byName[fn.File] = file
+			files = append(files, file)
+		}
+		file.Functions = append(file.Functions, fn)
+		for _, s := range fn.Statements {
+			file.Statements++
+			if s.Reached > 0 {
+				file.Reached++
+			}
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].File < files[j].File })
+	return files
+}
+
+// accumulateFiles accumulates the block data of p2's files into p's,
+// adding any files p has no block data for.
+func (p *Package) accumulateFiles(p2 *Package) error {
+	for _, f2 := range p2.Files {
+		var file *File
+		for _, f := range p.Files {
+			if f.File == f2.File {
+				file = f
+				break
+			}
+		}
+		if file == nil {
+			p.Files = append(p.Files, f2)
+			continue
+		}
+		if err := file.Accumulate(f2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Accumulate will accumulate the block counts from the provided File into
+// this File.
+func (f *File) Accumulate(f2 *File) error {
+	if f.File != f2.File {
+		return fmt.Errorf("Files do not match: %q != %q", f.File, f2.File)
+	}
+	if len(f.Blocks) != len(f2.Blocks) {
+		return fmt.Errorf("Number of blocks do not match: %d != %d", len(f.Blocks), len(f2.Blocks))
+	}
+	for i, b := range f.Blocks {
+		b2 := f2.Blocks[i]
+		if b.StartLine != b2.StartLine || b.StartCol != b2.StartCol || b.EndLine != b2.EndLine || b.EndCol != b2.EndCol {
+			return fmt.Errorf("Block ranges do not match: %d.%d,%d.%d != %d.%d,%d.%d",
+				b.StartLine, b.StartCol, b.EndLine, b.EndCol, b2.StartLine, b2.StartCol, b2.EndLine, b2.EndCol)
+		}
+		b.Count += b2.Count
+	}
 	return nil
 }
 
@@ -104,6 +278,30 @@ func (f *Function) Accumulate(f2 *Function) error {
 	return nil
 }
 
+// UniquelyAttributedTo reports whether input i of a merge reached a
+// statement of this function that no other input reached.
+func (f *Function) UniquelyAttributedTo(i int) bool {
+	for _, s := range f.Statements {
+		if !s.AttributedTo(i) {
+			continue
+		}
+		others := false
+		for j, word := range s.Attribution {
+			if j == i/64 {
+				word &^= 1 << uint(i%64)
+			}
+			if word != 0 {
+				others = true
+				break
+			}
+		}
+		if !others {
+			return true
+		}
+	}
+	return false
+}
+
 // Accumulate will accumulate the coverage information from the provided
 // Statement into this Statement.
 func (s *Statement) Accumulate(s2 *Statement) error {
@@ -111,5 +309,28 @@ func (s *Statement) Accumulate(s2 *Statement) error {
 		return fmt.Errorf("Source ranges do not match: %d-%d != %d-%d", s.Start, s.End, s2.Start, s2.End)
 	}
 	s.Reached += s2.Reached
+	for i, word := range s2.Attribution {
+		if i < len(s.Attribution) {
+			s.Attribution[i] |= word
+		} else {
+			s.Attribution = append(s.Attribution, word)
+		}
+	}
 	return nil
 }
+
+// Attribute records that input i of a merge reached this statement.
+func (s *Statement) Attribute(i int) {
+	for len(s.Attribution) <= i/64 {
+		s.Attribution = append(s.Attribution, 0)
+	}
+	s.Attribution[i/64] |= 1 << uint(i%64)
+}
+
+// AttributedTo reports whether input i of a merge reached this statement.
+func (s *Statement) AttributedTo(i int) bool {
+	if i/64 >= len(s.Attribution) {
+		return false
+	}
+	return s.Attribution[i/64]&(1<<uint(i%64)) != 0
+}
@@ -21,17 +21,25 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"flag"
 	"fmt"
 	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"math"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
 
 	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+	goPackages "golang.org/x/tools/go/packages"
 )
 
 const (
@@ -50,8 +58,21 @@ var (
 	annotateColorFlag = annotateFlags.Bool(
 		"color", false,
 		"Differentiate coverage with color")
+	annotateMaxLineLengthFlag = annotateFlags.Int(
+		"max-line-length", 512,
+		"Truncate source lines longer than this many bytes (0 for no limit)")
+	annotateSourceRootFlag = annotateFlags.String(
+		"source-root", "",
+		"Read source files relative to this directory instead of the file system root")
+	annotateMmapFlag = annotateFlags.Bool(
+		"mmap", false,
+		"Memory-map source files instead of streaming them, where supported")
 )
 
+func init() {
+	annotateFlags.IntVar(&sourceReadJobs, "read-jobs", sourceReadJobs, "Read up to this many source files concurrently")
+}
+
 type packageList []*gocov.Package
 type functionList []*gocov.Function
 
@@ -72,16 +93,203 @@ func (l functionList) Len() int {
 }
 
 func (l functionList) Less(i, j int) bool {
-	return l[i].Name < l[j].Name
+	if l[i].Name != l[j].Name {
+		return l[i].Name < l[j].Name
+	}
+	return l[i].File < l[j].File
 }
 
 func (l functionList) Swap(i, j int) {
 	l[i], l[j] = l[j], l[i]
 }
 
+// functionSelector selects functions to annotate. A selector is a
+// regular expression matched against "package/function", or, to tell
+// apart functions of the same name defined in different files, of the
+// form file:function, where file is matched against "package/file" and
+// function against the function's name.
+type functionSelector struct {
+	file, name *regexp.Regexp
+
+	// methods, if not nil, holds the functions a Type.Method selector
+	// was resolved to with type information; see resolveMethods.
+	methods map[methodKey]bool
+}
+
+// methodSelector matches selectors of the form Type.Method.
+var methodSelector = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\.([A-Za-z_][A-Za-z0-9_]*)$`)
+
+// methodKey identifies a method by package, file base name and name, as
+// both coverage data and type information record them.
+type methodKey struct {
+	pkg, file, name string
+}
+
+// resolveMethods loads the named packages and resolves the selector
+// typ.method against the types of that name they define: to the method
+// of a concrete type, which may be promoted from an embedded type, or, if
+// typ is an interface, to the methods of the types implementing it.
+func resolveMethods(pkgNames []string, typ, method string) (map[methodKey]bool, error) {
+	pkgs, err := goPackages.Load(&goPackages.Config{
+		Mode: goPackages.NeedName | goPackages.NeedTypes | goPackages.NeedImports | goPackages.NeedDeps,
+	}, pkgNames...)
+	if err != nil {
+		return nil, fmt.Errorf("load packages: %v", err)
+	}
+	var fset *token.FileSet
+	var roots []*types.Package
+	for _, pkg := range pkgs {
+		if pkg.Types != nil {
+			fset = pkg.Fset
+			roots = append(roots, pkg.Types)
+		}
+	}
+	return methodsOf(fset, roots, typ, method), nil
+}
+
+// methodsOf returns the functions implementing method for the types named
+// typ defined by pkgs, as resolveMethods does. The implementations of an
+// interface are looked for among the types of pkgs.
+func methodsOf(fset *token.FileSet, pkgs []*types.Package, typ, method string) map[methodKey]bool {
+	methods := make(map[methodKey]bool)
+	add := func(t types.Type, pkg *types.Package) {
+		obj, _, _ := types.LookupFieldOrMethod(t, true, pkg, method)
+		fn, ok := obj.(*types.Func)
+		if !ok {
+			return
+		}
+		recv := fn.Type().(*types.Signature).Recv().Type()
+		if p, ok := recv.(*types.Pointer); ok {
+			recv = p.Elem()
+		}
+		named, ok := recv.(*types.Named)
+		if !ok || types.IsInterface(named) {
+			// Methods promoted from embedded interfaces have no
+			// implementation of their own.
+			return
+		}
+		methods[methodKey{
+			pkg:  fn.Pkg().Path(),
+			file: filepath.Base(fset.Position(fn.Pos()).Filename),
+			name: named.Obj().Name() + "." + fn.Name(),
+		}] = true
+	}
+	for _, pkg := range pkgs {
+		obj, ok := pkg.Scope().Lookup(typ).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		it, ok := obj.Type().Underlying().(*types.Interface)
+		if !ok {
+			add(obj.Type(), pkg)
+			continue
+		}
+		for _, impl := range pkgs {
+			scope := impl.Scope()
+			for _, name := range scope.Names() {
+				obj, ok := scope.Lookup(name).(*types.TypeName)
+				if !ok || obj.IsAlias() || types.IsInterface(obj.Type()) {
+					continue
+				}
+				if t := types.NewPointer(obj.Type()); types.Implements(t, it) {
+					add(obj.Type(), impl)
+				}
+			}
+		}
+	}
+	return methods
+}
+
+// fileSeparator returns the index of the colon separating the file
+// expression of a selector from its function expression, or -1 if there
+// is none. The colons of the expressions themselves, in groups such as
+// (?:Get|Set) and in character classes such as [[:upper:]], do not.
+func fileSeparator(arg string) int {
+	depth, class := 0, false
+	for i := 0; i < len(arg); i++ {
+		switch c := arg[i]; {
+		case c == '\\':
+			i++
+		case class && strings.HasPrefix(arg[i:], "[:"):
+			if j := strings.Index(arg[i+2:], ":]"); j >= 0 {
+				i += j + 3
+			}
+		case class:
+			class = c != ']'
+		case c == '[':
+			class = true
+			// A ] opening a class, or its negation, is literal.
+			if strings.HasPrefix(arg[i+1:], "^") {
+				i++
+			}
+			if strings.HasPrefix(arg[i+1:], "]") {
+				i++
+			}
+		case c == '(':
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case c == ':' && depth == 0:
+			return i
+		}
+	}
+	return -1
+}
+
+func parseFunctionSelector(arg string) (functionSelector, error) {
+	var s functionSelector
+	var err error
+	if i := fileSeparator(arg); i >= 0 {
+		if s.file, err = regexp.Compile(arg[:i]); err != nil {
+			return s, err
+		}
+		arg = arg[i+1:]
+	}
+	s.name, err = regexp.Compile(arg)
+	return s, err
+}
+
+func (s functionSelector) selects(pkg *gocov.Package, fn *gocov.Function) bool {
+	if s.methods != nil {
+		return s.methods[methodKey{pkg.Name, filepath.Base(fn.File), gocovutil.WithoutTypeParams(fn.Name)}]
+	}
+	if s.file == nil {
+		return s.name.MatchString(pkg.Name + "/" + fn.Name)
+	}
+	return s.file.MatchString(pkg.Name+"/"+filepath.Base(fn.File)) && s.name.MatchString(fn.Name)
+}
+
+// selectsAny reports whether the selector selects any function of the
+// packages.
+func selectsAny(s functionSelector, packages []*gocov.Package) bool {
+	for _, pkg := range packages {
+		for _, fn := range pkg.Functions {
+			if s.selects(pkg, fn) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// goPackageNames returns the import paths of the Go packages.
+func goPackageNames(packages []*gocov.Package) []string {
+	var names []string
+	for _, pkg := range packages {
+		if pkg.IsGo() {
+			names = append(names, pkg.Name)
+		}
+	}
+	return names
+}
+
 type annotator struct {
-	fset  *token.FileSet
-	files map[string]*token.File
+	// fsys is the file system from which sources are read, or nil
+	// for the operating system's; see gocovutil.ReadSource.
+	fsys fs.FS
+
+	// mmap selects memory-mapping sources instead of streaming them.
+	mmap bool
 }
 
 func percentReached(fn *gocov.Function) float64 {
@@ -97,6 +305,54 @@ func percentReached(fn *gocov.Function) float64 {
 	return float64(reached) / float64(len(fn.Statements)) * 100
 }
 
+// annotation is the annotated source of a function.
+type annotation struct {
+	pkg    *gocov.Package
+	fn     *gocov.Function
+	output bytes.Buffer
+	err    error
+}
+
+// annotate annotates the functions' sources with up to jobs concurrent
+// readers, which hides the latency of network file systems, passing the
+// annotations to emit in the order of the functions. Annotating runs at
+// most jobs functions ahead of emit, and each annotation's output is
+// released once emitted, so that few are held at once.
+func (a *annotator) annotate(annotations []annotation, jobs int, emit func(*annotation)) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	done := make([]chan struct{}, len(annotations))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	indices := make(chan int)
+	for i := 0; i < jobs && i < len(annotations); i++ {
+		go func() {
+			for i := range indices {
+				an := &annotations[i]
+				an.err = a.printFunctionSource(&an.output, an.fn)
+				close(done[i])
+			}
+		}()
+	}
+	// Each annotation takes a place in the window until it is emitted.
+	window := make(chan struct{}, jobs)
+	go func() {
+		for i := range annotations {
+			window <- struct{}{}
+			indices <- i
+		}
+		close(indices)
+	}()
+	for i := range annotations {
+		<-done[i]
+		emit(&annotations[i])
+		annotations[i].output = bytes.Buffer{}
+		<-window
+	}
+}
+
 func annotateSource() (rc int) {
 	annotateFlags.Parse(os.Args[2:])
 	if annotateFlags.NArg() == 0 {
@@ -130,69 +386,155 @@ func annotateSource() (rc int) {
 	}
 
 	a := &annotator{}
-	a.fset = token.NewFileSet()
-	a.files = make(map[string]*token.File)
+	a.fsys = sourceFS(*annotateSourceRootFlag)
+	a.mmap = *annotateMmapFlag
 
-	var regexps []*regexp.Regexp
+	var selectors []functionSelector
 	for _, arg := range annotateFlags.Args()[1:] {
-		re, err := regexp.Compile(arg)
+		selector, err := parseFunctionSelector(arg)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "warning: failed to compile %q as a regular expression, ignoring\n", arg)
-		} else {
-			regexps = append(regexps, re)
+			continue
+		}
+		if m := methodSelector.FindStringSubmatch(arg); m != nil && !selectsAny(selector, packages) {
+			// The method may be promoted from an embedded type, or
+			// that of an interface; type information tells.
+			methods, err := resolveMethods(goPackageNames(packages), m[1], m[2])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to resolve %q: %s\n", arg, err)
+			} else {
+				selector.methods = methods
+			}
 		}
+		selectors = append(selectors, selector)
 	}
-	if len(regexps) == 0 {
-		regexps = append(regexps, regexp.MustCompile("."))
+	if len(selectors) == 0 {
+		selectors = append(selectors, functionSelector{name: regexp.MustCompile(".")})
 	}
+	var selected []annotation
 	for _, pkg := range packages {
 		for _, fn := range pkg.Functions {
 			if percentReached(fn) >= *annotateCeilingFlag {
 				continue
 			}
-			name := pkg.Name + "/" + fn.Name
-			for _, regexp := range regexps {
-				if regexp.FindStringIndex(name) != nil {
-					err := a.printFunctionSource(fn)
-					if err != nil {
-						fmt.Fprintf(os.Stderr, "warning: failed to annotate function %q\n", name)
-					}
+			for _, selector := range selectors {
+				if selector.selects(pkg, fn) {
+					selected = append(selected, annotation{pkg: pkg, fn: fn})
 					break
 				}
 			}
 		}
 	}
+	a.annotate(selected, sourceReadJobs, func(an *annotation) {
+		if an.err != nil {
+			name := an.pkg.Name + "/" + filepath.Base(an.fn.File) + ":" + an.fn.Name
+			fmt.Fprintf(os.Stderr, "warning: failed to annotate function %q\n", name)
+			return
+		}
+		os.Stdout.Write(an.output.Bytes())
+	})
 	return
 }
 
-func (a *annotator) printFunctionSource(fn *gocov.Function) error {
-	// Load the file for line information. Probably overkill, maybe
-	// just compute the lines from offsets in here.
-	setContent := false
-	file := a.files[fn.File]
-	if file == nil {
-		info, err := os.Stat(fn.File)
+// sourceLines holds the lines of a range of a source file.
+type sourceLines struct {
+	// first is the number of the first line.
+	first int
+
+	// starts holds the offset of the start of each line.
+	starts []int
+
+	// text holds the text of each line, truncated if it is too long.
+	text []string
+}
+
+// line returns the number of the line holding the byte at offset.
+func (l *sourceLines) line(offset int) int {
+	return l.first + sort.SearchInts(l.starts, offset+1) - 1
+}
+
+// readLines reads the lines of source from offset start to end. The
+// source is streamed rather than read whole, and lines longer than max
+// bytes (if max > 0) are truncated with a marker, so that functions of
+// huge generated files, with megabyte-long lines, remain printable.
+func readLines(r io.Reader, start, end, max int) (*sourceLines, error) {
+	br := bufio.NewReader(r)
+	l := &sourceLines{first: 1}
+	for offset := 0; offset < start; offset++ {
+		b, err := br.ReadByte()
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if b == '\n' {
+			l.first++
+		}
+	}
+	var line []byte
+	var truncated int
+	flush := func() {
+		text := string(line)
+		if truncated > 0 {
+			text = strings.ToValidUTF8(text, "") + fmt.Sprintf("... [%d bytes truncated]", truncated)
 		}
-		file = a.fset.AddFile(fn.File, a.fset.Base(), int(info.Size()))
-		setContent = true
+		l.text = append(l.text, text)
+		line, truncated = line[:0], 0
 	}
+	l.starts = append(l.starts, start)
+	for offset := start; offset < end; offset++ {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch {
+		case b == '\n':
+			flush()
+			l.starts = append(l.starts, offset+1)
+		case max > 0 && len(line) >= max:
+			truncated++
+		default:
+			line = append(line, b)
+		}
+	}
+	flush()
+	return l, nil
+}
 
-	data, err := ioutil.ReadFile(fn.File)
+// openSource opens a source file for reading, memory-mapping it if so
+// configured.
+func (a *annotator) openSource(name string) (io.Reader, func(), error) {
+	if a.mmap {
+		data, release, err := gocovutil.MapSource(a.fsys, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		return bytes.NewReader(data), release, nil
+	}
+	f, err := gocovutil.OpenSource(a.fsys, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { f.Close() }, nil
+}
+
+func (a *annotator) printFunctionSource(w io.Writer, fn *gocov.Function) error {
+	openSources <- struct{}{}
+	defer func() { <-openSources }()
+	r, closeSource, err := a.openSource(fn.File)
 	if err != nil {
 		return err
 	}
-	if setContent {
-		// This processes the content and records line number info.
-		file.SetLinesForContent(data)
+	defer closeSource()
+	source, err := readLines(r, fn.Start, fn.End, *annotateMaxLineLengthFlag)
+	if err != nil {
+		return err
 	}
 
-	statements := fn.Statements[:]
-	lineno := file.Line(file.Pos(fn.Start))
-	lines := strings.Split(string(data)[fn.Start:fn.End], "\n")
-	linenoWidth := int(math.Log10(float64(lineno+len(lines)))) + 1
-	fmt.Println()
+	statements := append([]*gocov.Statement(nil), fn.Statements...)
+	lines := source.text
+	linenoWidth := int(math.Log10(float64(source.first+len(lines)))) + 1
+	fmt.Fprintln(w)
 	for i, line := range lines {
 		// Go through statements one at a time, seeing if we've hit
 		// them or not.
@@ -200,11 +542,11 @@ func (a *annotator) printFunctionSource(fn *gocov.Function) error {
 		// The prefix approach isn't perfect, as it doesn't
 		// distinguish multiple statements per line. It'll have to
 		// do for now. We could do fancy ANSI colouring later.
-		lineno := lineno + i
+		lineno := source.first + i
 		statementFound := false
 		hit := false
 		for j := 0; j < len(statements); j++ {
-			start := file.Line(file.Pos(statements[j].Start))
+			start := source.line(statements[j].Start)
 			// FIXME instrumentation no longer records statements
 			// in line order, as function literals are processed
 			// after the body of a function. If/when that's changed,
@@ -216,6 +558,7 @@ func (a *annotator) printFunctionSource(fn *gocov.Function) error {
 					hit = true
 				}
 				statements = append(statements[:j], statements[j+1:]...)
+				j--
 			}
 		}
 		if *annotateColorFlag {
@@ -223,16 +566,16 @@ func (a *annotator) printFunctionSource(fn *gocov.Function) error {
 			if statementFound && !hit {
 				color = RED
 			}
-			fmt.Printf("%s%*d \t%s%s\n", color, linenoWidth, lineno, line, NONE)
+			fmt.Fprintf(w, "%s%*d \t%s%s\n", color, linenoWidth, lineno, line, NONE)
 		} else {
 			hitmiss := hitPrefix
 			if statementFound && !hit {
 				hitmiss = missPrefix
 			}
-			fmt.Printf("%*d %s\t%s\n", linenoWidth, lineno, hitmiss, line)
+			fmt.Fprintf(w, "%*d %s\t%s\n", linenoWidth, lineno, hitmiss, line)
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 
 	return nil
 }
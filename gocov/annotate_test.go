@@ -0,0 +1,267 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestReadLines(t *testing.T) {
+	src := "package p\n\nfunc f() {\n\tx := \"" + strings.Repeat("a", 100) + "\"\n}\n"
+	start := strings.Index(src, "func")
+	l, err := readLines(strings.NewReader(src), start, len(src)-1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.first != 3 {
+		t.Errorf("Expected the function to start on line 3, got %d", l.first)
+	}
+	want := []string{"func f() {", "\tx := \"aaa... [98 bytes truncated]", "}"}
+	if !reflect.DeepEqual(l.text, want) {
+		t.Errorf("readLines = %q, want %q", l.text, want)
+	}
+	if line := l.line(strings.Index(src, "x :=")); line != 4 {
+		t.Errorf("Expected the assignment on line 4, got %d", line)
+	}
+	if line := l.line(start); line != 3 {
+		t.Errorf("Expected the function's start on line 3, got %d", line)
+	}
+}
+
+func TestAnnotateDiff(t *testing.T) {
+	diff := `diff --git a/p/a.go b/p/a.go
+--- a/p/a.go
++++ b/p/a.go
+@@ -1,3 +1,4 @@
+ func f() {
+-	old()
++	x := 1
++	// comment
++	g()
+ }
+`
+	coverage := func(abspath string) (map[int]bool, error) {
+		if abspath != filepath.Join("/repo", "p", "a.go") {
+			t.Errorf("Unexpected file %s", abspath)
+		}
+		return map[int]bool{2: true, 4: false}, nil
+	}
+	var buf bytes.Buffer
+	if err := annotateDiff(&buf, strings.NewReader(diff), "/repo", coverage); err != nil {
+		t.Fatal(err)
+	}
+	want := `    diff --git a/p/a.go b/p/a.go
+    --- a/p/a.go
+    +++ b/p/a.go
+    @@ -1,3 +1,4 @@
+     func f() {
+    -	old()
+HIT +	x := 1
+    +	// comment
+MISS+	g()
+     }
+`
+	if buf.String() != want {
+		t.Errorf("annotateDiff = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestDiffCoverage(t *testing.T) {
+	diff := `diff --git a/p/a.go b/p/a.go
+--- a/p/a.go
++++ b/p/a.go
+@@ -2 +2,2 @@ func f() {
+-	old()
++	x := 1
++	g()
+@@ -9,0 +11 @@ func h() {
++	k()
+diff --git a/p/b.go b/p/b.go
+new file mode 100644
+--- /dev/null
++++ b/p/b.go
+@@ -0,0 +1 @@
++package p
+`
+	added, err := addedLines(strings.NewReader(diff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]int{"p/a.go": {2, 3, 11}, "p/b.go": {1}}
+	if !reflect.DeepEqual(added, want) {
+		t.Fatalf("addedLines = %v, want %v", added, want)
+	}
+	coverage := func(abspath string) (map[int]bool, error) {
+		if abspath == filepath.Join("/repo", "p", "a.go") {
+			return map[int]bool{2: true, 11: false}, nil
+		}
+		return nil, nil
+	}
+	reached, total, missed, err := diffCoverage(added, "/repo", coverage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reached != 1 || total != 2 || !reflect.DeepEqual(missed, []diffLine{{"p/a.go", 11}}) {
+		t.Errorf("diffCoverage = %d, %d, %v, want 1, 2, [p/a.go:11]", reached, total, missed)
+	}
+}
+
+func TestAnnotatorOrder(t *testing.T) {
+	fsys := fstest.MapFS{}
+	var annotations []annotation
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("/src/p/f%d.go", i)
+		src := fmt.Sprintf("package p\n\nfunc F%d() {}\n", i)
+		fsys[name[1:]] = &fstest.MapFile{Data: []byte(src)}
+		fn := &gocov.Function{Name: fmt.Sprintf("F%d", i), File: name, Start: strings.Index(src, "func"), End: len(src) - 1}
+		annotations = append(annotations, annotation{fn: fn})
+	}
+	annotations[7].fn.File = "/src/p/missing.go"
+	a := &annotator{fsys: fsys}
+	var emitted []string
+	a.annotate(annotations, 4, func(an *annotation) {
+		emitted = append(emitted, an.fn.Name)
+		if an.fn.Name == "F7" {
+			if an.err == nil {
+				t.Error("Expected an error annotating a missing source file")
+			}
+		} else if !strings.Contains(an.output.String(), "func "+an.fn.Name+"() {}") {
+			t.Errorf("Unexpected annotation of %s: %q", an.fn.Name, an.output.String())
+		}
+	})
+	for i, name := range emitted {
+		if want := fmt.Sprintf("F%d", i); name != want {
+			t.Fatalf("Emitted %v, want the functions in order", emitted)
+		}
+	}
+	if len(emitted) != 20 {
+		t.Errorf("Emitted %d annotations, want 20", len(emitted))
+	}
+	for i := range annotations {
+		if annotations[i].output.Len() != 0 {
+			t.Errorf("Expected the output of %s to be released once emitted", annotations[i].fn.Name)
+		}
+	}
+}
+
+func TestMethodsOf(t *testing.T) {
+	src := `package p
+
+type Inner struct{}
+
+func (*Inner) M() {}
+
+type Outer struct{ Inner }
+
+type Other struct{}
+
+func (Other) M() {}
+
+type I interface{ M() }
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "/src/p/p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := (&types.Config{}).Check("example.com/p", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		typ  string
+		want []string
+	}{
+		{"Outer", []string{"Inner.M"}},
+		{"Other", []string{"Other.M"}},
+		{"I", []string{"Inner.M", "Other.M"}},
+		{"Missing", nil},
+	} {
+		var got []string
+		for m := range methodsOf(fset, []*types.Package{pkg}, test.typ, "M") {
+			if m.pkg != "example.com/p" || m.file != "p.go" {
+				t.Errorf("%s.M resolved to %s in %s/%s", test.typ, m.name, m.pkg, m.file)
+			}
+			got = append(got, m.name)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("methodsOf(%s.M) = %v, want %v", test.typ, got, test.want)
+		}
+	}
+
+	s := functionSelector{methods: methodsOf(fset, []*types.Package{pkg}, "Outer", "M")}
+	coverage := &gocov.Package{Name: "example.com/p"}
+	if !s.selects(coverage, &gocov.Function{Name: "Inner.M", File: "/src/p/p.go"}) {
+		t.Errorf("Expected Outer.M to select Inner.M")
+	}
+	if s.selects(coverage, &gocov.Function{Name: "Other.M", File: "/src/p/p.go"}) {
+		t.Errorf("Expected Outer.M not to select Other.M")
+	}
+}
+
+func TestFunctionSelector(t *testing.T) {
+	pkg := &gocov.Package{Name: "os"}
+	unix := &gocov.Function{Name: "Open", File: "/src/os/file_unix.go"}
+	windows := &gocov.Function{Name: "Open", File: "/src/os/file_windows.go"}
+	tests := []struct {
+		selector      string
+		unix, windows bool
+	}{
+		{"os/Open", true, true},
+		{"file_unix.go:^Open$", true, false},
+		{"os/file_windows:Op", false, true},
+		{"file_unix.go:Close", false, false},
+		{"os/(?:Op|Cl)en$", true, true},
+		{"os/[[:upper:]]pen", true, true},
+		{"os/[^:]*pen", true, true},
+		{"file_unix.go:(?:Op|Cl)en", true, false},
+		{"(?i)FILE_WINDOWS:^[[:upper:]]", false, true},
+	}
+	for _, test := range tests {
+		s, err := parseFunctionSelector(test.selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.selects(pkg, unix); got != test.unix {
+			t.Errorf("%s selects unix Open = %v, expected %v", test.selector, got, test.unix)
+		}
+		if got := s.selects(pkg, windows); got != test.windows {
+			t.Errorf("%s selects windows Open = %v, expected %v", test.selector, got, test.windows)
+		}
+	}
+	if _, err := parseFunctionSelector("[:f"); err == nil {
+		t.Errorf("Expected an error for an invalid file expression")
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var (
+	annotateDiffFlags    = flag.NewFlagSet("annotate-diff", flag.ExitOnError)
+	annotateDiffBaseFlag = annotateDiffFlags.String(
+		"base", "origin/main",
+		"The git revision to diff the working tree against")
+)
+
+// lineCoverage returns a function reporting, for the lines of a source
+// file that start statements, whether any of those statements was
+// reached. Line information is computed for each file when first needed.
+func lineCoverage(packages []*gocov.Package) func(abspath string) (map[int]bool, error) {
+	functions := make(map[string][]*gocov.Function)
+	for _, pkg := range packages {
+		for _, fn := range pkg.Functions {
+			functions[fn.File] = append(functions[fn.File], fn)
+		}
+	}
+	sources := newSourceFiles()
+	sources.preload(packages)
+	lines := make(map[string]map[int]bool)
+	return func(abspath string) (map[int]bool, error) {
+		if l, ok := lines[abspath]; ok {
+			return l, nil
+		}
+		l := make(map[int]bool)
+		for _, fn := range functions[abspath] {
+			for _, stmt := range fn.Statements {
+				pos, err := sources.position(abspath, stmt.Start)
+				if err != nil {
+					return nil, err
+				}
+				l[pos.Line] = l[pos.Line] || stmt.Reached > 0
+			}
+		}
+		lines[abspath] = l
+		return l, nil
+	}
+}
+
+// annotateDiff copies a unified diff to w, prefixing each added line that
+// starts a statement with HIT or MISS, according to whether the statement
+// was reached. The diff's file names are relative to root.
+func annotateDiff(w io.Writer, diff io.Reader, root string, coverage func(string) (map[int]bool, error)) error {
+	var lines map[int]bool
+	var lineno int
+	scanner := bufio.NewScanner(diff)
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		line := scanner.Text()
+		prefix := "    "
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			lines = nil
+			if name := strings.TrimPrefix(line, "+++ "); strings.HasPrefix(name, "b/") {
+				var err error
+				if lines, err = coverage(filepath.Join(root, filepath.FromSlash(name[2:]))); err != nil {
+					return err
+				}
+			}
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "):
+		case strings.HasPrefix(line, "@@ "):
+			// @@ -l,s +l,s @@
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				return fmt.Errorf("malformed hunk header %q", line)
+			}
+			start := strings.SplitN(strings.TrimPrefix(fields[2], "+"), ",", 2)[0]
+			n, err := strconv.Atoi(start)
+			if err != nil {
+				return fmt.Errorf("malformed hunk header %q", line)
+			}
+			lineno = n
+		case strings.HasPrefix(line, "+"):
+			if hit, ok := lines[lineno]; ok {
+				prefix = missPrefix
+				if hit {
+					prefix = "HIT "
+				}
+			}
+			lineno++
+		case strings.HasPrefix(line, " "):
+			lineno++
+		}
+		fmt.Fprintf(w, "%s%s\n", prefix, line)
+	}
+	return scanner.Err()
+}
+
+// diffPathspecs returns the pathspecs of the files to diff: Go files, and
+// files of any other extension covered by the packages, as imported from
+// the coverage reports of other languages.
+func diffPathspecs(packages []*gocov.Package) []string {
+	specs := []string{"*.go"}
+	seen := map[string]bool{".go": true}
+	for _, pkg := range packages {
+		for _, fn := range pkg.Functions {
+			if ext := filepath.Ext(fn.File); ext != "" && !seen[ext] {
+				seen[ext] = true
+				specs = append(specs, "*"+ext)
+			}
+		}
+	}
+	return specs
+}
+
+// annotateDiffCoverage prints the diff of the working tree against a base
+// revision, marking added lines with their coverage.
+func annotateDiffCoverage() (rc int) {
+	annotateDiffFlags.Parse(os.Args[2:])
+	filename := "-"
+	if annotateDiffFlags.NArg() > 0 {
+		filename = annotateDiffFlags.Arg(0)
+	}
+	doc, err := gocovutil.ReadDocument(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read coverage file (%s): %s\n", filename, err)
+		return 1
+	}
+	root, err := git("", "rev-parse", "--show-toplevel")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	args := append([]string{"diff", "--no-color", "--no-ext-diff", *annotateDiffBaseFlag, "--"}, diffPathspecs(doc.Packages)...)
+	diff, err := git(root, args...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	if err := annotateDiff(os.Stdout, strings.NewReader(diff), root, lineCoverage(doc.Packages)); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to annotate diff: %s\n", err)
+		return 1
+	}
+	return 0
+}
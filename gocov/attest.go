@@ -0,0 +1,153 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	attestFlags       = flag.NewFlagSet("attest", flag.ExitOnError)
+	attestCommandFlag = attestFlags.String(
+		"command", "",
+		"The test command that produced the coverage, e.g. \"gocov test ./...\"")
+	attestDirFlag = attestFlags.String(
+		"dir", ".",
+		"Directory of the source checkout the coverage was produced from")
+)
+
+const (
+	inTotoStatementType   = "https://in-toto.io/Statement/v1"
+	coveragePredicateType = "https://github.com/hihoak/gocov/attestation/coverage/v1"
+)
+
+// attestation is an in-toto statement about a coverage document.
+type attestation struct {
+	Type          string               `json:"_type"`
+	Subject       []attestationSubject `json:"subject"`
+	PredicateType string               `json:"predicateType"`
+	Predicate     coveragePredicate    `json:"predicate"`
+}
+
+type attestationSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// coveragePredicate records how a coverage document was produced.
+type coveragePredicate struct {
+	Source struct {
+		Commit string `json:"commit"`
+		Dirty  bool   `json:"dirty"`
+	} `json:"source"`
+	Toolchain string            `json:"toolchain"`
+	Command   string            `json:"command,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Coverage  struct {
+		Reached   int     `json:"reached"`
+		Total     int     `json:"total"`
+		Percent   float64 `json:"percent"`
+		Formatted string  `json:"formatted"`
+	} `json:"coverage"`
+}
+
+// goVersion returns the version of the go tool that tests are run with.
+func goVersion() (string, error) {
+	out, err := exec.Command("go", "env", "GOVERSION").Output()
+	if err != nil {
+		return "", fmt.Errorf("go env GOVERSION: %v", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// attestCoverage prints an in-toto attestation linking a coverage file to
+// the source commit, toolchain and test command that produced it, for
+// environments that must prove how coverage numbers were obtained.
+func attestCoverage() (rc int) {
+	attestFlags.Parse(os.Args[2:])
+	if attestFlags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: gocov attest [flags] <coverage file>")
+		return 1
+	}
+	filename := attestFlags.Arg(0)
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read coverage file (%s): %s\n", filename, err)
+		return 1
+	}
+	doc, err := unmarshalDocument(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to unmarshal coverage data: %s\n", err)
+		return 1
+	}
+
+	var p coveragePredicate
+	if p.Source.Commit, err = git(*attestDirFlag, "rev-parse", "HEAD"); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	status, err := git(*attestDirFlag, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	p.Source.Dirty = status != ""
+	if p.Toolchain, err = goVersion(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	p.Command = *attestCommandFlag
+	p.Labels = doc.Labels
+	for _, pkg := range doc.Packages {
+		reached, total := coverageCounts(pkg)
+		p.Coverage.Reached += reached
+		p.Coverage.Total += total
+	}
+	p.Coverage.Percent = percentage(p.Coverage.Reached, p.Coverage.Total)
+	p.Coverage.Formatted = formatPercentage(p.Coverage.Reached, p.Coverage.Total)
+
+	sum := sha256.Sum256(data)
+	a := attestation{
+		Type: inTotoStatementType,
+		Subject: []attestationSubject{{
+			Name:   filepath.Base(filename),
+			Digest: map[string]string{"sha256": hex.EncodeToString(sum[:])},
+		}},
+		PredicateType: coveragePredicateType,
+		Predicate:     p,
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(a); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write attestation: %s\n", err)
+		return 1
+	}
+	return 0
+}
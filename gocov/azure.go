@@ -0,0 +1,204 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var (
+	azureFlags   = flag.NewFlagSet("azure", flag.ExitOnError)
+	azureDirFlag = azureFlags.String(
+		"dir", "coverage",
+		"Directory to write cobertura.xml and the HTML report directory to")
+	azurePublishFlag = azureFlags.Bool(
+		"publish", false,
+		"Also attach the coverage summary to the build with the Azure DevOps REST API")
+	azureDryRunFlag = azureFlags.Bool(
+		"dry-run", false,
+		"Print the request of -publish instead of sending it")
+	azureClientOptions = clientFlags(azureFlags)
+)
+
+// azureReportTemplate renders the HTML report published alongside the
+// Cobertura summary.
+var azureReportTemplate = template.Must(template.New("azure").Funcs(template.FuncMap{
+	"formatPercent": formatPercent,
+	"coveredLines":  coveredLines,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Coverage</title></head>
+<body>
+<h1>Coverage: {{formatPercent .LinesCovered .LinesValid}} of lines</h1>
+<table>
+<tr><th>Package</th><th>File</th><th>Lines</th><th>Coverage</th></tr>
+{{range .Packages}}{{$pkg := .Name}}{{range .Classes}}<tr><td>{{$pkg}}</td><td>{{.Filename}}</td><td>{{len .Lines}}</td><td>{{formatPercent (coveredLines .Lines) (len .Lines)}}</td></tr>
+{{end}}{{end}}</table>
+</body>
+</html>
+`))
+
+// writeAzureReport writes the Cobertura summary, cobertura.xml, and an
+// HTML report directory, html, to dir, as Azure Pipelines'
+// PublishCodeCoverageResults task expects.
+func writeAzureReport(dir string, c *coberturaCoverage) error {
+	if err := os.MkdirAll(filepath.Join(dir, "html"), 0755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := writeCobertura(&buf, c); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "cobertura.xml"), buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	buf.Reset()
+	if err := azureReportTemplate.Execute(&buf, c); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "html", "index.html"), buf.Bytes(), 0644)
+}
+
+// azureCoverageData is the body of the Azure DevOps request updating a
+// build's code coverage summary.
+type azureCoverageData struct {
+	CoverageData []azureCoverageSummary `json:"coverageData"`
+}
+
+type azureCoverageSummary struct {
+	BuildFlavor   string              `json:"buildFlavor"`
+	BuildPlatform string              `json:"buildPlatform"`
+	CoverageStats []azureCoverageStat `json:"coverageStats"`
+}
+
+type azureCoverageStat struct {
+	Label    string `json:"label"`
+	Position int    `json:"position"`
+	Covered  int    `json:"covered"`
+	Total    int    `json:"total"`
+}
+
+// publishAzureCoverage attaches the coverage summary to the running build
+// with the Azure DevOps REST API, using the predefined variables of
+// Azure Pipelines. The job must map $(System.AccessToken) into the
+// environment as SYSTEM_ACCESSTOKEN.
+func publishAzureCoverage(client *http.Client, c *coberturaCoverage) error {
+	var missing []string
+	env := make(map[string]string)
+	for _, name := range []string{"SYSTEM_COLLECTIONURI", "SYSTEM_TEAMPROJECT", "BUILD_BUILDID", "SYSTEM_ACCESSTOKEN"} {
+		if env[name] = os.Getenv(name); env[name] == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s not set; -publish only works in Azure Pipelines", strings.Join(missing, ", "))
+	}
+	registerSecret(env["SYSTEM_ACCESSTOKEN"])
+	body, err := json.Marshal(azureCoverageData{CoverageData: []azureCoverageSummary{{
+		BuildFlavor:   os.Getenv("BUILDCONFIGURATION"),
+		BuildPlatform: os.Getenv("BUILDPLATFORM"),
+		CoverageStats: []azureCoverageStat{{Label: "Lines", Position: 4, Covered: c.LinesCovered, Total: c.LinesValid}},
+	}}})
+	if err != nil {
+		return err
+	}
+	endpoint := strings.TrimSuffix(env["SYSTEM_COLLECTIONURI"], "/") + "/" + url.PathEscape(env["SYSTEM_TEAMPROJECT"]) +
+		"/_apis/test/codecoverage?buildId=" + url.QueryEscape(env["BUILD_BUILDID"]) + "&api-version=5.0-preview.1"
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+env["SYSTEM_ACCESSTOKEN"])
+	req.Header.Set("Idempotency-Key", idempotencyKey(endpoint, body))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("azure devops code coverage update failed: %s", resp.Status)
+	}
+	return nil
+}
+
+// azureCoverage writes coverage in the form Azure Pipelines publishes,
+// optionally attaching it to the build directly.
+func azureCoverage() (rc int) {
+	azureFlags.Parse(os.Args[2:])
+	filename := "-"
+	if azureFlags.NArg() > 0 {
+		filename = azureFlags.Arg(0)
+	}
+	doc, err := gocovutil.ReadDocument(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read coverage file (%s): %s\n", filename, err)
+		return 1
+	}
+	r := newReport()
+	for _, pkg := range doc.Packages {
+		r.addPackage(pkg)
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	c, err := coberturaReport(r, newSourceFiles(), root, time.Now().UnixNano()/int64(time.Millisecond))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to convert coverage to Cobertura: %s\n", err)
+		return 1
+	}
+	if err := writeAzureReport(*azureDirFlag, c); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write Azure report: %s\n", err)
+		return 1
+	}
+	if *azurePublishFlag {
+		client, err := newHTTPClient(azureClientOptions)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		if *azureDryRunFlag {
+			client = newDryRunClient(client, os.Stdout)
+		}
+		if err := publishAzureCoverage(client, c); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to publish coverage: %s\n", redactError(err))
+			return 1
+		}
+	}
+	return 0
+}
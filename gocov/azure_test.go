@@ -0,0 +1,61 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestPublishAzureCoverage(t *testing.T) {
+	var got azureCoverageData
+	var path, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth = r.URL.RequestURI(), r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+	t.Setenv("SYSTEM_COLLECTIONURI", server.URL+"/org/")
+	t.Setenv("SYSTEM_TEAMPROJECT", "My Project")
+	t.Setenv("BUILD_BUILDID", "42")
+	t.Setenv("SYSTEM_ACCESSTOKEN", "token")
+	if err := publishAzureCoverage(server.Client(), &coberturaCoverage{LinesCovered: 2, LinesValid: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/org/My%20Project/_apis/test/codecoverage?buildId=42&api-version=5.0-preview.1" {
+		t.Errorf("Unexpected request path %s", path)
+	}
+	if auth != "Bearer token" {
+		t.Errorf("Unexpected authorization %q", auth)
+	}
+	want := []azureCoverageStat{{Label: "Lines", Position: 4, Covered: 2, Total: 3}}
+	if len(got.CoverageData) != 1 || !reflect.DeepEqual(got.CoverageData[0].CoverageStats, want) {
+		t.Errorf("Unexpected coverage data %+v", got)
+	}
+
+	t.Setenv("BUILD_BUILDID", "")
+	if err := publishAzureCoverage(server.Client(), &coberturaCoverage{}); err == nil {
+		t.Errorf("Expected an error outside Azure Pipelines")
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// blameLine records who last changed a line of source, and when.
+type blameLine struct {
+	// author identifies the author by name and email address.
+	author string
+	// time is the author time of the change, in seconds since the epoch.
+	time int64
+}
+
+// blameFile returns the last change to each line of the named file,
+// according to "git blame".
+func blameFile(filename string) (map[int]blameLine, error) {
+	var buf bytes.Buffer
+	cmd := exec.Command("git", "blame", "--line-porcelain", "--", filepath.Base(filename))
+	cmd.Dir = filepath.Dir(filename)
+	cmd.Stdout = &buf
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git blame %s: %v", filename, err)
+	}
+	return parseBlame(&buf)
+}
+
+// parseBlame parses the output of "git blame --line-porcelain".
+func parseBlame(r io.Reader) (map[int]blameLine, error) {
+	lines := make(map[int]blameLine)
+	var line int
+	var name string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "\t"):
+			// The content of the line ends each entry.
+		case strings.HasPrefix(text, "author "):
+			name = text[len("author "):]
+		case strings.HasPrefix(text, "author-mail "):
+			b := lines[line]
+			b.author = name + " " + text[len("author-mail "):]
+			lines[line] = b
+		case strings.HasPrefix(text, "author-time "):
+			t, err := strconv.ParseInt(text[len("author-time "):], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("malformed blame line %q", text)
+			}
+			b := lines[line]
+			b.time = t
+			lines[line] = b
+		default:
+			// Each entry starts with "<sha> <orig-line> <final-line> [<count>]".
+			fields := strings.Fields(text)
+			if len(fields) >= 3 && len(fields[0]) >= 40 {
+				n, err := strconv.Atoi(fields[2])
+				if err != nil {
+					return nil, fmt.Errorf("malformed blame header %q", text)
+				}
+				line = n
+			}
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// printBlame attributes the report's uncovered statements to the authors
+// who last touched them, according to "git blame" in the local checkout.
+func printBlame(w io.Writer, r *report) error {
+	sources := newSourceFiles()
+	sources.preload(r.packages)
+	blames := make(map[string]map[int]blameLine)
+	uncovered := make(map[string]int)
+	var total int
+	for _, pkg := range r.packages {
+		for _, fn := range pkg.Functions {
+			for _, stmt := range fn.Statements {
+				if stmt.Reached > 0 {
+					continue
+				}
+				lines, ok := blames[fn.File]
+				if !ok {
+					var err error
+					lines, err = blameFile(fn.File)
+					if err != nil {
+						// Untracked files have no history; count their
+						// statements as unknown rather than failing.
+						fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+					}
+					blames[fn.File] = lines
+				}
+				pos, err := sources.position(fn.File, stmt.Start)
+				if err != nil {
+					return err
+				}
+				author := lines[pos.Line].author
+				if author == "" {
+					author = "unknown"
+				}
+				uncovered[author]++
+				total++
+			}
+		}
+	}
+
+	authors := make([]string, 0, len(uncovered))
+	for author := range uncovered {
+		authors = append(authors, author)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if uncovered[authors[i]] != uncovered[authors[j]] {
+			return uncovered[authors[i]] > uncovered[authors[j]]
+		}
+		return authors[i] < authors[j]
+	})
+	tw := tabwriter.NewWriter(w, 0, 8, 0, '\t', 0)
+	for _, author := range authors {
+		fmt.Fprintf(tw, "%s\t %d\t %s\n", author, uncovered[author], formatPercentage(uncovered[author], total))
+	}
+	fmt.Fprintf(tw, "Total uncovered statements: %d\n", total)
+	return tw.Flush()
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBlame(t *testing.T) {
+	const (
+		first  = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+		second = "9daeafb9864cf43055ae93beb0afd6c7d144bfa4"
+		none   = "0000000000000000000000000000000000000000"
+	)
+	entry := func(header, author, mail, time, content string) string {
+		return header + "\n" +
+			"author " + author + "\n" +
+			"author-mail " + mail + "\n" +
+			"author-time " + time + "\n" +
+			"author-tz +0100\n" +
+			"committer " + author + "\n" +
+			"committer-mail " + mail + "\n" +
+			"committer-time " + time + "\n" +
+			"committer-tz +0100\n" +
+			"summary Add the package\n" +
+			"filename p.go\n" +
+			"\t" + content + "\n"
+	}
+	tests := []struct {
+		name   string
+		output string
+		want   map[int]blameLine
+		err    bool
+	}{
+		{
+			name:   "empty",
+			output: "",
+			want:   map[int]blameLine{},
+		},
+		{
+			name: "lines of several commits",
+			output: entry(first+" 1 1 2", "Ann", "<ann@example.com>", "1700000000", "package p") +
+				entry(first+" 2 2", "Ann", "<ann@example.com>", "1700000000", "") +
+				entry(second+" 3 3 1", "Bob", "<bob@example.com>", "1710000000", "func F() {}"),
+			want: map[int]blameLine{
+				1: {"Ann <ann@example.com>", 1700000000},
+				2: {"Ann <ann@example.com>", 1700000000},
+				3: {"Bob <bob@example.com>", 1710000000},
+			},
+		},
+		{
+			name: "moved and uncommitted lines",
+			output: entry(second+" 7 1 1", "Bob", "<bob@example.com>", "1710000000", "package p") +
+				"previous " + first + " p.go\n" +
+				entry(none+" 2 2 1", "Not Committed Yet", "<not.committed.yet>", "1720000000", "author Mallory"),
+			want: map[int]blameLine{
+				1: {"Bob <bob@example.com>", 1710000000},
+				2: {"Not Committed Yet <not.committed.yet>", 1720000000},
+			},
+		},
+		{
+			name:   "malformed author time",
+			output: entry(first+" 1 1 1", "Ann", "<ann@example.com>", "yesterday", "package p"),
+			err:    true,
+		},
+		{
+			name:   "malformed final line",
+			output: entry(first+" 1 one 1", "Ann", "<ann@example.com>", "1700000000", "package p"),
+			err:    true,
+		},
+	}
+	for _, test := range tests {
+		got, err := parseBlame(strings.NewReader(test.output))
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %v", test.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: parseBlame = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var (
+	checkFlags      = flag.NewFlagSet("check", flag.ExitOnError)
+	checkPolicyFlag = checkFlags.String(
+		"policy", "",
+		"Rego `file` to evaluate the coverage against")
+	checkQueryFlag = checkFlags.String(
+		"query", "data.gocov.deny",
+		"Rego query yielding the policy violations")
+	checkOPAFlag = checkFlags.String(
+		"opa", "opa",
+		"The OPA executable used to evaluate policies")
+	checkConfigFlag = checkFlags.String(
+		"config", defaultConfigFile,
+		"Configuration `file` holding package coverage thresholds")
+	checkBaselineFlag = checkFlags.String(
+		"baseline", "",
+		"Fail if total coverage is lower than that of this coverage file or git revision")
+	checkWarnOnlyFlag = checkFlags.Bool(
+		"warn-only", false,
+		"Report failures as warnings, without failing the check")
+	checkMaxWarningsFlag = checkFlags.Int(
+		"max-warnings", 0,
+		"Report up to this many failures as warnings, failing the check only if there are more")
+)
+
+// opaResult is the output of "opa eval --format json".
+type opaResult struct {
+	Result []struct {
+		Expressions []struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"expressions"`
+	} `json:"result"`
+}
+
+// violations extracts the violations from the result of a policy query,
+// whose value must be a set (or array) of messages; any other value is
+// taken to be a single message.
+func violations(result *opaResult) []string {
+	var messages []string
+	for _, r := range result.Result {
+		for _, expr := range r.Expressions {
+			var values []interface{}
+			if err := json.Unmarshal(expr.Value, &values); err != nil {
+				messages = append(messages, string(expr.Value))
+				continue
+			}
+			for _, v := range values {
+				if s, ok := v.(string); ok {
+					messages = append(messages, s)
+				} else {
+					data, _ := json.Marshal(v)
+					messages = append(messages, string(data))
+				}
+			}
+		}
+	}
+	return messages
+}
+
+// thresholdViolations returns a message for each package whose coverage is
+// below its threshold. Packages without a threshold, and thresholds of
+// packages absent from the coverage, are ignored.
+func thresholdViolations(doc *gocovutil.Document, thresholds map[string]float64) []string {
+	var messages []string
+	for _, pkg := range doc.Packages {
+		threshold, ok := thresholds[pkg.Name]
+		if !ok {
+			continue
+		}
+		reached, total := coverageCounts(pkg)
+		if belowThreshold(reached, total, threshold) {
+			messages = append(messages, fmt.Sprintf("%s: coverage %s is below the threshold of %.2f%%", pkg.Name, formatPercentage(reached, total), threshold))
+		}
+	}
+	return messages
+}
+
+// hitViolations returns a message for each package with statements
+// reached fewer times than its minimum, naming the first function holding
+// such a statement. Packages without a minimum are ignored.
+func hitViolations(doc *gocovutil.Document, minHits map[string]int64) []string {
+	var messages []string
+	for _, pkg := range doc.Packages {
+		min, ok := minHits[pkg.Name]
+		if !ok {
+			continue
+		}
+		var below, total int
+		var first string
+		for _, fn := range pkg.Functions {
+			for _, stmt := range fn.Statements {
+				total++
+				if stmt.Reached < min {
+					if below == 0 {
+						first = fn.Name
+					}
+					below++
+				}
+			}
+		}
+		if below > 0 {
+			messages = append(messages, fmt.Sprintf("%s: %d of %d statements were reached fewer than %d times, first in %s", pkg.Name, below, total, min, first))
+		}
+	}
+	return messages
+}
+
+// coverageDecrease returns a message if the document's total coverage is
+// lower than the baseline's.
+func coverageDecrease(baseline, doc *gocovutil.Document) (string, bool) {
+	total := func(doc *gocovutil.Document) (reached, total int) {
+		for _, pkg := range doc.Packages {
+			r, t := coverageCounts(pkg)
+			reached += r
+			total += t
+		}
+		return reached, total
+	}
+	beforeReached, beforeTotal := total(baseline)
+	afterReached, afterTotal := total(doc)
+	if gocovutil.ExactPercentage(afterReached, afterTotal).Cmp(gocovutil.ExactPercentage(beforeReached, beforeTotal)) >= 0 {
+		return "", false
+	}
+	return fmt.Sprintf("total coverage decreased from %s to %s",
+		formatPercentage(beforeReached, beforeTotal), formatPercentage(afterReached, afterTotal)), true
+}
+
+// evalPolicy evaluates the query against the coverage document with OPA,
+// returning the violations found.
+func evalPolicy(opa, policy, query string, doc []byte) ([]string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(opa, "eval", "--format", "json", "--data", policy, "--stdin-input", query)
+	cmd.Stdin = bytes.NewReader(doc)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("opa eval: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	var result opaResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, fmt.Errorf("malformed opa output: %v", err)
+	}
+	return violations(&result), nil
+}
+
+// checkCoverage evaluates a coverage document against a policy, the
+// configured package and group thresholds and minimum hit counts, and a
+// baseline, printing each failure. Violations of the policy, thresholds
+// or minimum hit counts exit with exitThreshold, and a decrease from the
+// baseline with exitDecreased, unless the failures are tolerated as
+// warnings.
+func checkCoverage() (rc int) {
+	checkFlags.Parse(os.Args[2:])
+	cfg, err := loadConfig(*checkConfigFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read configuration: %s\n", err)
+		return 1
+	}
+	// A configuration setting no thresholds, as "thresholds: {}" in
+	// that written by "gocov init", checks nothing but is no mistake.
+	if *checkPolicyFlag == "" && cfg.Thresholds == nil && cfg.MinHits == nil && *checkBaselineFlag == "" {
+		fmt.Fprintf(os.Stderr, "nothing to check: missing -policy, -baseline, or thresholds or min_hits in %s\n", *checkConfigFlag)
+		return 1
+	}
+	filename := "-"
+	if checkFlags.NArg() > 0 {
+		filename = checkFlags.Arg(0)
+	}
+	var data []byte
+	if filename == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read coverage file (%s): %s\n", filename, err)
+		return 1
+	}
+	doc, err := unmarshalDocument(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to unmarshal coverage data: %s\n", err)
+		return 1
+	}
+	messages := thresholdViolations(doc, cfg.Thresholds)
+	messages = append(messages, hitViolations(doc, cfg.MinHits)...)
+	if len(cfg.Groups) > 0 {
+		grouped := cfg.Groups.groupDocument(doc)
+		messages = append(messages, thresholdViolations(grouped, cfg.Thresholds)...)
+		messages = append(messages, hitViolations(grouped, cfg.MinHits)...)
+	}
+	if *checkPolicyFlag != "" {
+		violations, err := evalPolicy(*checkOPAFlag, *checkPolicyFlag, *checkQueryFlag, data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to evaluate policy: %s\n", err)
+			return 1
+		}
+		messages = append(messages, violations...)
+	}
+	var decrease string
+	var decreased bool
+	if *checkBaselineFlag != "" {
+		baseline, err := loadBaseline(*checkBaselineFlag, []string{"./..."})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		decrease, decreased = coverageDecrease(baseline, doc)
+	}
+
+	failures := len(messages)
+	if decreased {
+		failures++
+	}
+	if *checkWarnOnlyFlag || failures <= *checkMaxWarningsFlag {
+		for _, message := range messages {
+			fmt.Fprintln(os.Stderr, "warning: policy violation:", message)
+		}
+		if decreased {
+			fmt.Fprintln(os.Stderr, "warning:", decrease)
+		}
+		return 0
+	}
+	for _, message := range messages {
+		fmt.Fprintln(os.Stderr, "policy violation:", message)
+	}
+	if decreased {
+		fmt.Fprintln(os.Stderr, decrease)
+	}
+	if len(messages) > 0 {
+		return exitThreshold
+	}
+	if decreased {
+		return exitDecreased
+	}
+	return 0
+}
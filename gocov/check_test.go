@@ -0,0 +1,215 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+func TestViolations(t *testing.T) {
+	tests := []struct {
+		output string
+		want   []string
+	}{
+		{`{}`, nil},
+		{`{"result":[{"expressions":[{"value":[]}]}]}`, nil},
+		{`{"result":[{"expressions":[{"value":["a","b"]}]}]}`, []string{"a", "b"}},
+		{`{"result":[{"expressions":[{"value":[{"pkg":"p"}]}]}]}`, []string{`{"pkg":"p"}`}},
+		{`{"result":[{"expressions":[{"value":"too low"}]}]}`, []string{`"too low"`}},
+	}
+	for _, test := range tests {
+		var result opaResult
+		if err := json.Unmarshal([]byte(test.output), &result); err != nil {
+			t.Fatal(err)
+		}
+		if got := violations(&result); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("violations(%s) = %q, want %q", test.output, got, test.want)
+		}
+	}
+}
+
+func TestEvalPolicy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stand-in opa is a shell script")
+	}
+	// A stand-in for opa denies documents with a package named p, and
+	// fails to parse any other policy than policy.rego.
+	opa := filepath.Join(t.TempDir(), "opa")
+	script := `#!/bin/sh
+if [ "$*" != "eval --format json --data policy.rego --stdin-input data.gocov.deny" ]; then
+	echo "1 error occurred: $5: rego_parse_error" >&2
+	exit 1
+fi
+case "$(cat)" in
+*'"Name":"p"'*) echo '{"result":[{"expressions":[{"value":["p is below 80% coverage"]}]}]}' ;;
+*) echo '{"result":[{"expressions":[{"value":[]}]}]}' ;;
+esac
+`
+	if err := ioutil.WriteFile(opa, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	got, err := evalPolicy(opa, "policy.rego", "data.gocov.deny", []byte(`{"Packages":[{"Name":"p"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"p is below 80% coverage"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("evalPolicy = %q, want %q", got, want)
+	}
+	if got, err := evalPolicy(opa, "policy.rego", "data.gocov.deny", []byte(`{"Packages":[{"Name":"q"}]}`)); err != nil || len(got) != 0 {
+		t.Errorf("evalPolicy = %q, %v, want no violations", got, err)
+	}
+	_, err = evalPolicy(opa, "broken.rego", "data.gocov.deny", nil)
+	if err == nil || !strings.Contains(err.Error(), "broken.rego: rego_parse_error") {
+		t.Errorf("evalPolicy error = %v, want opa's parse error", err)
+	}
+}
+
+func TestSuggestThresholds(t *testing.T) {
+	doc := &gocovutil.Document{Packages: gocovutil.Packages{
+		{Name: "a", Functions: []*gocov.Function{{Statements: []*gocov.Statement{{Reached: 1}, {Reached: 1}, {}}}}},
+		{Name: "b", Functions: []*gocov.Function{{Statements: []*gocov.Statement{{}}}}},
+		{Name: "empty"},
+	}}
+	got := suggestThresholds(doc, 5)
+	want := map[string]float64{"a": 61, "b": 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestThresholds = %v, want %v", got, want)
+	}
+	if messages := thresholdViolations(doc, got); len(messages) != 0 {
+		t.Errorf("Expected suggested thresholds to hold, got %q", messages)
+	}
+	messages := thresholdViolations(doc, map[string]float64{"a": 70, "missing": 50})
+	if len(messages) != 1 || !strings.HasPrefix(messages[0], "a: ") {
+		t.Errorf("Expected a violation for package a, got %q", messages)
+	}
+
+	// 57 of 100 statements, whose percentage is 56.99999999999999 in
+	// floating point, are exactly 57%.
+	fn := &gocov.Function{}
+	for i := 0; i < 100; i++ {
+		stmt := &gocov.Statement{}
+		if i < 57 {
+			stmt.Reached = 1
+		}
+		fn.Statements = append(fn.Statements, stmt)
+	}
+	doc = &gocovutil.Document{Packages: gocovutil.Packages{{Name: "p", Functions: []*gocov.Function{fn}}}}
+	if got := suggestThresholds(doc, 5); got["p"] != 52 {
+		t.Errorf("suggestThresholds = %v, want 52 for p", got)
+	}
+	for _, threshold := range []float64{57, 56.99, 52} {
+		if messages := thresholdViolations(doc, map[string]float64{"p": threshold}); len(messages) != 0 {
+			t.Errorf("Expected 57/100 to meet a threshold of %v, got %q", threshold, messages)
+		}
+	}
+	for _, threshold := range []float64{57.01, 57.1, 58} {
+		if messages := thresholdViolations(doc, map[string]float64{"p": threshold}); len(messages) != 1 {
+			t.Errorf("Expected 57/100 to fail a threshold of %v, got %q", threshold, messages)
+		}
+	}
+}
+
+func TestCoverageDecrease(t *testing.T) {
+	doc := func(reached ...int64) *gocovutil.Document {
+		fn := &gocov.Function{}
+		for _, r := range reached {
+			fn.Statements = append(fn.Statements, &gocov.Statement{Reached: r})
+		}
+		return &gocovutil.Document{Packages: gocovutil.Packages{{Name: "p", Functions: []*gocov.Function{fn}}}}
+	}
+	if _, decreased := coverageDecrease(doc(1, 0), doc(1, 1)); decreased {
+		t.Errorf("Expected increased coverage not to be a decrease")
+	}
+	if _, decreased := coverageDecrease(doc(1, 0), doc(0, 1)); decreased {
+		t.Errorf("Expected unchanged coverage not to be a decrease")
+	}
+	message, decreased := coverageDecrease(doc(1, 1), doc(1, 0))
+	if !decreased || message != "total coverage decreased from 100.00% to 50.00%" {
+		t.Errorf("coverageDecrease = %q, %v", message, decreased)
+	}
+
+	// A decrease too small to show at two decimal places is still shown
+	// as a decrease, rounded down from the exact percentages.
+	before, after := make([]int64, 10000), make([]int64, 10000)
+	for i := range before {
+		before[i] = 1
+		after[i] = 1
+	}
+	after[0] = 0
+	message, decreased = coverageDecrease(doc(before...), doc(after...))
+	if !decreased || message != "total coverage decreased from 100.00% to 99.99%" {
+		t.Errorf("coverageDecrease = %q, %v", message, decreased)
+	}
+}
+
+func TestHitViolations(t *testing.T) {
+	doc := &gocovutil.Document{Packages: gocovutil.Packages{
+		{Name: "p", Functions: []*gocov.Function{
+			{Name: "F", Statements: []*gocov.Statement{{Reached: 10}}},
+			{Name: "G", Statements: []*gocov.Statement{{Reached: 9}, {Reached: 0}}},
+		}},
+		{Name: "q", Functions: []*gocov.Function{
+			{Name: "H", Statements: []*gocov.Statement{{Reached: 0}}},
+		}},
+	}}
+	got := hitViolations(doc, map[string]int64{"p": 10})
+	want := []string{"p: 2 of 3 statements were reached fewer than 10 times, first in G"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("hitViolations = %q, want %q", got, want)
+	}
+}
+
+func TestAddThresholds(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".gocov.yaml")
+	if err := addThresholds(filename, map[string]float64{"a": 61}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "thresholds:\n  a: 61\n"; string(data) != want {
+		t.Errorf("addThresholds wrote %q, want %q", data, want)
+	}
+
+	// Configured thresholds are kept, and those of new packages added.
+	if err := ioutil.WriteFile(filename, []byte("# floors\nthresholds:\n  a: 70 # raised\nmin_hits:\n  a: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := addThresholds(filename, map[string]float64{"a": 61, "c": 12.5, "b": 0}); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = ioutil.ReadFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	if want := "# floors\nthresholds:\n  a: 70 # raised\n  b: 0\n  c: 12.5\nmin_hits:\n  a: 1\n"; string(data) != want {
+		t.Errorf("addThresholds wrote %q, want %q", data, want)
+	}
+}
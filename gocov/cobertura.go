@@ -0,0 +1,208 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hihoak/gocov"
+)
+
+// coberturaCoverage is the root of a Cobertura XML report, the coverage
+// format read by Azure Pipelines, GitLab and Jenkins. Only line coverage
+// is reported; gocov has no branch data.
+type coberturaCoverage struct {
+	XMLName         xml.Name           `xml:"coverage"`
+	LineRate        string             `xml:"line-rate,attr"`
+	BranchRate      string             `xml:"branch-rate,attr"`
+	LinesCovered    int                `xml:"lines-covered,attr"`
+	LinesValid      int                `xml:"lines-valid,attr"`
+	BranchesCovered int                `xml:"branches-covered,attr"`
+	BranchesValid   int                `xml:"branches-valid,attr"`
+	Complexity      int                `xml:"complexity,attr"`
+	Version         string             `xml:"version,attr"`
+	Timestamp       int64              `xml:"timestamp,attr"`
+	Sources         []string           `xml:"sources>source"`
+	Packages        []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   string           `xml:"line-rate,attr"`
+	BranchRate string           `xml:"branch-rate,attr"`
+	Complexity int              `xml:"complexity,attr"`
+	Classes    []coberturaClass `xml:"classes>class"`
+}
+
+// coberturaClass holds the coverage of a source file; Go has no classes.
+type coberturaClass struct {
+	Name       string            `xml:"name,attr"`
+	Filename   string            `xml:"filename,attr"`
+	LineRate   string            `xml:"line-rate,attr"`
+	BranchRate string            `xml:"branch-rate,attr"`
+	Complexity int               `xml:"complexity,attr"`
+	Methods    []coberturaMethod `xml:"methods>method"`
+	Lines      []coberturaLine   `xml:"lines>line"`
+}
+
+type coberturaMethod struct {
+	Name       string          `xml:"name,attr"`
+	Signature  string          `xml:"signature,attr"`
+	LineRate   string          `xml:"line-rate,attr"`
+	BranchRate string          `xml:"branch-rate,attr"`
+	Complexity int             `xml:"complexity,attr"`
+	Lines      []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number int   `xml:"number,attr"`
+	Hits   int64 `xml:"hits,attr"`
+}
+
+// coberturaRate formats the rate of covered lines as a fraction, rounded
+// down to four decimals as percentages are.
+func coberturaRate(covered, valid int) string {
+	if valid == 0 {
+		return "0"
+	}
+	n := int64(covered) * 10000 / int64(valid)
+	return fmt.Sprintf("%d.%04d", n/10000, n%10000)
+}
+
+// coberturaLines returns the lines of a function's statements, by the
+// line each starts on, with the highest count of the statements starting
+// on the line.
+func coberturaLines(sources *sourceFiles, fn *gocov.Function) ([]coberturaLine, error) {
+	hits := make(map[int]int64)
+	for _, stmt := range fn.Statements {
+		pos, err := sources.position(fn.File, stmt.Start)
+		if err != nil {
+			return nil, err
+		}
+		if n, ok := hits[pos.Line]; !ok || stmt.Reached > n {
+			hits[pos.Line] = stmt.Reached
+		}
+	}
+	lines := make([]coberturaLine, 0, len(hits))
+	for number, n := range hits {
+		lines = append(lines, coberturaLine{Number: number, Hits: n})
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].Number < lines[j].Number })
+	return lines, nil
+}
+
+// coveredLines returns the number of lines with hits.
+func coveredLines(lines []coberturaLine) int {
+	covered := 0
+	for _, l := range lines {
+		if l.Hits > 0 {
+			covered++
+		}
+	}
+	return covered
+}
+
+// coberturaReport converts the report's packages to Cobertura. File names
+// are made relative to root, the report's only source, where they are
+// within it.
+func coberturaReport(r *report, sources *sourceFiles, root string, timestamp int64) (*coberturaCoverage, error) {
+	c := &coberturaCoverage{BranchRate: "0", Version: "gocov", Timestamp: timestamp, Sources: []string{root}}
+	sources.preload(r.packages)
+	for _, pkg := range r.packages {
+		p := coberturaPackage{Name: pkg.Name, BranchRate: "0"}
+		var pkgCovered, pkgValid int
+		for _, file := range pkg.SourceFiles() {
+			filename := file.File
+			if rel, err := filepath.Rel(root, filename); err == nil && !strings.HasPrefix(rel, "..") {
+				filename = filepath.ToSlash(rel)
+			}
+			class := coberturaClass{Name: filepath.Base(file.File), Filename: filename, BranchRate: "0"}
+			fileLines := make(map[int]int64)
+			for _, fn := range file.Functions {
+				lines, err := coberturaLines(sources, fn)
+				if err != nil {
+					return nil, err
+				}
+				class.Methods = append(class.Methods, coberturaMethod{
+					Name:       fn.Name,
+					LineRate:   coberturaRate(coveredLines(lines), len(lines)),
+					BranchRate: "0",
+					Lines:      lines,
+				})
+				for _, l := range lines {
+					if n, ok := fileLines[l.Number]; !ok || l.Hits > n {
+						fileLines[l.Number] = l.Hits
+					}
+				}
+			}
+			for number, n := range fileLines {
+				class.Lines = append(class.Lines, coberturaLine{Number: number, Hits: n})
+			}
+			sort.Slice(class.Lines, func(i, j int) bool { return class.Lines[i].Number < class.Lines[j].Number })
+			covered := coveredLines(class.Lines)
+			class.LineRate = coberturaRate(covered, len(class.Lines))
+			pkgCovered += covered
+			pkgValid += len(class.Lines)
+			p.Classes = append(p.Classes, class)
+		}
+		p.LineRate = coberturaRate(pkgCovered, pkgValid)
+		c.LinesCovered += pkgCovered
+		c.LinesValid += pkgValid
+		c.Packages = append(c.Packages, p)
+	}
+	c.LineRate = coberturaRate(c.LinesCovered, c.LinesValid)
+	return c, nil
+}
+
+// writeCobertura writes a Cobertura report as XML.
+func writeCobertura(w io.Writer, c *coberturaCoverage) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// printCobertura writes the report in Cobertura XML, with file names
+// relative to the working directory.
+func printCobertura(w io.Writer, r *report) error {
+	root, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	c, err := coberturaReport(r, newSourceFiles(), root, time.Now().UnixNano()/int64(time.Millisecond))
+	if err != nil {
+		return err
+	}
+	return writeCobertura(w, c)
+}
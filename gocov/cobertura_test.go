@@ -0,0 +1,90 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"go/token"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestCoberturaReport(t *testing.T) {
+	src := "package p\n\nfunc f(x int) int {\n\tif x > 0 {\n\t\treturn x\n\t}\n\treturn 0\n}\n"
+	offset := func(s string) int { return strings.Index(src, s) }
+	r := newReport()
+	r.addPackage(&gocov.Package{Name: "p", Functions: []*gocov.Function{{
+		Name: "f", File: "/src/p/p.go",
+		Statements: []*gocov.Statement{
+			{Start: offset("if"), Reached: 2},
+			{Start: offset("return x"), Reached: 0},
+			{Start: offset("return 0"), Reached: 2},
+		},
+	}}})
+	sources := &sourceFiles{
+		fset:  token.NewFileSet(),
+		files: make(map[string]*token.File),
+		fsys:  fstest.MapFS{"src/p/p.go": {Data: []byte(src)}},
+	}
+	c, err := coberturaReport(r, sources, "/src", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := writeCobertura(&buf, c); err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<coverage line-rate="0.6666" branch-rate="0" lines-covered="2" lines-valid="3" branches-covered="0" branches-valid="0" complexity="0" version="gocov" timestamp="1">
+  <sources>
+    <source>/src</source>
+  </sources>
+  <packages>
+    <package name="p" line-rate="0.6666" branch-rate="0" complexity="0">
+      <classes>
+        <class name="p.go" filename="p/p.go" line-rate="0.6666" branch-rate="0" complexity="0">
+          <methods>
+            <method name="f" signature="" line-rate="0.6666" branch-rate="0" complexity="0">
+              <lines>
+                <line number="4" hits="2"></line>
+                <line number="5" hits="0"></line>
+                <line number="7" hits="2"></line>
+              </lines>
+            </method>
+          </methods>
+          <lines>
+            <line number="4" hits="2"></line>
+            <line number="5" hits="0"></line>
+            <line number="7" hits="2"></line>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>
+`
+	if buf.String() != want {
+		t.Errorf("writeCobertura = %s, want %s", buf.String(), want)
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// commentMarker is hidden in the markdown of gocov's pull request
+// comments, by which its previous comment is found.
+const commentMarker = "<!-- gocov:coverage -->"
+
+// prComment is a comment on a pull or merge request.
+type prComment struct {
+	ID   string
+	Body string
+}
+
+// commentService lists, posts and edits the comments of a pull or merge
+// request on a code hosting service.
+type commentService interface {
+	comments() ([]prComment, error)
+	post(body string) error
+	edit(id, body string) error
+}
+
+// upsertComment posts the body, marked as gocov's, as a comment. If sticky
+// is set, gocov's previous comment is edited instead, if there is one, so
+// that repeated runs on a pull request do not pile up comments.
+func upsertComment(service commentService, body string, sticky bool) error {
+	body = commentMarker + "\n" + body
+	if sticky {
+		comments, err := service.comments()
+		if err != nil {
+			return err
+		}
+		for _, c := range comments {
+			if strings.Contains(c.Body, commentMarker) {
+				if c.Body == body {
+					return nil
+				}
+				return service.edit(c.ID, body)
+			}
+		}
+	}
+	err := service.post(body)
+	if err != nil && sticky {
+		// A post that failed in transit may have gone through anyway,
+		// in which case it is not to be repeated.
+		if comments, lerr := service.comments(); lerr == nil {
+			for _, c := range comments {
+				if c.Body == body {
+					return nil
+				}
+			}
+		}
+	}
+	return err
+}
+
+// restClient calls the JSON REST API of a code hosting service.
+type restClient struct {
+	client *http.Client
+	base   string
+	header http.Header
+}
+
+// do makes a request to the API with a JSON body, decoding the JSON
+// response into v if it is not nil.
+func (c *restClient) do(method, path string, body, v interface{}) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.base+path, r)
+	if err != nil {
+		return err
+	}
+	for k, vs := range c.header {
+		req.Header[k] = vs
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// githubComments manages the comments of a GitHub pull request, which are
+// those of the issue it is.
+type githubComments struct {
+	*restClient
+	repo   string
+	number string
+}
+
+func (g *githubComments) comments() ([]prComment, error) {
+	var comments []prComment
+	for page := 1; ; page++ {
+		var result []struct {
+			ID   int64  `json:"id"`
+			Body string `json:"body"`
+		}
+		path := fmt.Sprintf("/repos/%s/issues/%s/comments?per_page=100&page=%d", g.repo, url.PathEscape(g.number), page)
+		if err := g.do("GET", path, nil, &result); err != nil {
+			return nil, err
+		}
+		for _, c := range result {
+			comments = append(comments, prComment{fmt.Sprint(c.ID), c.Body})
+		}
+		if len(result) < 100 {
+			return comments, nil
+		}
+	}
+}
+
+func (g *githubComments) post(body string) error {
+	return g.do("POST", fmt.Sprintf("/repos/%s/issues/%s/comments", g.repo, url.PathEscape(g.number)), map[string]string{"body": body}, nil)
+}
+
+func (g *githubComments) edit(id, body string) error {
+	return g.do("PATCH", fmt.Sprintf("/repos/%s/issues/comments/%s", g.repo, id), map[string]string{"body": body}, nil)
+}
+
+// gitlabComments manages the notes of a GitLab merge request.
+type gitlabComments struct {
+	*restClient
+	project string
+	number  string
+}
+
+func (g *gitlabComments) path() string {
+	return "/projects/" + url.PathEscape(g.project) + "/merge_requests/" + url.PathEscape(g.number) + "/notes"
+}
+
+func (g *gitlabComments) comments() ([]prComment, error) {
+	var comments []prComment
+	for page := 1; ; page++ {
+		var result []struct {
+			ID   int64  `json:"id"`
+			Body string `json:"body"`
+		}
+		if err := g.do("GET", fmt.Sprintf("%s?per_page=100&page=%d", g.path(), page), nil, &result); err != nil {
+			return nil, err
+		}
+		for _, c := range result {
+			comments = append(comments, prComment{fmt.Sprint(c.ID), c.Body})
+		}
+		if len(result) < 100 {
+			return comments, nil
+		}
+	}
+}
+
+func (g *gitlabComments) post(body string) error {
+	return g.do("POST", g.path(), map[string]string{"body": body}, nil)
+}
+
+func (g *gitlabComments) edit(id, body string) error {
+	return g.do("PUT", g.path()+"/"+id, map[string]string{"body": body}, nil)
+}
+
+// commentService returns the comment service of a github or gitlab
+// target. Unset settings default to the variables of GitHub Actions and
+// GitLab CI.
+func (t *notifyTarget) commentService(client *http.Client) (commentService, error) {
+	expand := func(value, env string) string {
+		if value = expandURL(value); value == "" {
+			value = os.Getenv(env)
+		}
+		return value
+	}
+	token, err := resolveSecret(t.Token)
+	if err != nil {
+		return nil, err
+	}
+	switch t.Kind {
+	case "github":
+		base := expand(t.URL, "GITHUB_API_URL")
+		if base == "" {
+			base = "https://api.github.com"
+		}
+		g := &githubComments{
+			restClient: &restClient{client: client, base: strings.TrimSuffix(base, "/"), header: http.Header{}},
+			repo:       expand(t.Repository, "GITHUB_REPOSITORY"),
+			number:     os.ExpandEnv(t.Number),
+		}
+		if g.repo == "" || g.number == "" {
+			return nil, fmt.Errorf("github notification requires repository and number")
+		}
+		g.header.Set("Accept", "application/vnd.github+json")
+		if token != "" {
+			g.header.Set("Authorization", "Bearer "+token)
+		}
+		return g, nil
+	case "gitlab":
+		g := &gitlabComments{
+			restClient: &restClient{client: client, base: strings.TrimSuffix(expand(t.URL, "CI_API_V4_URL"), "/"), header: http.Header{}},
+			project:    expand(t.Project, "CI_PROJECT_ID"),
+			number:     expand(t.Number, "CI_MERGE_REQUEST_IID"),
+		}
+		if g.base == "" || g.project == "" || g.number == "" {
+			return nil, fmt.Errorf("gitlab notification requires url, project and number")
+		}
+		if token != "" {
+			g.header.Set("PRIVATE-TOKEN", token)
+		}
+		return g, nil
+	}
+	return nil, fmt.Errorf("unknown comment service %q", t.Kind)
+}
+
+// comment comments on the target's pull or merge request with the
+// summary.
+func (t *notifyTarget) comment(client *http.Client, s *notifySummary) error {
+	service, err := t.commentService(client)
+	if err != nil {
+		return err
+	}
+	message, err := t.message(s)
+	if err != nil {
+		return err
+	}
+	if s.Diff != "" {
+		message += "\n\n" + s.Diff
+	}
+	return upsertComment(service, message, *notifyStickyFlag)
+}
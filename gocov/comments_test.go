@@ -0,0 +1,78 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStickyComment(t *testing.T) {
+	comments := map[string]string{"1": "LGTM"}
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		var body struct{ Body string }
+		json.NewDecoder(r.Body).Decode(&body)
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repos/o/r/issues/7/comments":
+			var list []map[string]interface{}
+			for id, body := range comments {
+				list = append(list, map[string]interface{}{"id": json.Number(id), "body": body})
+			}
+			json.NewEncoder(w).Encode(list)
+		case r.Method == "POST" && r.URL.Path == "/repos/o/r/issues/7/comments":
+			comments["2"] = body.Body
+		case r.Method == "PATCH" && strings.HasPrefix(r.URL.Path, "/repos/o/r/issues/comments/"):
+			comments[strings.TrimPrefix(r.URL.Path, "/repos/o/r/issues/comments/")] = body.Body
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	target := &notifyTarget{Kind: "github", URL: server.URL, Repository: "o/r", Number: "7"}
+	service, err := target.commentService(server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{"Coverage: 50.0%", "Coverage: 75.0%"} {
+		if err := upsertComment(service, body, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(comments) != 2 || comments["2"] != commentMarker+"\nCoverage: 75.0%" {
+		t.Errorf("Unexpected comments %q", comments)
+	}
+	if requests[len(requests)-1] != "PATCH /repos/o/r/issues/comments/2" {
+		t.Errorf("Expected the comment to be edited, got requests %q", requests)
+	}
+
+	if err := upsertComment(service, "Coverage: 75.0%", false); err != nil {
+		t.Fatal(err)
+	}
+	if requests[len(requests)-1] != "POST /repos/o/r/issues/7/comments" {
+		t.Errorf("Expected a comment to be posted, got requests %q", requests)
+	}
+}
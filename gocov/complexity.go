@@ -0,0 +1,113 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+// complexities computes the cyclomatic complexity of functions, parsing
+// each source file once.
+type complexities struct {
+	fset  *token.FileSet
+	files map[string]*ast.File
+}
+
+func newComplexities() *complexities {
+	return &complexities{fset: token.NewFileSet(), files: make(map[string]*ast.File)}
+}
+
+// of returns the cyclomatic complexity of the function: one more than the
+// number of decision points (conditions, loops, non-default cases and
+// short-circuit operators) in its body, excluding nested function
+// literals. Functions imported from the coverage of other languages have
+// no complexity, and so sort before all others.
+func (c *complexities) of(pkg *gocov.Package, fn *gocov.Function) (int, error) {
+	if !pkg.IsGo() {
+		return 0, nil
+	}
+	file, ok := c.files[fn.File]
+	if !ok {
+		src, err := gocovutil.ReadSource(sourceFS(*reportSourceRootFlag), fn.File)
+		if err != nil {
+			return 0, err
+		}
+		if file, err = parser.ParseFile(c.fset, fn.File, src, 0); err != nil {
+			return 0, err
+		}
+		c.files[fn.File] = file
+	}
+	offset := func(pos token.Pos) int { return c.fset.Position(pos).Offset }
+	var body *ast.BlockStmt
+	ast.Inspect(file, func(n ast.Node) bool {
+		if body != nil || n == nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil && offset(n.Pos()) == fn.Start {
+				body = n.Body
+			}
+		case *ast.FuncLit:
+			if offset(n.Pos()) == fn.Start {
+				body = n.Body
+			}
+		}
+		return true
+	})
+	if body == nil {
+		return 0, nil
+	}
+	return cyclomaticComplexity(body), nil
+}
+
+// cyclomaticComplexity returns the cyclomatic complexity of a function
+// body.
+func cyclomaticComplexity(body *ast.BlockStmt) int {
+	complexity := 1
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if n.List != nil {
+				complexity++
+			}
+		case *ast.CommClause:
+			if n.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+	return complexity
+}
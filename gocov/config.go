@@ -0,0 +1,223 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile is the configuration file read from the current
+// directory by commands that accept configuration.
+const defaultConfigFile = ".gocov.yaml"
+
+// config is the configuration read from .gocov.yaml.
+type config struct {
+	// Notify lists the targets that "gocov notify" posts to.
+	Notify []notifyTarget `yaml:"notify,omitempty"`
+
+	// Thresholds maps package import paths to the minimum coverage, in
+	// percent, that "gocov check" requires of them.
+	Thresholds map[string]float64 `yaml:"thresholds,omitempty"`
+
+	// MinHits maps package import paths to the number of times that
+	// "gocov check" requires each of their statements to be reached.
+	MinHits map[string]int64 `yaml:"min_hits,omitempty"`
+
+	// Groups defines named groups of packages, by import path pattern,
+	// whose coverage is reported and checked as a whole. Thresholds and
+	// minimum hit counts apply to groups by their name.
+	Groups packageGroups `yaml:"groups,omitempty"`
+
+	// Weights maps group names to the weight of their packages in the
+	// weighted total coverage of reports. Packages of no weighted group
+	// weigh 1.
+	Weights map[string]float64 `yaml:"weights,omitempty"`
+}
+
+// loadConfig reads the named configuration file. A missing default
+// configuration file yields the empty configuration.
+func loadConfig(filename string) (*config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) && filename == defaultConfigFile {
+			return &config{}, nil
+		}
+		return nil, err
+	}
+	c := &config{}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// setThresholds returns the configuration file with the given package and
+// group thresholds set. The file is edited where the thresholds are, so
+// that the rest of it, comments and layout included, is kept as it is.
+// Thresholds not yet configured are added to the end of the thresholds
+// section, in order of name, which is added if missing.
+func setThresholds(data []byte, values map[string]float64) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	var top *yaml.Node
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		top = root.Content[0]
+	}
+	if top != nil && top.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("configuration is not a mapping")
+	}
+	var key, thresholds *yaml.Node
+	for i := 0; top != nil && i+1 < len(top.Content); i += 2 {
+		if top.Content[i].Value == "thresholds" {
+			key, thresholds = top.Content[i], top.Content[i+1]
+		}
+	}
+	if thresholds != nil && thresholds.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("configuration thresholds are not a mapping")
+	}
+
+	var edits []textEdit
+	set := make(map[string]bool)
+	for i := 0; thresholds != nil && i+1 < len(thresholds.Content); i += 2 {
+		name, node := thresholds.Content[i].Value, thresholds.Content[i+1]
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		start, ok := nodeOffset(data, node)
+		if !ok || node.Kind != yaml.ScalarNode || node.Style != 0 || !bytes.HasPrefix(data[start:], []byte(node.Value)) {
+			return nil, fmt.Errorf("threshold of %s is not a plain number", name)
+		}
+		edits = append(edits, textEdit{start, start + len(node.Value), formatNumber(value)})
+		set[name] = true
+	}
+	var added []string
+	for name := range values {
+		if !set[name] {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	if len(added) > 0 {
+		edit, err := addedThresholds(data, key, thresholds, added, values)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, edit)
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	out := append([]byte(nil), data...)
+	for _, e := range edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+	return out, nil
+}
+
+// addedThresholds returns the edit adding the named thresholds to the end
+// of the thresholds section of a configuration file, or adding the
+// section, with them, to the end of the file if key is nil.
+func addedThresholds(data []byte, key, thresholds *yaml.Node, names []string, values map[string]float64) (textEdit, error) {
+	entries := func(indent string) string {
+		var b strings.Builder
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s%s: %s\n", indent, name, formatNumber(values[name]))
+		}
+		return b.String()
+	}
+	end := len(data)
+	separator := ""
+	if end > 0 && data[end-1] != '\n' {
+		separator = "\n"
+	}
+	switch {
+	case key == nil:
+		return textEdit{end, end, separator + "thresholds:\n" + entries("  ")}, nil
+	case len(thresholds.Content) == 0:
+		// An empty flow mapping, as in "thresholds: {}", is replaced by
+		// a block mapping.
+		start, ok := nodeOffset(data, thresholds)
+		if !ok || !bytes.HasPrefix(data[start:], []byte("{}")) {
+			return textEdit{}, fmt.Errorf("cannot add thresholds to the configuration")
+		}
+		end := start + 2
+		for start > 0 && (data[start-1] == ' ' || data[start-1] == '\t') {
+			start--
+		}
+		indent := strings.Repeat(" ", key.Column-1+2)
+		return textEdit{start, end, "\n" + strings.TrimSuffix(entries(indent), "\n")}, nil
+	case thresholds.Style&yaml.FlowStyle != 0:
+		return textEdit{}, fmt.Errorf("cannot add thresholds to the configuration's flow mapping")
+	}
+	// The entries go after the line of the last threshold.
+	first, last := thresholds.Content[0], thresholds.Content[len(thresholds.Content)-1]
+	start, ok := nodeOffset(data, last)
+	if !ok {
+		return textEdit{}, fmt.Errorf("cannot add thresholds to the configuration")
+	}
+	if i := bytes.IndexByte(data[start:], '\n'); i >= 0 {
+		end, separator = start+i+1, ""
+	}
+	return textEdit{end, end, separator + entries(strings.Repeat(" ", first.Column-1))}, nil
+}
+
+// textEdit replaces the bytes from start to end of a file with text.
+type textEdit struct {
+	start, end int
+	text       string
+}
+
+// nodeOffset returns the offset in data of the start of a node parsed from
+// it, whose column counts characters.
+func nodeOffset(data []byte, node *yaml.Node) (int, bool) {
+	offset := 0
+	for line := 1; line < node.Line; line++ {
+		i := bytes.IndexByte(data[offset:], '\n')
+		if i < 0 {
+			return 0, false
+		}
+		offset += i + 1
+	}
+	for column := 1; column < node.Column; column++ {
+		if offset >= len(data) || data[offset] == '\n' {
+			return 0, false
+		}
+		_, size := utf8.DecodeRune(data[offset:])
+		offset += size
+	}
+	return offset, true
+}
+
+// formatNumber formats a threshold as it is written in configuration.
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
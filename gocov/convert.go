@@ -0,0 +1,276 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hihoak/gocov/gocov/convert"
+)
+
+// convertOptions holds the flags shared by the commands that convert
+// coverprofiles.
+type convertOptions struct {
+	pathStyle    *string
+	lenient      *bool
+	ignoreCase   *bool
+	sourceRoot   *string
+	dir          *string
+	cacheDir     *string
+	bench        *bool
+	blocks       *bool
+	exclusions   *string
+	debugMatch   *bool
+	mocks        *bool
+	mmap         *bool
+	initializers *bool
+	types        *bool
+	labels       labelFlags
+	pkgs         stringsFlag
+	excludes     stringsFlag
+	rewrites     stringsFlag
+	classify     stringsFlag
+
+	// excluded collects the exclusions reported during conversion.
+	excluded []convert.Exclusion
+}
+
+// addConvertFlags defines the conversion flags in fs.
+func addConvertFlags(fs *flag.FlagSet) *convertOptions {
+	v := &convertOptions{
+		pathStyle: fs.String(
+			"path-style", "auto",
+			"How to interpret profile file names: auto, slash or windows"),
+		lenient: fs.Bool(
+			"lenient", false,
+			"Attribute profile blocks outside any function to a synthetic @file function"),
+		ignoreCase: fs.Bool(
+			"ignore-case", false,
+			"Match profile file names to source files regardless of case"),
+		sourceRoot: fs.String(
+			"source-root", "",
+			"Read source files relative to this directory instead of the file system root"),
+		dir: fs.String(
+			"dir", "",
+			"Resolve the packages named by profiles with the go tool in this directory, such as the root of the profiled module"),
+		cacheDir: fs.String(
+			"cache-dir", "",
+			"Cache parsed source files in this directory between runs"),
+		bench: fs.Bool(
+			"bench", false,
+			"Label the output as benchmark coverage (kind=benchmark)"),
+		blocks: fs.Bool(
+			"blocks", false,
+			"Include the profile blocks of each file in the output"),
+		exclusions: fs.String(
+			"exclusions-out", "",
+			"Write a JSON report of the files left out of the conversion, and why, to this file"),
+		debugMatch: fs.Bool(
+			"debug-match", false,
+			"Print to standard error which statements each profile block was matched to, and which blocks were orphaned"),
+		mocks: fs.Bool(
+			"mocks", false,
+			"Keep generated gomock, mockery and moq mocks, tagging their statements \"mock\""),
+		mmap: fs.Bool(
+			"mmap", false,
+			"Memory-map source files while parsing them, where supported"),
+		initializers: fs.Bool(
+			"initializers", false,
+			"Collect the initializers of package-level variables as statements of a function named \"init$vars\""),
+		types: fs.Bool(
+			"types", false,
+			"Load type information to record method receivers, API membership and implemented interfaces (slower)"),
+		labels: make(labelFlags),
+	}
+	fs.Var(v.labels, "label",
+		"Attach a key=value `label` to the output (repeatable)")
+	fs.Var(&v.pkgs, "pkg",
+		"Convert only packages matching the import path `pattern` (repeatable)")
+	fs.Var(&v.excludes, "exclude",
+		"Exclude packages or files matching the import path `pattern` (repeatable)")
+	fs.Var(&v.rewrites, "rewrite",
+		"Rewrite the import path prefix `old=new` in profiles (repeatable)")
+	fs.Var(&v.classify, "classify",
+		"Tag statements with the named `classifier`: error-paths, goroutines or panic-paths (repeatable)")
+	return v
+}
+
+// options returns the conversion options selected by the flags.
+func (v *convertOptions) options() ([]convert.Option, error) {
+	pathStyle, err := convert.ParsePathStyle(*v.pathStyle)
+	if err != nil {
+		return nil, err
+	}
+	opts := []convert.Option{
+		convert.WithPackages(v.pkgs...),
+		convert.WithExcludes(v.excludes...),
+		convert.WithPathStyle(pathStyle),
+	}
+	for _, rewrite := range v.rewrites {
+		i := strings.Index(rewrite, "=")
+		if i <= 0 || i == len(rewrite)-1 {
+			return nil, fmt.Errorf("invalid rewrite %q, expected old=new", rewrite)
+		}
+		opts = append(opts, convert.WithRewrite(rewrite[:i], rewrite[i+1:]))
+	}
+	for _, name := range v.classify {
+		fn, ok := classifiers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown classifier %q", name)
+		}
+		opts = append(opts, convert.WithClassifier(fn))
+	}
+	if fsys := sourceFS(*v.sourceRoot); fsys != nil {
+		opts = append(opts, convert.WithFS(fsys))
+	}
+	if *v.dir != "" {
+		opts = append(opts, convert.WithDir(*v.dir))
+	}
+	if *v.cacheDir != "" {
+		opts = append(opts, convert.WithCache(*v.cacheDir))
+	}
+	if *v.bench {
+		opts = append(opts, convert.WithLabels(benchmarkLabels))
+	}
+	if len(v.labels) > 0 {
+		opts = append(opts, convert.WithLabels(v.labels))
+	}
+	if *v.blocks {
+		opts = append(opts, convert.WithBlocks())
+	}
+	if *v.mocks {
+		opts = append(opts, convert.WithMocks())
+	}
+	if *v.initializers {
+		opts = append(opts, convert.WithInitializers())
+	}
+	if *v.types {
+		opts = append(opts, convert.WithTypes())
+	}
+	if *v.mmap {
+		opts = append(opts, convert.WithMmap())
+	}
+	if *v.lenient {
+		opts = append(opts, convert.WithLenientMatching())
+	}
+	if *v.ignoreCase {
+		opts = append(opts, convert.WithCaseInsensitivePaths())
+	}
+	if *v.exclusions != "" {
+		opts = append(opts, convert.WithExclusionHandler(func(e convert.Exclusion) {
+			v.excluded = append(v.excluded, e)
+		}))
+	}
+	opts = append(opts, convert.WithMismatchHandler(func(m convert.Mismatch) {
+		fmt.Fprintf(os.Stderr, "warning: %s: %d profile blocks matched no statement, %d statements matched no profile block\n",
+			m.File, m.OrphanedBlocks, m.UnmatchedStatements)
+	}))
+	opts = append(opts, convert.WithLoadErrorHandler(func(e convert.LoadError) {
+		fmt.Fprintf(os.Stderr, "warning: failed to load package %s, reading its files by path: %s\n",
+			e.Package, strings.Join(e.Errors, "; "))
+	}))
+	if *v.debugMatch {
+		opts = append(opts, convert.WithMatchHandler(func(m convert.BlockMatch) {
+			printMatch(os.Stderr, m)
+		}))
+	}
+	return opts, nil
+}
+
+// printResolveHint suggests how to convert profiles naming packages that
+// cannot be resolved, if err is the error of converting one.
+func printResolveHint(w io.Writer, err error) {
+	var unresolved *convert.UnresolvedPackageError
+	if !errors.As(err, &unresolved) {
+		return
+	}
+	fmt.Fprintf(w, "hint: run from the root of the module the profile was gathered in or pass it with -dir, "+
+		"or map the profile's import paths to ones that resolve with -rewrite, e.g. -rewrite %s=<import path>\n",
+		unresolved.ImportPath)
+}
+
+// printMatch prints a profile block and the statements it was matched to.
+func printMatch(w io.Writer, m convert.BlockMatch) {
+	b := m.Block
+	fmt.Fprintf(w, "%s:%d.%d,%d.%d %d %d: ", m.File, b.StartLine, b.StartCol, b.EndLine, b.EndCol, b.NumStmt, b.Count)
+	if len(m.Statements) == 0 {
+		fmt.Fprintln(w, "orphaned")
+		return
+	}
+	for i, s := range m.Statements {
+		if i > 0 {
+			fmt.Fprint(w, ", ")
+		}
+		fmt.Fprintf(w, "%s %d.%d,%d.%d", s.Function, s.StartLine, s.StartCol, s.EndLine, s.EndCol)
+	}
+	fmt.Fprintln(w)
+}
+
+// classifiers holds the statement classifiers selectable with -classify.
+var classifiers = map[string]convert.Classifier{
+	"error-paths": convert.ErrorPaths,
+	"goroutines":  convert.Goroutines,
+	"panic-paths": convert.PanicPaths,
+}
+
+var (
+	convertFlags      = flag.NewFlagSet("convert", flag.ExitOnError)
+	convertFlagValues = addConvertFlags(convertFlags)
+)
+
+// runConvert converts the profiles named by args with the options
+// selected by v and any extra options, writing the result to standard
+// output.
+func runConvert(args []string, v *convertOptions, extra ...convert.Option) (rc int) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "missing cover profile")
+		return 1
+	}
+	opts, err := v.options()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	out, err := convert.Convert(args, append(opts, extra...)...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		printResolveHint(os.Stderr, err)
+		return 1
+	}
+	if *v.exclusions != "" {
+		if err := writeExclusions(*v.exclusions, v.excluded); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write exclusions: %s\n", err)
+			return 1
+		}
+	}
+	os.Stdout.Write(out)
+	return 0
+}
+
+func convertProfiles() (rc int) {
+	convertFlags.Parse(os.Args[2:])
+	return runConvert(convertFlags.Args(), convertFlagValues)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// cacheVersion is mixed into cache keys, and must be changed whenever the
+// extents found for a source file, or their encoding, change.
+const cacheVersion = "gocov-extents-7"
+
+// WithCache caches the function and statement extents found in each
+// source file in dir, keyed by a hash of the file's contents, so that
+// repeated conversions (e.g. CI runs restoring dir between builds) only
+// parse files that have changed. The directory is created if necessary.
+func WithCache(dir string) Option {
+	return func(c *Converter) {
+		c.cacheDir = dir
+	}
+}
+
+// cachedFunc is the cached form of a FuncExtent.
+type cachedFunc struct {
+	Name      string
+	Parent    string `json:",omitempty"`
+	Signature string `json:",omitempty"`
+	Extent    [6]int
+	Stmts     [][6]int
+	Kinds     []string
+}
+
+func encodeExtent(e extent) [6]int {
+	return [6]int{e.startOffset, e.startLine, e.startCol, e.endOffset, e.endLine, e.endCol}
+}
+
+func decodeExtent(v [6]int) extent {
+	return extent{v[0], v[1], v[2], v[3], v[4], v[5]}
+}
+
+func cacheKey(src []byte) string {
+	h := sha256.New()
+	h.Write([]byte(cacheVersion))
+	h.Write(src)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// findFuncs is like the package-level findFuncs, but consults and updates
+// the converter's cache, if any. Classification depends on more than the
+// source, so the cache is bypassed when there are classifiers.
+func (c *Converter) findFuncs(name string, src []byte) ([]*FuncExtent, *token.File, error) {
+	if c.cacheDir == "" || len(c.classifiers) > 0 {
+		return findFuncs(name, src, c.classifier())
+	}
+	path := filepath.Join(c.cacheDir, cacheKey(src)+".json")
+	if extents, ok := loadExtents(path); ok {
+		file := token.NewFileSet().AddFile(name, -1, len(src))
+		file.SetLinesForContent(src)
+		return extents, file, nil
+	}
+	extents, file, err := findFuncs(name, src, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := storeExtents(path, extents); err != nil {
+		return nil, nil, err
+	}
+	return extents, file, nil
+}
+
+// loadExtents reads extents from a cache file. A missing or unreadable
+// entry is treated as a cache miss.
+func loadExtents(path string) ([]*FuncExtent, bool) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var cached []cachedFunc
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, false
+	}
+	extents := make([]*FuncExtent, len(cached))
+	for i, cf := range cached {
+		fe := &FuncExtent{extent: decodeExtent(cf.Extent), name: cf.Name, parent: cf.Parent, signature: cf.Signature}
+		if len(cf.Kinds) != len(cf.Stmts) {
+			return nil, false
+		}
+		for j, s := range cf.Stmts {
+			fe.stmts = append(fe.stmts, &StmtExtent{extent: decodeExtent(s), kind: cf.Kinds[j]})
+		}
+		extents[i] = fe
+	}
+	return extents, true
+}
+
+// storeExtents writes extents to a cache file. The file is written under
+// a temporary name and renamed into place, so that concurrent conversions
+// sharing the cache never observe a partial entry.
+func storeExtents(path string, extents []*FuncExtent) error {
+	cached := make([]cachedFunc, len(extents))
+	for i, fe := range extents {
+		cf := cachedFunc{Name: fe.name, Parent: fe.parent, Signature: fe.signature, Extent: encodeExtent(fe.extent)}
+		for _, se := range fe.stmts {
+			cf.Stmts = append(cf.Stmts, encodeExtent(se.extent))
+			cf.Kinds = append(cf.Kinds, se.kind)
+		}
+		cached[i] = cf
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// StatementInfo describes a statement collected from a source file to a
+// Classifier.
+type StatementInfo struct {
+	// Fset holds the position information of the file's syntax tree.
+	Fset *token.FileSet
+
+	// Function is the name of the function containing the statement, as
+	// reported in the converted coverage.
+	Function string
+
+	// Stmt is the statement's node, or for statements that stand for
+	// the header of a compound statement (e.g. the condition of an if
+	// statement), the compound statement's node.
+	Stmt ast.Stmt
+
+	// Enclosing holds the statements enclosing Stmt in its function,
+	// innermost last.
+	Enclosing []ast.Stmt
+
+	// Deferred is set if the function containing Stmt is a function
+	// literal called by a defer statement.
+	Deferred bool
+
+	// Spawned is set if the function containing Stmt is a function
+	// literal run as a goroutine by a go statement.
+	Spawned bool
+
+	// Pos and End are the positions of the source covered by the
+	// statement.
+	Pos, End token.Pos
+}
+
+// Classification is a Classifier's verdict on a statement.
+type Classification struct {
+	// Skip leaves the statement out of the coverage.
+	Skip bool
+
+	// Tags are recorded in the statement's Tags.
+	Tags []string
+
+	// Weight, if not zero, is recorded as the statement's Weight.
+	Weight float64
+}
+
+// Classifier classifies the statements collected from source files, e.g.
+// to leave out statements that only log. It may be called concurrently.
+type Classifier func(StatementInfo) Classification
+
+// WithClassifier classifies each statement collected from source files
+// with fn. Of several classifiers, a statement is skipped if any of them
+// skips it, its tags are those of all of them and its weight is the
+// product of theirs. Classification needs the syntax of every source
+// file, so a cache set with WithCache is not used.
+func WithClassifier(fn Classifier) Option {
+	return func(c *Converter) {
+		c.classifiers = append(c.classifiers, fn)
+	}
+}
+
+// classifier returns a Classifier combining the converter's classifiers,
+// or nil if there are none.
+func (c *Converter) classifier() Classifier {
+	if len(c.classifiers) == 0 {
+		return nil
+	}
+	classifiers := c.classifiers
+	return func(info StatementInfo) Classification {
+		var class Classification
+		for _, fn := range classifiers {
+			cl := fn(info)
+			class.Skip = class.Skip || cl.Skip
+			class.Tags = append(class.Tags, cl.Tags...)
+			if cl.Weight != 0 {
+				if class.Weight == 0 {
+					class.Weight = 1
+				}
+				class.Weight *= cl.Weight
+			}
+		}
+		return class
+	}
+}
+
+// collect adds a statement to the function being visited, unless it is
+// skipped by the visitor's classifier.
+func (v *StmtVisitor) collect(se *StmtExtent) {
+	if v.classify != nil {
+		stmt := v.stack[len(v.stack)-1]
+		file := v.fset.File(stmt.Pos())
+		class := v.classify(StatementInfo{
+			Fset:      v.fset,
+			Function:  v.function.name,
+			Stmt:      stmt,
+			Enclosing: v.stack[:len(v.stack)-1],
+			Deferred:  v.deferred,
+			Spawned:   v.spawned,
+			Pos:       file.Pos(se.startOffset),
+			End:       file.Pos(se.endOffset),
+		})
+		if class.Skip {
+			return
+		}
+		if len(class.Tags) > 0 || class.Weight != 0 {
+			if v.function.classes == nil {
+				v.function.classes = make(map[*StmtExtent]Classification)
+			}
+			v.function.classes[se] = class
+		}
+	}
+	v.function.stmts = append(v.function.stmts, se)
+}
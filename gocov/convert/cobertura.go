@@ -0,0 +1,91 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"encoding/xml"
+	"io"
+	"path/filepath"
+)
+
+// coberturaCoverage holds the parts of a Cobertura XML report that
+// record line coverage. Decoding XML of another root element, such as a
+// JaCoCo report, fails.
+type coberturaCoverage struct {
+	XMLName  xml.Name `xml:"coverage"`
+	Sources  []string `xml:"sources>source"`
+	Packages []struct {
+		Name    string `xml:"name,attr"`
+		Classes []struct {
+			Name     string `xml:"name,attr"`
+			Filename string `xml:"filename,attr"`
+			Methods  []struct {
+				Name  string          `xml:"name,attr"`
+				Lines []coberturaLine `xml:"lines>line"`
+			} `xml:"methods>method"`
+			Lines []coberturaLine `xml:"lines>line"`
+		} `xml:"classes>class"`
+	} `xml:"packages>package"`
+}
+
+type coberturaLine struct {
+	Number int   `xml:"number,attr"`
+	Hits   int64 `xml:"hits,attr"`
+}
+
+// parseCobertura parses a Cobertura XML report, as written by gocov's
+// own Cobertura reports and by coverage tools for many other languages.
+// The file names of classes are relative to the report's sources; see
+// lineFileName. Files of Go packages are named by the
+// directory of the file, so that the lines of Go files line up with the
+// packages resolved for them; those of others take the name of their
+// Cobertura package.
+//
+// A class named other than its file, as in languages that have classes,
+// qualifies the names of its methods, as in "Class.method".
+func (c *Converter) parseCobertura(r io.Reader) ([]*lineCoverage, error) {
+	var report coberturaCoverage
+	if err := xml.NewDecoder(r).Decode(&report); err != nil {
+		return nil, err
+	}
+	var files lineFiles
+	for _, pkg := range report.Packages {
+		for _, class := range pkg.Classes {
+			f := files.file(c.lineFileName(class.Filename, report.Sources), pkg.Name)
+			if f.isGo() {
+				f.pkg = ""
+			}
+			for _, method := range class.Methods {
+				name := method.Name
+				if class.Name != "" && class.Name != filepath.Base(class.Filename) {
+					name = class.Name + "." + name
+				}
+				for _, line := range method.Lines {
+					f.function(name, line.Number)
+				}
+			}
+			for _, line := range class.Lines {
+				f.counts[line.Number] += line.Hits
+			}
+		}
+	}
+	return files.sorted(), nil
+}
@@ -22,6 +22,7 @@ package convert
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"github.com/hihoak/gocov"
@@ -29,36 +30,171 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"golang.org/x/tools/cover"
-	goPackages "golang.org/x/tools/go/packages"
-	"io"
-	"path"
+	"io/fs"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 )
 
-func marshalJson(w io.Writer, packages []*gocov.Package) error {
-	return json.NewEncoder(w).Encode(struct{ Packages []*gocov.Package }{packages})
+// Converter converts coverprofiles into gocov's data model. Create one
+// with NewConverter.
+type Converter struct {
+	packages    []string
+	excludes    []string
+	pathStyle   PathStyle
+	lenient     bool
+	foldCase    bool
+	rewrites    [][2]string
+	dir         string
+	buildFlags  []string
+	env         []string
+	concurrency int
+	fsys        fs.FS
+	resolver    Resolver
+	shardIndex  int
+	shardTotal  int
+	cacheDir    string
+	labels      map[string]string
+	blocks      bool
+	excluded    func(Exclusion)
+	matched     func(BlockMatch)
+	mismatched  func(Mismatch)
+	loadFailed  func(LoadError)
+	classifiers []Classifier
+	mocks       bool
+	mmap        bool
+
+	initializers bool
+	loadTypes    bool
+
+	// handlerMu serializes the calls of handlers from concurrent
+	// conversions.
+	handlerMu sync.Mutex
 }
 
+// NewConverter returns a Converter configured by the given options.
+func NewConverter(opts ...Option) *Converter {
+	c := &Converter{concurrency: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.concurrency < 1 {
+		c.concurrency = 1
+	}
+	if c.resolver == nil {
+		r := newPackagesResolver(c.dir, c.foldCase)
+		r.buildFlags, r.env = c.buildFlags, c.env
+		if c.loadTypes {
+			r.types = make(map[string]*types.Package)
+		}
+		c.resolver = r
+	}
+	return c
+}
+
+// ConvertProfiles converts the named coverprofiles into gocov's JSON
+// interchange format, merging their coverage.
 func ConvertProfiles(filenames ...string) ([]byte, error) {
-	var (
-		ps gocovutil.Packages
-	)
+	return NewConverter().ConvertProfiles(filenames...)
+}
 
-	for i := range filenames {
-		converter := converter{
-			packages: make(map[string]*gocov.Package),
+// Convert is like ConvertProfiles, but accepts options controlling the
+// conversion.
+func Convert(filenames []string, opts ...Option) ([]byte, error) {
+	return NewConverter(opts...).ConvertProfiles(filenames...)
+}
+
+// ConvertProfiles converts the named coverprofiles into gocov's JSON
+// interchange format, merging their coverage.
+func (c *Converter) ConvertProfiles(filenames ...string) ([]byte, error) {
+	ps, err := c.Packages(filenames...)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.Buffer{}
+	doc := &gocovutil.Document{Packages: ps, Labels: c.labels}
+	if err := gocovutil.WriteDocument(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// matcher returns a function reporting whether a path matches any of the
+// given patterns, or nil if there are no patterns.
+func matcher(patterns []string) func(string) bool {
+	if len(patterns) == 0 {
+		return nil
+	}
+	var matchers []func(string) bool
+	for _, pattern := range patterns {
+		matchers = append(matchers, MatchPattern(pattern))
+	}
+	return func(p string) bool {
+		for _, match := range matchers {
+			if match(p) {
+				return true
+			}
 		}
-		profiles, err := cover.ParseProfiles(filenames[i])
+		return false
+	}
+}
+
+// fileJob is a source file to be converted, with its profile.
+type fileJob struct {
+	profile *cover.Profile
+	abspath string
+	pkgPath string
+}
+
+// Packages converts the named coverprofiles into gocov's data model,
+// merging their coverage. Glob patterns and directories may be given in
+// place of profiles: directories are searched recursively for "*.out"
+// profiles and for binary coverage data directories (see GOCOVERDIR),
+// which are converted with "go tool covdata". The format of each file is
+// detected from its content, so LCOV tracefiles, gocov JSON documents and
+// binary coverage data files may be given alongside coverprofiles.
+func (c *Converter) Packages(filenames ...string) (gocovutil.Packages, error) {
+	filenames, covdata, err := expandProfiles(filenames)
+	if err != nil {
+		return nil, err
+	}
+	if len(covdata) > 0 {
+		tmpDir, err := ioutil.TempDir("", "gocov-covdata")
 		if err != nil {
 			return nil, err
 		}
+		defer os.RemoveAll(tmpDir)
+		profile, err := covdataProfile(covdata, tmpDir)
+		if err != nil {
+			return nil, err
+		}
+		filenames = append(filenames, profile)
+	}
+	inputs, err := c.parseProfiles(filenames)
+	if err != nil {
+		return nil, err
+	}
+	included := matcher(c.packages)
+	excluded := matcher(c.excludes)
 
-		mapUniqPackageNames := make(map[string]interface{})
-		uniqPackageNames := make([]string, 0, len(profiles))
-		for _, profile := range profiles {
-			packageName := path.Dir(profile.FileName)
+	// Select the profiles of each input, so that the packages of all
+	// inputs can be resolved at once.
+	selected := make([][]*cover.Profile, len(inputs))
+	mapUniqPackageNames := make(map[string]interface{})
+	var uniqPackageNames []string
+	for i, in := range inputs {
+		for _, profile := range in.profiles {
+			profile.FileName = c.rewrite(profile.FileName)
+			packageName, filename := splitProfileFileName(profile.FileName, c.pathStyle)
+			if !c.selects(included, excluded, packageName, filename) {
+				continue
+			}
+			selected[i] = append(selected[i], profile)
 
 			if _, ok := mapUniqPackageNames[packageName]; ok {
 				continue
@@ -67,91 +203,463 @@ func ConvertProfiles(filenames ...string) ([]byte, error) {
 			mapUniqPackageNames[packageName] = nil
 			uniqPackageNames = append(uniqPackageNames, packageName)
 		}
+	}
+	if len(uniqPackageNames) == 0 {
+		return c.addImported(nil, inputs, included, excluded)
+	}
+	if p, ok := c.resolver.(preloader); ok {
+		if err := p.preload(uniqPackageNames); err != nil {
+			return nil, err
+		}
+	}
 
-		packages, err := goPackages.Load(&goPackages.Config{
-			Mode: goPackages.NeedName | goPackages.NeedCompiledGoFiles,
-		}, uniqPackageNames...)
-		if err != nil {
-			return nil, fmt.Errorf("load packages: %v", err)
+	// The source files of all inputs are converted together; starts[i]
+	// is the index of the first job of input i.
+	var jobs []fileJob
+	starts := make([]int, len(inputs)+1)
+	skipped := make(map[string]string)
+	for i, profiles := range selected {
+		starts[i] = len(jobs)
+		for _, profile := range profiles {
+			pkgpath, filename := splitProfileFileName(profile.FileName, c.pathStyle)
+			files, err := c.resolve(pkgpath)
+			if err != nil {
+				return nil, &UnresolvedPackageError{ImportPath: pkgpath, File: profile.FileName, Dir: c.dir, Err: err}
+			}
+			marker, ok := skipped[pkgpath]
+			if !ok {
+				marker = c.skipMarkerFile(files)
+				skipped[pkgpath] = marker
+				c.reportLoadErrors(pkgpath)
+			}
+			if marker != "" {
+				c.exclude(pkgpath, filename, "package marked "+SkipPackageMarker+" in "+marker)
+				continue
+			}
+			if abspath := findSourceFile(files, filename, c.foldCase); abspath != "" {
+				jobs = append(jobs, fileJob{profile, c.canonicalPath(abspath), pkgpath})
+			} else if errs := c.loadErrors(pkgpath); len(errs) > 0 {
+				c.exclude(pkgpath, filename, "source file not found: package failed to load: "+strings.Join(errs, "; "))
+			} else {
+				c.exclude(pkgpath, filename, "source file not found")
+			}
 		}
+	}
+	starts[len(inputs)] = len(jobs)
+	functions, err := c.convertFiles(jobs)
+	if err != nil {
+		return nil, err
+	}
 
-		pkgmap := make(map[string]*goPackages.Package, len(packages))
-		for _, pkg := range packages {
-			pkgmap[pkg.PkgPath] = pkg
+	var ps gocovutil.Packages
+	for i := range inputs {
+		// Functions are added in profile order, so that the packages
+		// produced from different profiles line up for accumulation.
+		converted := make(map[string]*gocov.Package)
+		var order []*gocov.Package
+		for j := starts[i]; j < starts[i+1]; j++ {
+			job := jobs[j]
+			pkg := converted[job.pkgPath]
+			if pkg == nil {
+				pkg = &gocov.Package{Name: job.pkgPath}
+				converted[job.pkgPath] = pkg
+				order = append(order, pkg)
+			}
+			pkg.Functions = append(pkg.Functions, functions[j]...)
+			if c.blocks {
+				pkg.Files = append(pkg.Files, profileFile(job.profile, job.abspath))
+			}
+		}
+		for _, pkg := range order {
+			if c.initializers {
+				markInitializers(pkg)
+			}
+			if c.loadTypes {
+				c.describeTypes(pkg)
+			}
 		}
+		ps.AddPackages(order)
+	}
+	return c.addImported(ps, inputs, included, excluded)
+}
 
-		for _, profile := range profiles {
-			pkgpath, filename := path.Split(profile.FileName)
-			pkgpath = strings.TrimSuffix(pkgpath, "/")
-			pkg := pkgmap[pkgpath]
-			for _, abspath := range pkg.CompiledGoFiles {
-				if filepath.Base(abspath) == filename {
-					if err := converter.convertProfile(profile, abspath, pkg.PkgPath); err != nil {
-						return nil, fmt.Errorf("convert profile %s: %w", profile.FileName, err)
-					}
-				}
+// selects reports whether the file of the package with the given import
+// path is selected for conversion by the package patterns, the exclude
+// patterns and the shard, reporting its exclusion if not. An empty
+// filename stands for the whole package.
+func (c *Converter) selects(included, excluded func(string) bool, packageName, filename string) bool {
+	if included != nil && !included(packageName) {
+		c.exclude(packageName, filename, "not matched by package patterns "+strings.Join(c.packages, " "))
+		return false
+	}
+	if excluded != nil && (excluded(packageName) || filename != "" && excluded(packageName+"/"+filename)) {
+		pattern := matchingPattern(c.excludes, packageName)
+		if pattern == "" {
+			pattern = matchingPattern(c.excludes, packageName+"/"+filename)
+		}
+		c.exclude(packageName, filename, "matched exclude pattern "+pattern)
+		return false
+	}
+	if c.shardTotal > 0 && !inShard(packageName, c.shardIndex, c.shardTotal) {
+		c.exclude(packageName, filename, fmt.Sprintf("not in shard %d of %d", c.shardIndex, c.shardTotal))
+		return false
+	}
+	return true
+}
+
+// addImported merges the selected packages of the inputs that need no
+// conversion into ps: those of gocov JSON documents, the coverage of
+// istanbul reports, and the line coverage of source files other than Go,
+// which is merged across inputs before it is imported. Packages are
+// merged rather than accumulated, as they may have been converted for
+// other platforms or from other revisions.
+func (c *Converter) addImported(ps gocovutil.Packages, inputs []input, included, excluded func(string) bool) (gocovutil.Packages, error) {
+	// importSource imports the named source file with importFile, if it
+	// is selected, into the given package or else the file's directory.
+	importSource := func(name, pkgName string, importFile func(pkgName, abspath string) (*gocov.Package, error)) error {
+		pkgpath, filename := splitProfileFileName(c.rewrite(name), c.pathStyle)
+		if pkgName == "" {
+			pkgName = pkgpath
+		}
+		if !c.selects(included, excluded, pkgName, filename) {
+			return nil
+		}
+		pkg, err := importFile(pkgName, filepath.FromSlash(name))
+		if os.IsNotExist(err) {
+			c.exclude(pkgName, filename, "source file not found")
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := ps.MergePackage(pkg); err != nil {
+			return fmt.Errorf("merge package %s: %v", pkg.Name, err)
+		}
+		return nil
+	}
+
+	var lines lineFiles
+	for _, in := range inputs {
+		for _, f := range in.lines {
+			lines.add(f)
+		}
+	}
+	for _, f := range lines.sorted() {
+		f := f
+		err := importSource(f.name, f.pkg, func(pkgName, abspath string) (*gocov.Package, error) {
+			return c.importFile(f, pkgName, abspath)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, in := range inputs {
+		for _, f := range in.istanbul {
+			f := f
+			err := importSource(f.name, "", func(pkgName, abspath string) (*gocov.Package, error) {
+				return c.importIstanbul(f, pkgName, abspath)
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, pkg := range in.packages {
+			if !c.selects(included, excluded, pkg.Name, "") {
+				continue
+			}
+			if err := ps.MergePackage(pkg); err != nil {
+				return nil, fmt.Errorf("merge package %s: %v", pkg.Name, err)
 			}
 		}
+	}
+	return ps, nil
+}
+
+// input is a parsed profile.
+type input struct {
+	// profiles holds the coverprofile blocks of Go source files.
+	profiles []*cover.Profile
+
+	// lines holds the line coverage of other source files, as recorded
+	// by LCOV and Cobertura reports.
+	lines []*lineCoverage
+
+	// istanbul holds the coverage of source files in istanbul reports.
+	istanbul []*istanbulFile
 
-		for _, pkg := range converter.packages {
-			ps.AddPackage(pkg)
+	// packages holds coverage already in gocov's model, as read from
+	// gocov JSON documents.
+	packages gocovutil.Packages
+}
+
+// parseProfiles parses the named profiles concurrently, returning them
+// in the order of filenames. The format of each file is sniffed from its
+// content. The line coverage of Go files in LCOV and Cobertura reports is
+// turned into profiles, so that it is converted like coverprofiles. A
+// file whose content is identical to that of an earlier one, as when a
+// profile is passed twice by overlapping globs, yields nothing, so that
+// its counts are not added twice.
+func (c *Converter) parseProfiles(filenames []string) ([]input, error) {
+	inputs := make([]input, len(filenames))
+	digests := make([][sha256.Size]byte, len(filenames))
+	errs := make([]error, len(filenames))
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+	for i, filename := range filenames {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, filename string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			data, err := ioutil.ReadFile(filename)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			digests[i] = sha256.Sum256(data)
+			inputs[i], err = c.parseProfile(data)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %v", filename, err)
+			}
+		}(i, filename)
+	}
+	wg.Wait()
+	seen := make(map[[sha256.Size]byte]bool, len(filenames))
+	for i, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+		if seen[digests[i]] {
+			inputs[i] = input{}
 		}
+		seen[digests[i]] = true
 	}
-	buf := bytes.Buffer{}
-	if err := marshalJson(&buf, ps); err != nil {
+	return inputs, nil
+}
+
+// parseProfile parses a profile in any of the formats of sniffFormat.
+func (c *Converter) parseProfile(data []byte) (input, error) {
+	var lines []*lineCoverage
+	var err error
+	switch sniffFormat(data) {
+	case formatJSON:
+		// Other coverage reports in JSON are keyed by file name, rather
+		// than having the fields of a gocov JSON document.
+		var doc struct {
+			gocovutil.Document
+			Result json.RawMessage `json:"result"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return input{}, err
+		}
+		switch {
+		case doc.Packages != nil || doc.Labels != nil || doc.Inputs != nil || doc.Signature != "":
+			return input{packages: doc.Packages}, nil
+		case doc.Result != nil:
+			return input{}, fmt.Errorf("V8 coverage must first be converted to istanbul JSON, e.g. with c8 report --reporter=json")
+		}
+		files, err := c.parseIstanbul(data)
+		return input{istanbul: files}, err
+	case formatLCOV:
+		lines, err = c.parseLCOV(bytes.NewReader(data))
+	case formatCobertura:
+		lines, err = c.parseCobertura(bytes.NewReader(data))
+	default:
+		profiles, err := cover.ParseProfilesFromReader(bytes.NewReader(data))
+		return input{profiles: profiles}, err
+	}
+	if err != nil {
+		return input{}, err
+	}
+	var in input
+	for _, f := range lines {
+		if f.isGo() {
+			in.profiles = append(in.profiles, f.profile())
+		} else {
+			in.lines = append(in.lines, f)
+		}
+	}
+	return in, nil
+}
+
+// convertFiles converts the source files concurrently, returning the
+// functions of each in the order of jobs.
+func (c *Converter) convertFiles(jobs []fileJob) ([][]*gocov.Function, error) {
+	functions := make([][]*gocov.Function, len(jobs))
+	errs := make([]error, len(jobs))
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, job fileJob) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			functions[i], errs[i] = c.convertFile(job.profile, job.abspath)
+		}(i, job)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("convert profile %s: %w", jobs[i].profile.FileName, err)
+		}
+	}
+	return functions, nil
+}
+
+// profileFile returns the blocks recorded for a source file in the profile.
+func profileFile(p *cover.Profile, absFilePath string) *gocov.File {
+	f := &gocov.File{File: absFilePath, Mode: p.Mode}
+	for _, b := range p.Blocks {
+		f.Blocks = append(f.Blocks, &gocov.Block{
+			StartLine: b.StartLine,
+			StartCol:  b.StartCol,
+			EndLine:   b.EndLine,
+			EndCol:    b.EndCol,
+			NumStmt:   b.NumStmt,
+			Count:     int64(b.Count),
+		})
+	}
+	return f
+}
+
+// findSourceFile returns the first of the files with the given base name,
+// compared regardless of case if foldCase is set, or "" if there is no
+// such file.
+func findSourceFile(files []string, filename string, foldCase bool) string {
+	for _, abspath := range files {
+		base := filepath.Base(abspath)
+		if base == filename || foldCase && strings.EqualFold(base, filename) {
+			return abspath
+		}
+	}
+	return ""
+}
+
+// resolve resolves the package with the given import path. Packages named
+// by directory are retried with symbolic links in the directory evaluated,
+// since profiles and the go tool may disagree on whether to evaluate
+// them, as for symlinked GOPATHs or macOS's /var and /private/var.
+func (c *Converter) resolve(pkgpath string) ([]string, error) {
+	files, err := c.resolver.Resolve(pkgpath)
+	if err == nil || c.fsys != nil || !filepath.IsAbs(filepath.FromSlash(pkgpath)) {
+		return files, err
+	}
+	dir, lerr := filepath.EvalSymlinks(filepath.FromSlash(pkgpath))
+	if lerr != nil || filepath.ToSlash(dir) == pkgpath {
 		return nil, err
 	}
-	return buf.Bytes(), nil
+	if files, lerr := c.resolver.Resolve(filepath.ToSlash(dir)); lerr == nil {
+		return files, nil
+	}
+	return nil, err
 }
 
-type converter struct {
-	packages map[string]*gocov.Package
+// canonicalPath returns the path of a source file with symbolic links
+// evaluated, so that the files of the same package line up across
+// conversions of checkouts reached through different links. Paths in a
+// file system set by WithFS are left alone.
+func (c *Converter) canonicalPath(abspath string) string {
+	if c.fsys != nil {
+		return abspath
+	}
+	if p, err := filepath.EvalSymlinks(abspath); err == nil {
+		return p
+	}
+	return abspath
 }
 
 // wrapper for gocov.Statement
 type statement struct {
 	*gocov.Statement
 	*StmtExtent
+	function string
 }
 
-func (c *converter) convertProfile(p *cover.Profile, absFilePath, pkgPath string) error {
-	pkg := c.packages[pkgPath]
-	if pkg == nil {
-		pkg = &gocov.Package{Name: pkgPath}
-		c.packages[pkgPath] = pkg
+// readSource reads a source file, memory-mapping it if so configured. The
+// parser copies what it keeps of the source, so the mapping may be
+// released once the file's functions have been found.
+func (c *Converter) readSource(absFilePath string) ([]byte, func(), error) {
+	if c.mmap {
+		return gocovutil.MapSource(c.fsys, absFilePath)
 	}
+	src, err := gocovutil.ReadSource(c.fsys, absFilePath)
+	return src, func() {}, err
+}
+
+// convertFile returns the functions of a source file, with the coverage
+// recorded for it in the profile.
+func (c *Converter) convertFile(p *cover.Profile, absFilePath string) ([]*gocov.Function, error) {
 	// Find function and statement extents; create corresponding
 	// gocov.Functions and gocov.Statements, and keep a separate
 	// slice of gocov.Statements so we can match them with profile
 	// blocks.
-	extents, err := findFuncs(absFilePath)
+	src, release, err := c.readSource(absFilePath)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer release()
+	pkgpath, filename := splitProfileFileName(p.FileName, c.pathStyle)
+	mock := mockGenerator(src)
+	if mock != "" && !c.mocks {
+		c.exclude(pkgpath, filename, "mock generated by "+mock)
+		return nil, nil
+	}
+	extents, file, err := c.findFuncs(absFilePath, src)
+	if err != nil {
+		return nil, err
+	}
+	if !c.initializers {
+		extents = withoutInitializers(extents)
 	}
 
+	var functions []*gocov.Function
 	var stmts []statement
 	for _, fe := range extents {
 		f := &gocov.Function{
-			Name:  fe.name,
-			File:  absFilePath,
-			Start: fe.startOffset,
-			End:   fe.endOffset,
+			Name:   fe.name,
+			Parent: fe.parent,
+			File:   absFilePath,
+			Start:  fe.startOffset,
+			End:    fe.endOffset,
+			ID:     gocovutil.FunctionID(pkgpath, filename, fe.name, fe.signature),
 		}
 		for _, se := range fe.stmts {
 			s := statement{
-				Statement:  &gocov.Statement{Start: se.startOffset, End: se.endOffset},
+				Statement:  &gocov.Statement{Start: se.startOffset, End: se.endOffset, Kind: se.kind},
 				StmtExtent: se,
+				function:   fe.name,
+			}
+			if class, ok := fe.classes[se]; ok {
+				s.Tags, s.Weight = class.Tags, class.Weight
+			}
+			if mock != "" {
+				s.Tags = append(s.Tags, MockTag)
 			}
 			f.Statements = append(f.Statements, s.Statement)
-			stmts = append(stmts, s)
+			if fe.name != initializerName {
+				// Initializers have no blocks of their own;
+				// see markInitializers.
+				stmts = append(stmts, s)
+			}
 		}
-		pkg.Functions = append(pkg.Functions, f)
+		functions = append(functions, f)
 	}
 	// For each profile block in the file, find the statement(s) it
 	// covers and increment the Reached field(s).
 	blocks := p.Blocks
+	var matches [][]MatchedStatement
+	if c.matched != nil {
+		matches = make([][]MatchedStatement, len(blocks))
+	}
+	matched := make([]bool, len(blocks))
+	mismatch := Mismatch{File: absFilePath}
 	for _, s := range stmts {
-		for _, b := range blocks {
+		found := false
+		for i, b := range blocks {
 			if b.StartLine > s.endLine || (b.StartLine == s.endLine && b.StartCol >= s.endCol) {
 				// Past the end of the statement
 				break
@@ -162,22 +670,112 @@ func (c *converter) convertProfile(p *cover.Profile, absFilePath, pkgPath string
 			}
 
 			s.Reached += int64(b.Count)
+			matched[i], found = true, true
+			if matches != nil {
+				matches[i] = append(matches[i], MatchedStatement{
+					Function:  s.function,
+					StartLine: s.startLine,
+					StartCol:  s.startCol,
+					EndLine:   s.endLine,
+					EndCol:    s.endCol,
+				})
+			}
+		}
+		if !found {
+			mismatch.UnmatchedStatements++
+		}
+	}
+	for i, ok := range matched {
+		// Blocks of no statements, such as empty function bodies,
+		// carry no coverage to lose.
+		if !ok && blocks[i].NumStmt > 0 {
+			mismatch.OrphanedBlocks++
+		}
+	}
+	if c.mismatched != nil {
+		c.reportMismatch(mismatch)
+	}
+	if matches != nil {
+		c.reportMatches(absFilePath, profileFile(p, absFilePath).Blocks, matches)
+	}
+
+	if c.lenient {
+		if f := orphanFunction(blocks, extents, file, absFilePath); f != nil {
+			functions = append(functions, f)
 		}
 	}
+	return functions, nil
+}
 
-	return nil
+// orphanFunction returns a synthetic function holding a statement for each
+// profile block that lies outside all function extents, or nil if there
+// are no such blocks.
+func orphanFunction(blocks []cover.ProfileBlock, extents []*FuncExtent, file *token.File, absFilePath string) *gocov.Function {
+	var f *gocov.Function
+	for _, b := range blocks {
+		orphan := true
+		for _, fe := range extents {
+			if !before(b.EndLine, b.EndCol, fe.startLine, fe.startCol) &&
+				!before(fe.endLine, fe.endCol, b.StartLine, b.StartCol) {
+				orphan = false
+				break
+			}
+		}
+		if !orphan {
+			continue
+		}
+		if f == nil {
+			f = &gocov.Function{Name: "@file", File: absFilePath, End: file.Size()}
+		}
+		f.Statements = append(f.Statements, &gocov.Statement{
+			Start:   lineColOffset(file, b.StartLine, b.StartCol),
+			End:     lineColOffset(file, b.EndLine, b.EndCol),
+			Reached: int64(b.Count),
+		})
+	}
+	return f
 }
 
-// findFuncs parses the file and returns a slice of FuncExtent descriptors.
-func findFuncs(name string) ([]*FuncExtent, error) {
+// before reports whether line:col l1:c1 is at or before l2:c2.
+func before(l1, c1, l2, c2 int) bool {
+	return l1 < l2 || (l1 == l2 && c1 <= c2)
+}
+
+// lineColOffset converts a 1-based line and column to a file offset,
+// clamping positions that are out of range.
+func lineColOffset(file *token.File, line, col int) int {
+	if line < 1 {
+		return 0
+	}
+	if line > file.LineCount() {
+		return file.Size()
+	}
+	offset := file.Offset(file.LineStart(line)) + col - 1
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > file.Size() {
+		offset = file.Size()
+	}
+	return offset
+}
+
+// findFuncs parses the file's source and returns a slice of FuncExtent
+// descriptors, along with the file's position information. Statements are
+// classified by classify, if it is not nil.
+func findFuncs(name string, src []byte, classify Classifier) ([]*FuncExtent, *token.File, error) {
 	fset := token.NewFileSet()
-	parsedFile, err := parser.ParseFile(fset, name, nil, 0)
+	parsedFile, err := parser.ParseFile(fset, name, src, 0)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	visitor := &FuncVisitor{fset: fset}
+	visitor := &FuncVisitor{fset: fset, classify: classify}
 	ast.Walk(visitor, parsedFile)
-	return visitor.funcs, nil
+	funcs := visitor.funcs
+	if fe := initializerExtent(fset, parsedFile); fe != nil {
+		funcs = append(funcs, fe)
+	}
+	return funcs, fset.File(parsedFile.Pos()), nil
 }
 
 type extent struct {
@@ -189,20 +787,82 @@ type extent struct {
 	endCol      int
 }
 
+// nodeExtent returns the extent of a syntax node.
+func nodeExtent(fset *token.FileSet, node ast.Node) extent {
+	start, end := fset.Position(node.Pos()), fset.Position(node.End())
+	return extent{
+		startOffset: start.Offset,
+		startLine:   start.Line,
+		startCol:    start.Column,
+		endOffset:   end.Offset,
+		endLine:     end.Line,
+		endCol:      end.Column,
+	}
+}
+
 // FuncExtent describes a function's extent in the source by file and position.
 type FuncExtent struct {
 	extent
-	name  string
-	stmts []*StmtExtent
+	name      string
+	parent    string
+	signature string
+	stmts     []*StmtExtent
+
+	// classes holds the classifications of statements that were tagged
+	// or reweighted by a Classifier.
+	classes map[*StmtExtent]Classification
 }
 
 // StmtExtent describes a statements's extent in the source by file and position.
-type StmtExtent extent
+type StmtExtent struct {
+	extent
+	kind string
+}
 
 // FuncVisitor implements the visitor that builds the function position list for a file.
 type FuncVisitor struct {
-	fset  *token.FileSet
-	funcs []*FuncExtent
+	fset     *token.FileSet
+	funcs    []*FuncExtent
+	classify Classifier
+
+	// closures holds the names of the file's function literals; see
+	// nameClosures.
+	closures     map[*ast.FuncLit]closureName
+	initClosures int
+
+	// inits counts the file's init functions, and initsVisited those
+	// visited so far.
+	inits, initsVisited int
+
+	// deferred and spawned hold the file's function literals called by
+	// defer and go statements respectively.
+	deferred map[*ast.FuncLit]bool
+	spawned  map[*ast.FuncLit]bool
+}
+
+// closureName is the name of a function literal, and that of the
+// function enclosing it.
+type closureName struct {
+	name, parent string
+}
+
+// nameClosures names the function literals within node as the compiler
+// does: after their enclosing function, as F.func1, F.func2 and so on, and
+// closures nested in F.func1 as F.func1.1, F.func1.2. Unlike names made
+// from positions, these stay the same when unrelated code moves.
+func nameClosures(names map[*ast.FuncLit]closureName, node ast.Node, parent, prefix string, n *int) {
+	ast.Inspect(node, func(node ast.Node) bool {
+		lit, ok := node.(*ast.FuncLit)
+		if !ok {
+			return true
+		}
+		*n++
+		name := fmt.Sprintf("%s%d", prefix, *n)
+		names[lit] = closureName{name, parent}
+		var nested int
+		nameClosures(names, lit.Body, name, name+".", &nested)
+		return false
+	})
 }
 
 func functionName(f *ast.FuncDecl) string {
@@ -229,36 +889,111 @@ func exprName(x ast.Expr) string {
 	}
 }
 
+// signature returns the signature of a function with the given receiver
+// and type: the types of its receiver, parameters and results, without
+// their names, so that renaming a parameter leaves it unchanged.
+func signature(recv *ast.FieldList, typ *ast.FuncType) string {
+	var b strings.Builder
+	fields := func(list *ast.FieldList) {
+		b.WriteByte('(')
+		if list != nil {
+			for i, field := range list.List {
+				for j := 0; j < len(field.Names) || j == 0; j++ {
+					if i > 0 || j > 0 {
+						b.WriteString(", ")
+					}
+					b.WriteString(types.ExprString(field.Type))
+				}
+			}
+		}
+		b.WriteByte(')')
+	}
+	if recv != nil {
+		fields(recv)
+	}
+	fields(typ.Params)
+	fields(typ.Results)
+	return b.String()
+}
+
 // Visit implements the ast.Visitor interface.
 func (v *FuncVisitor) Visit(node ast.Node) ast.Visitor {
 	var body *ast.BlockStmt
-	var name string
+	var name, parent, sig string
+	var deferred, spawned bool
+	if v.closures == nil {
+		v.closures = make(map[*ast.FuncLit]closureName)
+	}
 	switch n := node.(type) {
+	case *ast.File:
+		// Closures in package-level declarations run during package
+		// initialization.
+		for _, decl := range n.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				nameClosures(v.closures, decl, "", "init.func", &v.initClosures)
+			case *ast.FuncDecl:
+				if decl.Recv == nil && decl.Name.Name == "init" {
+					v.inits++
+				}
+			}
+		}
+		v.deferred = make(map[*ast.FuncLit]bool)
+		v.spawned = make(map[*ast.FuncLit]bool)
+		ast.Inspect(n, func(node ast.Node) bool {
+			switch s := node.(type) {
+			case *ast.DeferStmt:
+				if lit, ok := s.Call.Fun.(*ast.FuncLit); ok {
+					v.deferred[lit] = true
+				}
+			case *ast.GoStmt:
+				if lit, ok := s.Call.Fun.(*ast.FuncLit); ok {
+					v.spawned[lit] = true
+				}
+			}
+			return true
+		})
 	case *ast.FuncLit:
 		body = n.Body
+		name, parent = v.closures[n].name, v.closures[n].parent
+		sig = signature(nil, n.Type)
+		deferred, spawned = v.deferred[n], v.spawned[n]
 	case *ast.FuncDecl:
 		body = n.Body
 		name = functionName(n)
+		if name == "init" && v.inits > 1 {
+			// A file may have several init functions, which are
+			// numbered like the compiler numbers those of a
+			// package; unlike it, we number those of each file, as
+			// files are converted independently.
+			name = fmt.Sprintf("init.%d", v.initsVisited)
+			v.initsVisited++
+		}
+		sig = signature(n.Recv, n.Type)
+		if body != nil {
+			var closures int
+			counter := &closures
+			if name == "init" {
+				// The closures of a file's only init function
+				// are numbered after those of its package-level
+				// declarations, which share its name.
+				counter = &v.initClosures
+			}
+			nameClosures(v.closures, body, name, name+".func", counter)
+		}
 	}
 	if body != nil {
-		start := v.fset.Position(node.Pos())
-		end := v.fset.Position(node.End())
-		if name == "" {
-			name = fmt.Sprintf("@%d:%d", start.Line, start.Column)
-		}
 		fe := &FuncExtent{
-			name: name,
-			extent: extent{
-				startOffset: start.Offset,
-				startLine:   start.Line,
-				startCol:    start.Column,
-				endOffset:   end.Offset,
-				endLine:     end.Line,
-				endCol:      end.Column,
-			},
+			name:      name,
+			parent:    parent,
+			signature: sig,
+			extent:    nodeExtent(v.fset, node),
+		}
+		if name == "" {
+			fe.name = fmt.Sprintf("@%d:%d", fe.startLine, fe.startCol)
 		}
 		v.funcs = append(v.funcs, fe)
-		sv := StmtVisitor{fset: v.fset, function: fe}
+		sv := StmtVisitor{fset: v.fset, function: fe, classify: v.classify, deferred: deferred, spawned: spawned}
 		sv.VisitStmt(body)
 	}
 	return v
@@ -267,35 +1002,87 @@ func (v *FuncVisitor) Visit(node ast.Node) ast.Visitor {
 type StmtVisitor struct {
 	fset     *token.FileSet
 	function *FuncExtent
+	classify Classifier
+
+	// deferred and spawned are set if the function is a literal called
+	// by a defer or go statement respectively.
+	deferred bool
+	spawned  bool
+
+	// stack holds the statement being visited, preceded by the
+	// statements enclosing it.
+	stack []ast.Stmt
 }
 
 func (v *StmtVisitor) collectExpr(node ast.Node) {
-	start, end := v.fset.Position(node.Pos()), v.fset.Position(node.End())
-	se := &StmtExtent{
-		startOffset: start.Offset,
-		startLine:   start.Line,
-		startCol:    start.Column,
-		endOffset:   end.Offset,
-		endLine:     end.Line,
-		endCol:      end.Column,
-	}
-	v.function.stmts = append(v.function.stmts, se)
+	v.collectRange(node.Pos(), node.End())
 }
 
 func (v *StmtVisitor) collectToken(pos token.Pos, statement string) {
-	start, end := v.fset.Position(pos), v.fset.Position(pos+token.Pos(len(statement)))
+	v.collectRange(pos, pos+token.Pos(len(statement)))
+}
+
+// collectRange collects a statement covering the source from pos to end,
+// of the kind of the statement being visited.
+func (v *StmtVisitor) collectRange(pos, end token.Pos) {
+	startPos, endPos := v.fset.Position(pos), v.fset.Position(end)
 	se := &StmtExtent{
-		startOffset: start.Offset,
-		startLine:   start.Line,
-		startCol:    start.Column,
-		endOffset:   end.Offset,
-		endLine:     end.Line,
-		endCol:      end.Column,
+		extent: extent{
+			startOffset: startPos.Offset,
+			startLine:   startPos.Line,
+			startCol:    startPos.Column,
+			endOffset:   endPos.Offset,
+			endLine:     endPos.Line,
+			endCol:      endPos.Column,
+		},
+		kind: stmtKind(v.stack[len(v.stack)-1]),
+	}
+	v.collect(se)
+}
+
+// stmtKind returns the kind recorded for statements collected from s.
+func stmtKind(s ast.Stmt) string {
+	switch s := s.(type) {
+	case *ast.DeclStmt:
+		return "decl"
+	case *ast.ExprStmt:
+		if _, ok := s.X.(*ast.CallExpr); ok {
+			return "call"
+		}
+		return "expr"
+	case *ast.SendStmt:
+		return "send"
+	case *ast.IncDecStmt:
+		return "incdec"
+	case *ast.AssignStmt:
+		return "assign"
+	case *ast.GoStmt:
+		return "go"
+	case *ast.DeferStmt:
+		return "defer"
+	case *ast.ReturnStmt:
+		return "return"
+	case *ast.BranchStmt:
+		return "branch"
+	case *ast.IfStmt:
+		return "if"
+	case *ast.SwitchStmt:
+		return "switch"
+	case *ast.TypeSwitchStmt:
+		return "typeswitch"
+	case *ast.SelectStmt:
+		return "select"
+	case *ast.ForStmt:
+		return "for"
+	case *ast.RangeStmt:
+		return "range"
 	}
-	v.function.stmts = append(v.function.stmts, se)
+	return ""
 }
 
 func (v *StmtVisitor) VisitStmt(s ast.Stmt) {
+	v.stack = append(v.stack, s)
+	defer func() { v.stack = v.stack[:len(v.stack)-1] }()
 	switch s := s.(type) {
 
 	case *ast.DeclStmt:
@@ -371,16 +1158,7 @@ func (v *StmtVisitor) VisitStmt(s ast.Stmt) {
 		} else if s.Assign != nil {
 			v.VisitStmt(s.Assign)
 		} else {
-			start, end := v.fset.Position(s.Switch), v.fset.Position(s.Switch+token.Pos(len("switch")))
-			se := &StmtExtent{
-				startOffset: start.Offset,
-				startLine:   start.Line,
-				startCol:    start.Column,
-				endOffset:   end.Offset,
-				endLine:     end.Line,
-				endCol:      end.Column,
-			}
-			v.function.stmts = append(v.function.stmts, se)
+			v.collectToken(s.Switch, "switch")
 		}
 		v.VisitStmt(s.Body)
 	case *ast.CommClause:
@@ -388,31 +1166,13 @@ func (v *StmtVisitor) VisitStmt(s ast.Stmt) {
 			v.VisitStmt(stmt)
 		}
 	case *ast.SelectStmt:
-		start, end := v.fset.Position(s.Select), v.fset.Position(s.Select+token.Pos(len("select")))
-		se := &StmtExtent{
-			startOffset: start.Offset,
-			startLine:   start.Line,
-			startCol:    start.Column,
-			endOffset:   end.Offset,
-			endLine:     end.Line,
-			endCol:      end.Column,
-		}
-		v.function.stmts = append(v.function.stmts, se)
+		v.collectToken(s.Select, "select")
 		v.VisitStmt(s.Body)
 	case *ast.ForStmt:
 		if s.Init != nil {
 			v.VisitStmt(s.Init)
 		} else if s.Cond != nil {
-			start, end := v.fset.Position(s.Cond.Pos()), v.fset.Position(s.Cond.End())
-			se := &StmtExtent{
-				startOffset: start.Offset,
-				startLine:   start.Line,
-				startCol:    start.Column,
-				endOffset:   end.Offset,
-				endLine:     end.Line,
-				endCol:      end.Column,
-			}
-			v.function.stmts = append(v.function.stmts, se)
+			v.collectExpr(s.Cond)
 		} else if s.Post != nil {
 			v.VisitStmt(s.Post)
 		} else {
@@ -420,16 +1180,7 @@ func (v *StmtVisitor) VisitStmt(s ast.Stmt) {
 		}
 		v.VisitStmt(s.Body)
 	case *ast.RangeStmt:
-		start, end := v.fset.Position(s.X.Pos()), v.fset.Position(s.X.End())
-		se := &StmtExtent{
-			startOffset: start.Offset,
-			startLine:   start.Line,
-			startCol:    start.Column,
-			endOffset:   end.Offset,
-			endLine:     end.Line,
-			endCol:      end.Column,
-		}
-		v.function.stmts = append(v.function.stmts, se)
+		v.collectExpr(s.X)
 		v.VisitStmt(s.Body)
 	}
 }
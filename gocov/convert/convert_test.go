@@ -1,12 +1,23 @@
 package convert
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
 
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/cover"
 )
 
 func TestExprName(t *testing.T) {
@@ -38,3 +49,1321 @@ func (x *Foo[T]) GenericMethod() {}
 	assert.Equal(t, "Foo[T].GenericMethod", functionName(function3))
 
 }
+
+func TestMatchPattern(t *testing.T) {
+	match := MatchPattern("example.com/foo/...")
+	assert.True(t, match("example.com/foo"))
+	assert.True(t, match("example.com/foo/bar"))
+	assert.False(t, match("example.com/foobar"))
+
+	match = MatchPattern("example.com/.../internal")
+	assert.True(t, match("example.com/foo/internal"))
+	assert.False(t, match("example.com/foo/internal/bar"))
+
+	match = MatchPattern("fmt")
+	assert.True(t, match("fmt"))
+	assert.False(t, match("fmt/internal"))
+}
+
+func TestSplitProfileFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    PathStyle
+		pkgpath  string
+		filename string
+	}{
+		{"example.com/foo/bar.go", PathStyleAuto, "example.com/foo", "bar.go"},
+		{"bar.go", PathStyleAuto, ".", "bar.go"},
+		{`C:\src\foo\bar.go`, PathStyleAuto, "C:/src/foo", "bar.go"},
+		{"C:/src/foo/bar.go", PathStyleAuto, "C:/src/foo", "bar.go"},
+		{`example.com\foo\bar.go`, PathStyleAuto, "example.com/foo", "bar.go"},
+		{`example.com/foo\bar.go`, PathStyleSlash, "example.com", `foo\bar.go`},
+		{`foo\bar.go`, PathStyleWindows, "foo", "bar.go"},
+	}
+	for _, test := range tests {
+		pkgpath, filename := splitProfileFileName(test.name, test.style)
+		assert.Equal(t, test.pkgpath, pkgpath, test.name)
+		assert.Equal(t, test.filename, filename, test.name)
+	}
+}
+
+func TestOrphanFunction(t *testing.T) {
+	source := `package foo
+
+var x = 1
+
+func Function() {
+	x++
+}
+`
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, "foo.go", source, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	visitor := &FuncVisitor{fset: fset}
+	ast.Walk(visitor, parsed)
+	file := fset.File(parsed.Pos())
+
+	blocks := []cover.ProfileBlock{
+		{StartLine: 3, StartCol: 1, EndLine: 3, EndCol: 10, NumStmt: 1, Count: 2},
+		{StartLine: 5, StartCol: 17, EndLine: 7, EndCol: 2, NumStmt: 1, Count: 1},
+		{StartLine: 9, StartCol: 1, EndLine: 9, EndCol: 99, NumStmt: 1, Count: 0},
+	}
+	f := orphanFunction(blocks, visitor.funcs, file, "foo.go")
+	if assert.NotNil(t, f) {
+		assert.Equal(t, "@file", f.Name)
+		assert.Len(t, f.Statements, 2)
+		assert.Equal(t, 13, f.Statements[0].Start)
+		assert.Equal(t, int64(2), f.Statements[0].Reached)
+		assert.Equal(t, file.Size(), f.Statements[1].Start)
+	}
+
+	assert.Nil(t, orphanFunction(blocks[1:2], visitor.funcs, file, "foo.go"))
+}
+
+func TestClosureNames(t *testing.T) {
+	source := `package foo
+
+var f = func() {}
+
+func Function() {
+	go func() {
+		defer func() {}()
+	}()
+	func() {}()
+}
+
+func (t *T) Method() {
+	_ = func() {}
+}
+`
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, "foo.go", source, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	visitor := &FuncVisitor{fset: fset}
+	ast.Walk(visitor, parsed)
+	var names []string
+	for _, fe := range visitor.funcs {
+		names = append(names, fe.name)
+	}
+	assert.Equal(t, []string{
+		"init.func1",
+		"Function", "Function.func1", "Function.func1.1", "Function.func2",
+		"T.Method", "T.Method.func1",
+	}, names)
+	assert.Equal(t, "", visitor.funcs[0].parent)
+	assert.Equal(t, "Function", visitor.funcs[2].parent)
+	assert.Equal(t, "Function.func1", visitor.funcs[3].parent)
+	assert.Equal(t, "T.Method", visitor.funcs[6].parent)
+}
+
+func TestConverterWithFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"src/foo/foo.go": {Data: []byte("package foo\n\nfunc Function() {\n\tprintln()\n}\n")},
+	}
+	c := NewConverter(WithFS(fsys))
+	profile := &cover.Profile{
+		FileName: "example.com/foo/foo.go",
+		Blocks: []cover.ProfileBlock{
+			{StartLine: 3, StartCol: 17, EndLine: 5, EndCol: 2, NumStmt: 1, Count: 3},
+		},
+	}
+	functions, err := c.convertFile(profile, "/src/foo/foo.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, functions, 1) {
+		assert.Equal(t, "Function", functions[0].Name)
+		assert.Equal(t, "/src/foo/foo.go", functions[0].File)
+		assert.Equal(t, gocovutil.FunctionID("example.com/foo", "foo.go", "Function", "()()"), functions[0].ID)
+		if assert.Len(t, functions[0].Statements, 1) {
+			assert.Equal(t, int64(3), functions[0].Statements[0].Reached)
+		}
+	}
+}
+
+func TestSignature(t *testing.T) {
+	src := []byte(`package foo
+
+func (t *T) Method(a, b int, _ string) (n int, err error) {
+	f := func(x []byte) {}
+	return 0, nil
+}
+`)
+	extents, _, err := findFuncs("foo.go", src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var signatures []string
+	for _, fe := range extents {
+		signatures = append(signatures, fe.signature)
+	}
+	assert.Equal(t, []string{"(*T)(int, int, string)(int, error)", "([]byte)()"}, signatures)
+}
+
+func TestConverterWithMatchHandler(t *testing.T) {
+	fsys := fstest.MapFS{
+		"src/foo/foo.go": {Data: []byte("package foo\n\nfunc Function() {\n\tprintln()\n}\n")},
+	}
+	var matches []BlockMatch
+	c := NewConverter(WithFS(fsys), WithMatchHandler(func(m BlockMatch) { matches = append(matches, m) }))
+	profile := &cover.Profile{
+		FileName: "example.com/foo/foo.go",
+		Blocks: []cover.ProfileBlock{
+			{StartLine: 3, StartCol: 17, EndLine: 5, EndCol: 2, NumStmt: 1, Count: 3},
+			{StartLine: 7, StartCol: 1, EndLine: 8, EndCol: 2, NumStmt: 1, Count: 1},
+		},
+	}
+	if _, err := c.convertFile(profile, "/src/foo/foo.go"); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []BlockMatch{
+		{
+			File:       "/src/foo/foo.go",
+			Block:      gocov.Block{StartLine: 3, StartCol: 17, EndLine: 5, EndCol: 2, NumStmt: 1, Count: 3},
+			Statements: []MatchedStatement{{Function: "Function", StartLine: 4, StartCol: 2, EndLine: 4, EndCol: 11}},
+		},
+		{
+			File:  "/src/foo/foo.go",
+			Block: gocov.Block{StartLine: 7, StartCol: 1, EndLine: 8, EndCol: 2, NumStmt: 1, Count: 1},
+		},
+	}, matches)
+}
+
+func TestConverterWithMismatchHandler(t *testing.T) {
+	fsys := fstest.MapFS{
+		"src/foo/foo.go": {Data: []byte("package foo\n\nfunc Function() {\n\tprintln()\n}\n\nfunc Other() {\n\tprintln()\n}\n")},
+	}
+	var mismatches []Mismatch
+	c := NewConverter(WithFS(fsys), WithMismatchHandler(func(m Mismatch) { mismatches = append(mismatches, m) }))
+	profile := &cover.Profile{
+		FileName: "example.com/foo/foo.go",
+		Blocks: []cover.ProfileBlock{
+			{StartLine: 3, StartCol: 17, EndLine: 5, EndCol: 2, NumStmt: 1, Count: 3},
+			{StartLine: 11, StartCol: 1, EndLine: 12, EndCol: 2, NumStmt: 1, Count: 1},
+			{StartLine: 12, StartCol: 2, EndLine: 12, EndCol: 2, NumStmt: 0, Count: 1},
+		},
+	}
+	if _, err := c.convertFile(profile, "/src/foo/foo.go"); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Mismatch{{File: "/src/foo/foo.go", OrphanedBlocks: 1, UnmatchedStatements: 1}}, mismatches)
+
+	mismatches = nil
+	if _, err := c.convertFile(&cover.Profile{Blocks: profile.Blocks[:1]}, "/src/foo/foo.go"); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Mismatch{{File: "/src/foo/foo.go", UnmatchedStatements: 1}}, mismatches)
+}
+
+func TestMockGenerator(t *testing.T) {
+	tests := []struct {
+		src  string
+		mock string
+	}{
+		{"// Code generated by MockGen. DO NOT EDIT.\n// Source: foo.go\n\npackage foo\n", "gomock"},
+		{"// Code generated by moq; DO NOT EDIT.\n// github.com/matryer/moq\n\npackage foo\n", "moq"},
+		{"// Code generated by mockery v2.20.0. DO NOT EDIT.\n\npackage mocks\n", "mockery"},
+		{"// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage foo\n", ""},
+		{"package foo\n\n// Code generated by MockGen. DO NOT EDIT.\n", ""},
+		{"package foo\n", ""},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.mock, mockGenerator([]byte(test.src)), test.src)
+	}
+}
+
+func TestConverterMocks(t *testing.T) {
+	src := []byte("// Code generated by MockGen. DO NOT EDIT.\n\npackage foo\n\nfunc Function() {\n\tprintln()\n}\n")
+	fsys := fstest.MapFS{"src/foo/mock.go": {Data: src}}
+	profile := &cover.Profile{
+		FileName: "example.com/foo/mock.go",
+		Blocks: []cover.ProfileBlock{
+			{StartLine: 5, StartCol: 17, EndLine: 7, EndCol: 2, NumStmt: 1, Count: 1},
+		},
+	}
+	var exclusions []Exclusion
+	c := NewConverter(WithFS(fsys), WithExclusionHandler(func(e Exclusion) { exclusions = append(exclusions, e) }))
+	functions, err := c.convertFile(profile, "/src/foo/mock.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, functions)
+	assert.Equal(t, []Exclusion{{Package: "example.com/foo", File: "mock.go", Rule: "mock generated by gomock"}}, exclusions)
+
+	functions, err = NewConverter(WithFS(fsys), WithMocks()).convertFile(profile, "/src/foo/mock.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, functions, 1) && assert.Len(t, functions[0].Statements, 1) {
+		assert.Equal(t, []string{MockTag}, functions[0].Statements[0].Tags)
+		assert.Equal(t, int64(1), functions[0].Statements[0].Reached)
+	}
+}
+
+func TestConverterInitializers(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), "c.out")
+	data := "mode: set\nexample.com/foo/foo.go:10.13,12.2 1 1\nexample.com/foo/foo.go:14.13,16.2 1 0\n"
+	if err := ioutil.WriteFile(profile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := `package foo
+
+var (
+	x = compute()
+	y int
+)
+
+var f = func() {}
+
+func init() {
+	x++
+}
+
+func init() {
+	go func() {}()
+}
+`
+	options := []Option{
+		WithFS(fstest.MapFS{"src/foo/foo.go": {Data: []byte(src)}}),
+		WithResolver(StaticResolver{"example.com/foo": {"/src/foo/foo.go"}}),
+	}
+	names := func(ps gocovutil.Packages) []string {
+		var names []string
+		for _, fn := range ps[0].Functions {
+			names = append(names, fn.Name)
+		}
+		return names
+	}
+
+	ps, err := NewConverter(options...).Packages(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"init.func1", "init.0", "init.1", "init.1.func1"}, names(ps))
+
+	ps, err = NewConverter(append(options, WithInitializers())...).Packages(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, []string{"init.func1", "init.0", "init.1", "init.1.func1", "init$vars"}, names(ps)) {
+		initializers := ps[0].Functions[4].Statements
+		if assert.Len(t, initializers, 2) {
+			assert.Equal(t, "x = compute()", src[initializers[0].Start:initializers[0].End])
+			assert.Equal(t, "f = func() {}", src[initializers[1].Start:initializers[1].End])
+			assert.Equal(t, int64(1), initializers[0].Reached)
+			assert.Equal(t, "decl", initializers[1].Kind)
+		}
+	}
+
+	// A file's only init function is not numbered, and its closures
+	// are numbered after those of package-level declarations.
+	src = `package foo
+
+var f = func() {}
+
+func init() {
+	go func() {}()
+}
+`
+	options[0] = WithFS(fstest.MapFS{"src/foo/foo.go": {Data: []byte(src)}})
+	ps, err = NewConverter(options...).Packages(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"init.func1", "init", "init.func2"}, names(ps))
+}
+
+func TestConverterWithResolver(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), "c.out")
+	data := "mode: set\nexample.com/foo/foo.go:3.17,5.2 1 1\n"
+	if err := ioutil.WriteFile(profile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := NewConverter(
+		WithFS(fstest.MapFS{
+			"src/foo/foo.go": {Data: []byte("package foo\n\nfunc Function() {\n\tprintln()\n}\n")},
+		}),
+		WithResolver(StaticResolver{
+			"example.com/foo": {"/src/foo/foo.go"},
+		}),
+	)
+	ps, err := c.Packages(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, ps, 1) {
+		assert.Equal(t, "example.com/foo", ps[0].Name)
+		assert.Len(t, ps[0].Functions, 1)
+	}
+
+	c = NewConverter(WithResolver(StaticResolver{}), WithDir("/src"))
+	_, err = c.Packages(profile)
+	var unresolved *UnresolvedPackageError
+	if assert.True(t, errors.As(err, &unresolved)) {
+		assert.Equal(t, "example.com/foo", unresolved.ImportPath)
+		assert.Equal(t, "example.com/foo/foo.go", unresolved.File)
+		assert.Equal(t, "/src", unresolved.Dir)
+		assert.Contains(t, err.Error(), "package example.com/foo not found")
+	}
+}
+
+func TestConverterLoadErrors(t *testing.T) {
+	// Build constraints exclude the only file of package b, as when a
+	// profile of another platform is converted.
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.12\n",
+		"a/a.go": "package a\n\nfunc A() {\n\tprintln()\n}\n",
+		"b/b.go": "//go:build ignore\n// +build ignore\n\npackage b\n\nfunc B() {\n\tprintln()\n}\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	profile := filepath.Join(dir, "c.out")
+	data := "mode: set\nexample.com/m/a/a.go:3.10,5.2 1 1\nexample.com/m/b/b.go:6.10,8.2 1 1\nexample.com/m/b/gone.go:3.10,5.2 1 1\n"
+	if err := ioutil.WriteFile(profile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var loadErrors []LoadError
+	var exclusions []Exclusion
+	c := NewConverter(
+		WithDir(dir),
+		WithLoadErrorHandler(func(e LoadError) { loadErrors = append(loadErrors, e) }),
+		WithExclusionHandler(func(e Exclusion) { exclusions = append(exclusions, e) }),
+	)
+	ps, err := c.Packages(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, ps, 2) {
+		assert.Equal(t, "example.com/m/b", ps[1].Name)
+		if assert.Len(t, ps[1].Functions, 1) {
+			assert.Equal(t, "B", ps[1].Functions[0].Name)
+			assert.Equal(t, int64(1), ps[1].Functions[0].Statements[0].Reached)
+		}
+	}
+	if assert.Len(t, loadErrors, 1) {
+		assert.Equal(t, "example.com/m/b", loadErrors[0].Package)
+		assert.NotEmpty(t, loadErrors[0].Errors)
+	}
+	if assert.Len(t, exclusions, 1) {
+		assert.Equal(t, "gone.go", exclusions[0].File)
+		assert.Contains(t, exclusions[0].Rule, "package failed to load")
+	}
+
+	// Packages the go tool cannot find at all fail the conversion.
+	data = "mode: set\nexample.com/m/c/c.go:3.10,5.2 1 1\n"
+	if err := ioutil.WriteFile(profile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewConverter(WithDir(dir)).Packages(profile)
+	var unresolved *UnresolvedPackageError
+	if assert.True(t, errors.As(err, &unresolved)) {
+		assert.Equal(t, "example.com/m/c", unresolved.ImportPath)
+	}
+}
+
+// writeMmapSource writes a source file of package a with n functions, and
+// a profile covering each, for converting with and without WithMmap.
+func writeMmapSource(tb testing.TB, n int) (Resolver, string) {
+	dir := tb.TempDir()
+	var src, profile bytes.Buffer
+	src.WriteString("package a\n")
+	profile.WriteString("mode: count\n")
+	for i := 0; i < n; i++ {
+		line := 3 + 7*i
+		fmt.Fprintf(&src, "\nfunc F%d(x int) int {\n\tif x > %d {\n\t\treturn x\n\t}\n\treturn 0\n}\n", i, i)
+		fmt.Fprintf(&profile, "example.com/m/a/a.go:%d.1,%d.2 2 %d\n", line, line+5, i%3)
+	}
+	filename := filepath.Join(dir, "a.go")
+	if err := ioutil.WriteFile(filename, src.Bytes(), 0644); err != nil {
+		tb.Fatal(err)
+	}
+	profileName := filepath.Join(dir, "c.out")
+	if err := ioutil.WriteFile(profileName, profile.Bytes(), 0644); err != nil {
+		tb.Fatal(err)
+	}
+	return StaticResolver{"example.com/m/a": {filename}}, profileName
+}
+
+func TestConverterMmap(t *testing.T) {
+	resolver, profile := writeMmapSource(t, 50)
+	read, err := NewConverter(WithResolver(resolver)).Packages(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapped, err := NewConverter(WithResolver(resolver), WithMmap()).Packages(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, read, 1) {
+		assert.Len(t, read[0].Functions, 50)
+	}
+	assert.Equal(t, read, mapped)
+}
+
+func BenchmarkConverterMmap(b *testing.B) {
+	resolver, profile := writeMmapSource(b, 5000)
+	for _, bench := range []struct {
+		name string
+		opts []Option
+	}{
+		{"read", nil},
+		{"mmap", []Option{WithMmap()}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := NewConverter(append(bench.opts, WithResolver(resolver))...).Packages(profile); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestConverterBuildFlags(t *testing.T) {
+	// The profile was gathered on windows with the integration tag, which
+	// select files of package a that are not built by default.
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":             "module example.com/m\n\ngo 1.12\n",
+		"a/a.go":             "package a\n\nfunc A() {\n\tprintln()\n}\n",
+		"a/a_integration.go": "//go:build integration\n// +build integration\n\npackage a\n\nfunc I() {\n\tprintln()\n}\n",
+		"a/a_windows.go":     "package a\n\nfunc W() {\n\tprintln()\n}\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	profile := filepath.Join(dir, "c.out")
+	data := "mode: set\nexample.com/m/a/a.go:3.10,5.2 1 1\nexample.com/m/a/a_integration.go:6.10,8.2 1 1\nexample.com/m/a/a_windows.go:3.10,5.2 1 1\n"
+	if err := ioutil.WriteFile(profile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	functions := func(opts ...Option) []string {
+		ps, err := NewConverter(append(opts, WithDir(dir))...).Packages(profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, p := range ps {
+			for _, fn := range p.Functions {
+				names = append(names, fn.Name)
+			}
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"A"}, functions(WithEnv("GOOS=linux", "GOARCH=amd64")))
+	assert.Equal(t, []string{"A", "I"}, functions(WithBuildFlags("-tags=integration"), WithEnv("GOOS=linux", "GOARCH=amd64")))
+	assert.Equal(t, []string{"A", "I", "W"}, functions(WithBuildFlags("-tags=integration"), WithEnv("GOOS=windows", "GOARCH=amd64")))
+}
+
+func TestConverterTypesImported(t *testing.T) {
+	// Interfaces of imported packages are found in the type information
+	// loaded by the go tool, as well as those of the package itself.
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.12\n",
+		"p/p.go": "package p\n\nimport \"io\"\n\ntype R struct{}\n\nfunc (R) Read(p []byte) (int, error) {\n\treturn 0, io.EOF\n}\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	profile := filepath.Join(dir, "c.out")
+	if err := ioutil.WriteFile(profile, []byte("mode: set\nexample.com/m/p/p.go:7.39,9.2 1 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ps, err := NewConverter(WithDir(dir), WithTypes()).Packages(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, ps, 1) && assert.Len(t, ps[0].Functions, 1) {
+		assert.Equal(t, []string{"io.Reader"}, ps[0].Functions[0].Implements)
+	}
+}
+
+func TestConverterSkipPackageMarker(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), "c.out")
+	data := "mode: set\nexample.com/foo/foo.go:3.17,5.2 1 1\nexample.com/bar/bar.go:3.17,5.2 1 1\n"
+	if err := ioutil.WriteFile(profile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var exclusions []Exclusion
+	c := NewConverter(
+		WithFS(fstest.MapFS{
+			"src/foo/doc.go": {Data: []byte("// Package foo is experimental.\n//gocov:skip-package\npackage foo\n")},
+			"src/foo/foo.go": {Data: []byte("package foo\n\nfunc Function() {\n\tprintln()\n}\n")},
+			"src/bar/bar.go": {Data: []byte("package bar\n\nfunc Function() {\n\tprintln()\n}\n//gocov:skip-package\n")},
+		}),
+		WithResolver(StaticResolver{
+			"example.com/foo": {"/src/foo/doc.go", "/src/foo/foo.go"},
+			"example.com/bar": {"/src/bar/bar.go"},
+		}),
+		WithExclusionHandler(func(e Exclusion) { exclusions = append(exclusions, e) }),
+	)
+	ps, err := c.Packages(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, ps, 1) {
+		assert.Equal(t, "example.com/bar", ps[0].Name)
+	}
+	assert.Equal(t, []Exclusion{
+		{Package: "example.com/foo", File: "foo.go", Rule: "package marked //gocov:skip-package in doc.go"},
+	}, exclusions)
+}
+
+func TestInShard(t *testing.T) {
+	for _, pkgpath := range []string{"example.com/a", "example.com/b", "fmt"} {
+		shards := 0
+		for i := 0; i < 3; i++ {
+			if inShard(pkgpath, i, 3) {
+				shards++
+			}
+		}
+		assert.Equal(t, 1, shards, pkgpath)
+	}
+}
+
+func TestConverterWithCache(t *testing.T) {
+	src := []byte("package foo\n\nfunc Function() {\n\tprintln()\n}\n")
+	dir := t.TempDir()
+	c := NewConverter(WithCache(dir))
+	extents, _, err := c.findFuncs("foo.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, entries, 1)
+
+	cached, file, err := c.findFuncs("foo.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, extents, cached)
+	assert.Equal(t, 5, file.LineCount())
+}
+
+func TestWriteProfile(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), "c.out")
+	data := "mode: count\nexample.com/foo/foo.go:3.17,5.2 1 4\n"
+	if err := ioutil.WriteFile(profile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := NewConverter(
+		WithBlocks(),
+		WithFS(fstest.MapFS{
+			"src/foo/foo.go": {Data: []byte("package foo\n\nfunc Function() {\n\tprintln()\n}\n")},
+		}),
+		WithResolver(StaticResolver{
+			"example.com/foo": {"/src/foo/foo.go"},
+		}),
+	)
+	ps, err := c.Packages(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteProfile(&buf, ps); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, data, buf.String())
+
+	assert.Error(t, WriteProfile(&buf, nil))
+}
+
+func TestConverterExclusions(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), "c.out")
+	data := "mode: set\n" +
+		"example.com/foo/foo.go:3.17,5.2 1 1\n" +
+		"example.com/foo/gen.go:3.17,5.2 1 1\n" +
+		"example.com/foo/missing.go:3.17,5.2 1 1\n" +
+		"example.com/bar/bar.go:3.17,5.2 1 1\n"
+	if err := ioutil.WriteFile(profile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Profiles are sorted by file name, and missing sources are only
+	// found after filtering.
+	src := []byte("package foo\n\nfunc Function() {\n\tprintln()\n}\n")
+	var exclusions []Exclusion
+	c := NewConverter(
+		WithPackages("example.com/foo"),
+		WithExcludes("example.com/foo/gen.go"),
+		WithFS(fstest.MapFS{"src/foo/foo.go": {Data: src}}),
+		WithResolver(StaticResolver{"example.com/foo": {"/src/foo/foo.go"}}),
+		WithExclusionHandler(func(e Exclusion) { exclusions = append(exclusions, e) }),
+	)
+	if _, err := c.Packages(profile); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []Exclusion{
+		{Package: "example.com/bar", File: "bar.go", Rule: "not matched by package patterns example.com/foo"},
+		{Package: "example.com/foo", File: "gen.go", Rule: "matched exclude pattern example.com/foo/gen.go"},
+		{Package: "example.com/foo", File: "missing.go", Rule: "source file not found"},
+	}, exclusions)
+}
+
+func TestConverterMultipleProfiles(t *testing.T) {
+	dir := t.TempDir()
+	var profiles []string
+	for i, count := range []int{1, 2, 0, 4} {
+		profile := filepath.Join(dir, fmt.Sprintf("c%d.out", i))
+		data := fmt.Sprintf("mode: count\nexample.com/foo/foo.go:3.17,5.2 1 %d\n", count)
+		if err := ioutil.WriteFile(profile, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		profiles = append(profiles, profile)
+	}
+	c := NewConverter(
+		WithConcurrency(2),
+		WithFS(fstest.MapFS{
+			"src/foo/foo.go": {Data: []byte("package foo\n\nfunc Function() {\n\tprintln()\n}\n")},
+		}),
+		WithResolver(StaticResolver{
+			"example.com/foo": {"/src/foo/foo.go"},
+		}),
+	)
+	ps, err := c.Packages(profiles...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, ps, 1) && assert.Len(t, ps[0].Functions, 1) {
+		assert.Equal(t, int64(7), ps[0].Functions[0].Statements[0].Reached)
+	}
+
+	_, err = c.Packages(append(profiles, filepath.Join(dir, "missing.out"))...)
+	assert.Error(t, err)
+}
+
+func TestConverterDuplicateProfiles(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte("mode: count\nexample.com/foo/foo.go:3.17,5.2 1 3\n")
+	for _, name := range []string{"a.out", "b.out"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := NewConverter(
+		WithFS(fstest.MapFS{
+			"src/foo/foo.go": {Data: []byte("package foo\n\nfunc Function() {\n\tprintln()\n}\n")},
+		}),
+		WithResolver(StaticResolver{
+			"example.com/foo": {"/src/foo/foo.go"},
+		}),
+	)
+	a, b := filepath.Join(dir, "a.out"), filepath.Join(dir, "b.out")
+	ps, err := c.Packages(a, b, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, ps, 1) && assert.Len(t, ps[0].Functions, 1) {
+		assert.Equal(t, int64(3), ps[0].Functions[0].Statements[0].Reached)
+	}
+}
+
+func TestConverterInputFormats(t *testing.T) {
+	dir := t.TempDir()
+	inputs := map[string]string{
+		"a.out":   "mode: count\nexample.com/foo/foo.go:3.17,5.2 1 3\n",
+		"b.info":  "TN:\nSF:example.com/foo/foo.go\nDA:4,2\nend_of_record\nSF:example.com/foo/foo.go\nDA:4,1\nend_of_record\n",
+		"bad.txt": "TN:\nDA:4,1\n",
+	}
+	c := NewConverter(
+		WithFS(fstest.MapFS{
+			"src/foo/foo.go": {Data: []byte("package foo\n\nfunc Function() {\n\tprintln()\n}\n")},
+		}),
+		WithResolver(StaticResolver{
+			"example.com/foo": {"/src/foo/foo.go"},
+		}),
+	)
+	for name, data := range inputs {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	join := func(name string) string { return filepath.Join(dir, name) }
+	converted, err := c.ConvertProfiles(join("a.out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(join("c.json"), converted, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ps, err := c.Packages(join("a.out"), join("b.info"), join("c.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, ps, 1) && assert.Len(t, ps[0].Functions, 1) {
+		assert.Equal(t, int64(9), ps[0].Functions[0].Statements[0].Reached)
+	}
+
+	_, err = c.Packages(join("bad.txt"))
+	assert.Error(t, err)
+}
+
+func TestConverterImport(t *testing.T) {
+	dir := t.TempDir()
+	inputs := map[string]string{
+		"js.info": "SF:/src/web/app.js\nFN:2,f\nFN:5,g\nDA:1,1\nDA:3,4\nDA:6,0\nDA:9,1\nend_of_record\n",
+		"py.xml": `<?xml version="1.0" ?>
+<coverage>
+  <sources><source>/src</source></sources>
+  <packages>
+    <package name="tools">
+      <classes>
+        <class name="lint.py" filename="tools/lint.py">
+          <methods>
+            <method name="main"><lines><line number="2" hits="0"/></lines></method>
+          </methods>
+          <lines><line number="1" hits="1"/><line number="2" hits="0"/></lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`,
+	}
+	for name, data := range inputs {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var mismatches []Mismatch
+	c := NewConverter(
+		WithFS(fstest.MapFS{
+			"src/web/app.js":    {Data: []byte("const a = 1;\nfunction f() {\n  return a;\n}\nfunction g() {\n  return 2;\n}\n")},
+			"src/tools/lint.py": {Data: []byte("import sys\ndef main():\n    pass\n")},
+		}),
+		WithResolver(StaticResolver{}),
+		WithMismatchHandler(func(m Mismatch) { mismatches = append(mismatches, m) }),
+	)
+	ps, err := c.Packages(filepath.Join(dir, "js.info"), filepath.Join(dir, "py.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, ps, 2) {
+		return
+	}
+	assert.Equal(t, "/src/web", ps[0].Name)
+	assert.Equal(t, "javascript", ps[0].Language)
+	if assert.Len(t, ps[0].Functions, 3) {
+		names := []string{ps[0].Functions[0].Name, ps[0].Functions[1].Name, ps[0].Functions[2].Name}
+		assert.Equal(t, []string{"@file", "f", "g"}, names)
+		f := ps[0].Functions[1]
+		assert.Equal(t, 13, f.Start)
+		assert.Equal(t, 41, f.End)
+		if assert.Len(t, f.Statements, 1) {
+			assert.Equal(t, &gocov.Statement{Start: 30, End: 39, Reached: 4}, f.Statements[0])
+		}
+	}
+	assert.Equal(t, []Mismatch{{File: "/src/web/app.js", OrphanedBlocks: 1}}, mismatches)
+
+	assert.Equal(t, "tools", ps[1].Name)
+	assert.Equal(t, "python", ps[1].Language)
+	if assert.Len(t, ps[1].Functions, 2) {
+		assert.Equal(t, "main", ps[1].Functions[1].Name)
+		assert.Len(t, ps[1].Functions[1].Statements, 1)
+		assert.Equal(t, int64(1), ps[1].Functions[0].Statements[0].Reached)
+	}
+
+	// Other XML reports are not mistaken for Cobertura's.
+	jacoco := filepath.Join(dir, "jacoco.xml")
+	if err := ioutil.WriteFile(jacoco, []byte(`<?xml version="1.0"?><report name="app"><package name="app"/></report>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Packages(jacoco)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "<coverage>")
+	}
+}
+
+func TestConverterImportEmptyFile(t *testing.T) {
+	dir := t.TempDir()
+	inputs := map[string]string{
+		"py.info": "SF:/src/pkg/__init__.py\nDA:1,1\nend_of_record\n",
+		"py.xml": `<?xml version="1.0" ?>
+<coverage>
+  <sources><source>/src</source></sources>
+  <packages>
+    <package name="pkg">
+      <classes>
+        <class name="__init__.py" filename="pkg/__init__.py">
+          <lines><line number="1" hits="1"/></lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`,
+	}
+	for name, data := range inputs {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var mismatches []Mismatch
+		c := NewConverter(
+			WithFS(fstest.MapFS{"src/pkg/__init__.py": {Data: nil}}),
+			WithResolver(StaticResolver{}),
+			WithMismatchHandler(func(m Mismatch) { mismatches = append(mismatches, m) }),
+		)
+		if _, err := c.Packages(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		assert.Equal(t, []Mismatch{{File: "/src/pkg/__init__.py", OrphanedBlocks: 1}}, mismatches, name)
+	}
+}
+
+func TestConverterIstanbul(t *testing.T) {
+	dir := t.TempDir()
+	// The statement of g starts after "é", one UTF-16 code unit but two
+	// bytes.
+	src := "var s = \"é\"; function f() {\n  return function g() { return 1; };\n}\n"
+	report := `{"/src/web/app.js": {
+		"path": "/src/web/app.js",
+		"statementMap": {
+			"0": {"start": {"line": 1, "column": 0}, "end": {"line": 1, "column": 12}},
+			"1": {"start": {"line": 2, "column": 2}, "end": {"line": 2, "column": 36}},
+			"2": {"start": {"line": 2, "column": 24}, "end": {"line": 2, "column": 33}},
+			"10": {"start": {"line": 9, "column": 0}, "end": {"line": 9, "column": 1}}
+		},
+		"fnMap": {
+			"0": {"name": "f", "loc": {"start": {"line": 1, "column": 13}, "end": {"line": 3, "column": 1}}},
+			"1": {"name": "g", "loc": {"start": {"line": 2, "column": 9}, "end": {"line": 2, "column": null}}}
+		},
+		"s": {"0": 1, "1": 2, "2": 0, "10": 1}
+	}}`
+	inputs := map[string]string{
+		"coverage-final.json": report,
+		"v8.json":             `{"result": []}`,
+		"labelled.json":       `{"Labels": {"suite": "e2e"}, "Packages": null}`,
+	}
+	for name, data := range inputs {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var mismatches []Mismatch
+	c := NewConverter(
+		WithFS(fstest.MapFS{"src/web/app.js": {Data: []byte(src)}}),
+		WithResolver(StaticResolver{}),
+		WithMismatchHandler(func(m Mismatch) { mismatches = append(mismatches, m) }),
+	)
+	ps, err := c.Packages(filepath.Join(dir, "coverage-final.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, ps, 1) || !assert.Len(t, ps[0].Functions, 3) {
+		return
+	}
+	assert.Equal(t, "/src/web", ps[0].Name)
+	file, f, g := ps[0].Functions[0], ps[0].Functions[1], ps[0].Functions[2]
+	assert.Equal(t, "@file", file.Name)
+	assert.Equal(t, []*gocov.Statement{{Start: 0, End: 13, Reached: 1}}, file.Statements)
+	assert.Equal(t, "f", f.Name)
+	assert.Equal(t, 14, f.Start)
+	assert.Equal(t, []*gocov.Statement{{Start: 31, End: 65, Reached: 2}}, f.Statements)
+	assert.Equal(t, "g", g.Name)
+	assert.Equal(t, []*gocov.Statement{{Start: 53, End: 62}}, g.Statements)
+	assert.Equal(t, []Mismatch{{File: "/src/web/app.js", OrphanedBlocks: 1}}, mismatches)
+
+	_, err = c.Packages(filepath.Join(dir, "v8.json"))
+	assert.Error(t, err)
+
+	// A gocov JSON document is told apart by its fields, in any order.
+	ps, err = c.Packages(filepath.Join(dir, "labelled.json"))
+	assert.NoError(t, err)
+	assert.Empty(t, ps)
+}
+
+func TestSniffFormat(t *testing.T) {
+	for data, expected := range map[string]inputFormat{
+		"mode: set\n":              formatProfile,
+		"  {\"Packages\":[]}\n":    formatJSON,
+		"TN:\nSF:/src/foo.go\n":    formatLCOV,
+		"SF:/src/foo.go\nDA:1,1\n": formatLCOV,
+		"<?xml version=\"1.0\"?>":  formatCobertura,
+		"":                         formatProfile,
+	} {
+		assert.Equal(t, expected, sniffFormat([]byte(data)), "%q", data)
+	}
+}
+
+func TestExpandProfiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.out", "b.out", "shards/c.out", "shards/notes.txt", "covdata/covmeta.0123"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	join := func(name string) string { return filepath.Join(dir, filepath.FromSlash(name)) }
+
+	profiles, covdata, err := expandProfiles([]string{join("*.out"), join("shards"), join("covdata"), join("missing.out")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{join("a.out"), join("b.out"), join("shards/c.out"), join("missing.out")}, profiles)
+	assert.Equal(t, []string{join("covdata")}, covdata)
+
+	meta := join("gocoverdir/covmeta.0123")
+	if err := os.MkdirAll(filepath.Dir(meta), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(meta, covMetaMagic, 0644); err != nil {
+		t.Fatal(err)
+	}
+	profiles, covdata, err = expandProfiles([]string{meta, join("a.out")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{join("a.out")}, profiles)
+	assert.Equal(t, []string{join("gocoverdir")}, covdata)
+
+	_, _, err = expandProfiles([]string{join("*.json")})
+	assert.Error(t, err)
+	if err := os.Mkdir(join("empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = expandProfiles([]string{join("empty")})
+	assert.Error(t, err)
+}
+
+func TestConverterPathNormalization(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	real := filepath.Join(dir, "real")
+	if err := os.Mkdir(real, 0755); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(real, "foo.go")
+	if err := ioutil.WriteFile(src, []byte("package foo\n\nfunc Function() {\n\tprintln()\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(real, link); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	resolver := StaticResolver{filepath.ToSlash(real): {src}}
+	convert := func(name string, opts ...Option) (gocovutil.Packages, error) {
+		profile := filepath.Join(dir, "c.out")
+		data := fmt.Sprintf("mode: set\n%s:3.17,5.2 1 1\n", filepath.ToSlash(filepath.Join(link, name)))
+		if err := ioutil.WriteFile(profile, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return NewConverter(append(opts, WithResolver(resolver))...).Packages(profile)
+	}
+
+	ps, err := convert("foo.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, ps, 1) && assert.Len(t, ps[0].Functions, 1) {
+		assert.Equal(t, src, ps[0].Functions[0].File)
+	}
+
+	ps, err = convert("FOO.go")
+	if assert.NoError(t, err) {
+		assert.Len(t, ps, 0)
+	}
+	ps, err = convert("FOO.go", WithCaseInsensitivePaths())
+	if assert.NoError(t, err) {
+		assert.Len(t, ps, 1)
+	}
+}
+
+func TestConverterRewrite(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), "c.out")
+	data := "mode: set\n" +
+		"go.example.com/mod/foo/foo.go:3.17,5.2 1 1\n" +
+		"go.example.com/module/bar.go:3.17,5.2 1 1\n"
+	if err := ioutil.WriteFile(profile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := []byte("package foo\n\nfunc Function() {\n\tprintln()\n}\n")
+	var exclusions []Exclusion
+	c := NewConverter(
+		WithRewrite("go.example.com/mod", "github.com/org/mod"),
+		WithFS(fstest.MapFS{"src/foo/foo.go": {Data: src}}),
+		WithResolver(StaticResolver{"github.com/org/mod/foo": {"/src/foo/foo.go"}, "go.example.com/module": nil}),
+		WithExclusionHandler(func(e Exclusion) { exclusions = append(exclusions, e) }),
+	)
+	ps, err := c.Packages(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, ps, 1) {
+		assert.Equal(t, "github.com/org/mod/foo", ps[0].Name)
+	}
+	// Rewrites apply to whole path elements only.
+	assert.Equal(t, []Exclusion{{Package: "go.example.com/module", File: "bar.go", Rule: "source file not found"}}, exclusions)
+}
+
+func TestConverterWithClassifier(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), "c.out")
+	data := "mode: set\ngithub.com/org/foo/foo.go:3.17,7.2 3 1\n"
+	if err := ioutil.WriteFile(profile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := []byte("package foo\n\nfunc Function() {\n\tlog.Print()\n\tx := 1\n\tprintln(x)\n}\n")
+	logOnly := func(info StatementInfo) Classification {
+		if s, ok := info.Stmt.(*ast.ExprStmt); ok {
+			if call, ok := s.X.(*ast.CallExpr); ok {
+				if sel, ok := call.Fun.(*ast.SelectorExpr); ok && exprName(sel.X) == "log" {
+					return Classification{Skip: true}
+				}
+			}
+		}
+		return Classification{}
+	}
+	assignments := func(info StatementInfo) Classification {
+		if _, ok := info.Stmt.(*ast.AssignStmt); ok {
+			return Classification{Tags: []string{"assign"}, Weight: 2}
+		}
+		return Classification{}
+	}
+	c := NewConverter(
+		WithFS(fstest.MapFS{"src/foo/foo.go": {Data: src}}),
+		WithResolver(StaticResolver{"github.com/org/foo": {"/src/foo/foo.go"}}),
+		WithClassifier(logOnly),
+		WithClassifier(assignments),
+	)
+	ps, err := c.Packages(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, ps, 1) || !assert.Len(t, ps[0].Functions, 1) {
+		return
+	}
+	stmts := ps[0].Functions[0].Statements
+	if assert.Len(t, stmts, 2) {
+		assert.Equal(t, []string{"assign"}, stmts[0].Tags)
+		assert.Equal(t, 2.0, stmts[0].Weight)
+		assert.Nil(t, stmts[1].Tags)
+		assert.Equal(t, 0.0, stmts[1].Weight)
+	}
+}
+
+func TestStatementKinds(t *testing.T) {
+	src := []byte(`package foo
+
+func f(ch chan int, xs []int) (err error) {
+	var y int
+	x := 1
+	x++
+	println(x)
+	ch <- y
+	defer recover()
+	go f(ch, xs)
+	if x > 0 {
+		return nil
+	}
+	for range xs {
+		break
+	}
+	switch {
+	}
+	select {
+	}
+	return
+}
+`)
+	extents, _, err := findFuncs("foo.go", src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, se := range extents[0].stmts {
+		kinds = append(kinds, se.kind)
+	}
+	assert.Equal(t, []string{"decl", "assign", "incdec", "call", "send", "defer", "go", "if", "return", "range", "branch", "switch", "select", "return"}, kinds)
+}
+
+func TestErrorPaths(t *testing.T) {
+	src := []byte(`package foo
+
+func f() (int, error) {
+	x, err := g()
+	if err != nil {
+		log(err)
+		return 0, err
+	}
+	if err := h(); err == nil {
+		x++
+	} else {
+		x--
+	}
+	if x < 0 {
+		return 0, fmt.Errorf("negative")
+	}
+	return x, nil
+}
+`)
+	extents, _, err := findFuncs("foo.go", src, ErrorPaths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tagged []int
+	for _, se := range extents[0].stmts {
+		if class, ok := extents[0].classes[se]; ok && assert.Equal(t, []string{ErrorPathTag}, class.Tags) {
+			tagged = append(tagged, se.startLine)
+		}
+	}
+	assert.Equal(t, []int{6, 7, 12, 15}, tagged)
+}
+
+func TestPanicPaths(t *testing.T) {
+	src := []byte(`package foo
+
+func f(x int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	if x < 0 {
+		log(x)
+		panic("negative")
+	}
+	switch x {
+	case 0:
+		return nil
+	default:
+		panic("unreachable")
+	}
+}
+`)
+	extents, _, err := findFuncs("foo.go", src, PanicPaths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tagged []int
+	for _, fe := range extents {
+		for _, se := range fe.stmts {
+			if class, ok := fe.classes[se]; ok && assert.Equal(t, []string{PanicPathTag}, class.Tags) {
+				tagged = append(tagged, se.startLine)
+			}
+		}
+	}
+	assert.Equal(t, []int{10, 11, 17, 5, 6}, tagged)
+}
+
+func TestGoroutines(t *testing.T) {
+	src := []byte(`package foo
+
+func f(ch chan int) {
+	go func() {
+		ch <- 1
+	}()
+	go g(ch)
+	<-ch
+}
+`)
+	extents, _, err := findFuncs("foo.go", src, Goroutines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tagged []int
+	for _, fe := range extents {
+		for _, se := range fe.stmts {
+			if class, ok := fe.classes[se]; ok && assert.Equal(t, []string{GoroutineTag}, class.Tags) {
+				tagged = append(tagged, se.startLine)
+			}
+		}
+	}
+	assert.Equal(t, []int{4, 7, 5}, tagged)
+}
+
+// typesStaticResolver is a StaticResolver that also provides type
+// information.
+type typesStaticResolver struct {
+	StaticResolver
+	types map[string]*types.Package
+}
+
+func (r typesStaticResolver) packageTypes(importPath string) *types.Package {
+	return r.types[importPath]
+}
+
+func TestConverterTypes(t *testing.T) {
+	src := `package p
+
+type Reader interface{ Read([]byte) (int, error) }
+
+type reader struct{}
+
+func (*reader) Read(p []byte) (int, error) { return 0, nil }
+
+type T struct{ reader }
+
+type A = T
+
+func (A) M() {}
+
+func F() {}
+
+func g() {}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpkg, err := (&types.Config{}).Check("example.com/p", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &gocov.Package{Name: "example.com/p", Functions: []*gocov.Function{
+		{Name: "reader.Read"}, {Name: "A.M"}, {Name: "F"}, {Name: "g"}, {Name: "g.func1", Parent: "g"},
+	}}
+	c := NewConverter(WithTypes(), WithResolver(typesStaticResolver{types: map[string]*types.Package{"example.com/p": tpkg}}))
+	c.describeTypes(pkg)
+
+	type description struct {
+		receiver   string
+		exported   bool
+		implements []string
+	}
+	want := []description{
+		{"*example.com/p.reader", true, []string{"example.com/p.Reader"}},
+		{"example.com/p.T", true, nil},
+		{"", true, nil},
+		{"", false, nil},
+	}
+	for i, w := range want {
+		fn := pkg.Functions[i]
+		if assert.NotNil(t, fn.Exported, fn.Name) {
+			assert.Equal(t, w, description{fn.Receiver, *fn.Exported, fn.Implements}, fn.Name)
+		}
+	}
+	assert.Nil(t, pkg.Functions[4].Exported)
+}
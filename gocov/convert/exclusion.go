@@ -0,0 +1,69 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+// Exclusion records source code left out of a conversion, and the rule
+// that excluded it, so that exclusion policies can be audited.
+type Exclusion struct {
+	// Package is the import path of the excluded code.
+	Package string
+
+	// File is the base name of the excluded file, if the exclusion
+	// applies to a single file.
+	File string `json:",omitempty"`
+
+	// Function is the name of the excluded function, if the exclusion
+	// applies to a single function.
+	Function string `json:",omitempty"`
+
+	// Rule describes the rule that excluded the code.
+	Rule string
+}
+
+// WithExclusionHandler calls fn for each profiled file that is left out
+// of the conversion, whether by WithPackages, WithExcludes or WithShard,
+// because its source file cannot be found or because it holds generated
+// mocks.
+func WithExclusionHandler(fn func(Exclusion)) Option {
+	return func(c *Converter) {
+		c.excluded = fn
+	}
+}
+
+// exclude reports the exclusion of a file to the exclusion handler, if any.
+func (c *Converter) exclude(pkgpath, filename, rule string) {
+	if c.excluded != nil {
+		c.handlerMu.Lock()
+		defer c.handlerMu.Unlock()
+		c.excluded(Exclusion{Package: pkgpath, File: filename, Rule: rule})
+	}
+}
+
+// matchingPattern returns the first of the patterns that matches p, or ""
+// if none does.
+func matchingPattern(patterns []string, p string) string {
+	for _, pattern := range patterns {
+		if MatchPattern(pattern)(p) {
+			return pattern
+		}
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/hihoak/gocov"
+)
+
+// initializerName is the name of the synthetic function holding the
+// initializers of a file's package-level variables, which no function
+// declared in Go source can have.
+const initializerName = "init$vars"
+
+// WithInitializers collects the initializer expressions of package-level
+// variables, where significant logic can live, as the statements of a
+// synthetic function named "init$vars" in each file. Coverage profiles
+// record no blocks for them: as package initialization precedes all other
+// code of a package, they are counted as reached once if any code of
+// their package was.
+func WithInitializers() Option {
+	return func(c *Converter) {
+		c.initializers = true
+	}
+}
+
+// initializerExtent returns the extent of a file's synthetic "init$vars"
+// function, holding a statement for each specification of package-level
+// variables with values, or nil if the file has none.
+func initializerExtent(fset *token.FileSet, file *ast.File) *FuncExtent {
+	var fe *FuncExtent
+	for _, decl := range file.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range decl.Specs {
+			spec := spec.(*ast.ValueSpec)
+			if len(spec.Values) == 0 {
+				continue
+			}
+			se := &StmtExtent{extent: nodeExtent(fset, spec), kind: "decl"}
+			if fe == nil {
+				fe = &FuncExtent{name: initializerName, extent: se.extent}
+			}
+			fe.endOffset, fe.endLine, fe.endCol = se.endOffset, se.endLine, se.endCol
+			fe.stmts = append(fe.stmts, se)
+		}
+	}
+	return fe
+}
+
+// withoutInitializers returns extents without the synthetic "init$vars"
+// function, if any.
+func withoutInitializers(extents []*FuncExtent) []*FuncExtent {
+	if n := len(extents); n > 0 && extents[n-1].name == initializerName {
+		return extents[:n-1]
+	}
+	return extents
+}
+
+// markInitializers counts the variable initializers of a package as
+// reached if any other statement of the package was.
+func markInitializers(pkg *gocov.Package) {
+	var initialized bool
+	for _, fn := range pkg.Functions {
+		if fn.Name == initializerName {
+			continue
+		}
+		for _, stmt := range fn.Statements {
+			if stmt.Reached > 0 {
+				initialized = true
+			}
+		}
+	}
+	if !initialized {
+		return
+	}
+	for _, fn := range pkg.Functions {
+		if fn.Name == initializerName {
+			for _, stmt := range fn.Statements {
+				stmt.Reached = 1
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// expandProfiles expands the profile arguments given to the converter:
+// glob patterns are replaced by the files they match, and directories by
+// the "*.out" files found in them recursively. Directories holding binary
+// coverage data, as written to GOCOVERDIR by programs built with
+// "go build -cover", are returned separately, as are the directories of
+// binary coverage data files given directly.
+func expandProfiles(args []string) (profiles, covdata []string, err error) {
+	for _, arg := range args {
+		matches := []string{arg}
+		if _, err := os.Stat(arg); err != nil && strings.ContainsAny(arg, "*?[") {
+			if matches, err = filepath.Glob(arg); err != nil {
+				return nil, nil, err
+			}
+			if len(matches) == 0 {
+				return nil, nil, fmt.Errorf("no profiles match %s", arg)
+			}
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err == nil && !info.IsDir() && isCovdataFile(match) {
+				covdata = appendUnique(covdata, filepath.Dir(match))
+				continue
+			}
+			if err != nil || !info.IsDir() {
+				// Missing files are reported when parsed.
+				profiles = append(profiles, match)
+				continue
+			}
+			n := len(profiles) + len(covdata)
+			err = filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				switch {
+				case d.IsDir():
+					if isCovdataDir(path) {
+						covdata = append(covdata, path)
+					}
+				case strings.HasSuffix(path, ".out"):
+					profiles = append(profiles, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(profiles)+len(covdata) == n {
+				return nil, nil, fmt.Errorf("no profiles found in %s", match)
+			}
+		}
+	}
+	return profiles, covdata, nil
+}
+
+// isCovdataDir reports whether the directory holds binary coverage data,
+// which always includes a meta-data file.
+func isCovdataDir(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "covmeta.*"))
+	return len(matches) > 0
+}
+
+// appendUnique appends s to list unless it is already there.
+func appendUnique(list []string, s string) []string {
+	for _, e := range list {
+		if e == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// Magic numbers starting the meta-data and counter files of binary
+// coverage data.
+var (
+	covMetaMagic    = []byte("\x00cvm")
+	covCounterMagic = []byte("\x00cwm")
+)
+
+// isCovdataFile reports whether the file is a meta-data or counter file
+// of binary coverage data, which is converted with the rest of its
+// directory.
+func isCovdataFile(filename string) bool {
+	f, err := os.Open(filename)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	magic := make([]byte, len(covMetaMagic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return false
+	}
+	return bytes.Equal(magic, covMetaMagic) || bytes.Equal(magic, covCounterMagic)
+}
+
+// inputFormat is the format of a profile given to the converter.
+type inputFormat int
+
+const (
+	// formatProfile is the text coverprofile format of "go test".
+	formatProfile inputFormat = iota
+	// formatJSON is gocov's JSON interchange format.
+	formatJSON
+	// formatLCOV is the LCOV tracefile format.
+	formatLCOV
+	// formatCobertura is the Cobertura XML format.
+	formatCobertura
+)
+
+// lcovRecords holds the prefixes of LCOV records that may start a
+// tracefile.
+var lcovRecords = []string{"TN:", "SF:", "FN:", "DA:", "end_of_record"}
+
+// sniffFormat determines the format of a profile from its content.
+// Coverprofiles are assumed when nothing else matches, so that their
+// parse errors are reported for unrecognized inputs, as XML inputs are
+// assumed to be Cobertura reports, whose root element is checked when
+// they are parsed.
+func sniffFormat(data []byte) inputFormat {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	if bytes.HasPrefix(data, []byte("{")) {
+		return formatJSON
+	}
+	if bytes.HasPrefix(data, []byte("<")) {
+		return formatCobertura
+	}
+	for _, record := range lcovRecords {
+		if bytes.HasPrefix(data, []byte(record)) {
+			return formatLCOV
+		}
+	}
+	return formatProfile
+}
+
+// covdataProfile converts binary coverage data directories into a single
+// text profile in tmpDir using "go tool covdata", returning its name.
+func covdataProfile(dirs []string, tmpDir string) (string, error) {
+	profile := filepath.Join(tmpDir, "covdata.out")
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", "tool", "covdata", "textfmt", "-i="+strings.Join(dirs, ","), "-o="+profile)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("go tool covdata: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return profile, nil
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+// istanbulFile is the coverage of a source file in an istanbul coverage
+// report, as written by nyc, jest and c8 (coverage-final.json).
+type istanbulFile struct {
+	Path         string                      `json:"path"`
+	StatementMap map[string]istanbulRange    `json:"statementMap"`
+	FnMap        map[string]istanbulFunction `json:"fnMap"`
+	S            map[string]int64            `json:"s"`
+
+	// name is the name of the file, as in coverprofiles.
+	name string
+}
+
+type istanbulFunction struct {
+	Name string        `json:"name"`
+	Loc  istanbulRange `json:"loc"`
+}
+
+type istanbulRange struct {
+	Start istanbulPosition `json:"start"`
+	End   istanbulPosition `json:"end"`
+}
+
+// istanbulPosition is a position in a source file: a 1-based line, and a
+// 0-based column counted in UTF-16 code units. A missing column stands
+// for the end of the line.
+type istanbulPosition struct {
+	Line   int  `json:"line"`
+	Column *int `json:"column"`
+}
+
+// parseIstanbul parses an istanbul coverage report, which maps the paths
+// of source files to their coverage. Paths are resolved as by
+// lineFileName.
+func (c *Converter) parseIstanbul(data []byte) ([]*istanbulFile, error) {
+	var report map[string]*istanbulFile
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	files := make([]*istanbulFile, 0, len(report))
+	for key, f := range report {
+		if f == nil {
+			continue
+		}
+		if f.Path == "" {
+			f.Path = key
+		}
+		f.name = c.lineFileName(f.Path, nil)
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+	return files, nil
+}
+
+// sortIstanbulKeys sorts the keys of an istanbul map, which number its
+// entries, in numeric order.
+func sortIstanbulKeys(keys []string) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, aerr := strconv.Atoi(keys[i])
+		b, berr := strconv.Atoi(keys[j])
+		if aerr != nil || berr != nil {
+			return keys[i] < keys[j]
+		}
+		return a < b
+	})
+}
+
+// offset returns the offset of a position in the source, or -1 if the
+// position lies past the end of the source.
+func (x *lineIndex) offset(pos istanbulPosition) int {
+	if pos.Line < 1 || pos.Line > x.lines() {
+		return -1
+	}
+	start, end := x.start(pos.Line), x.end(pos.Line)
+	if pos.Column == nil {
+		return end
+	}
+	offset := start
+	for units := 0; offset < end && units < *pos.Column; {
+		r, size := utf8.DecodeRune(x.src[offset:end])
+		offset += size
+		if r >= 0x10000 {
+			units += 2
+		} else {
+			units++
+		}
+	}
+	return offset
+}
+
+// importIstanbul imports the coverage of a source file from an istanbul
+// report into gocov's model. Each statement is added to the innermost
+// function enclosing it, or to a synthetic "@file" function. The source
+// is read to turn positions into offsets; statements past its end are
+// reported as orphaned blocks.
+func (c *Converter) importIstanbul(f *istanbulFile, pkgName, abspath string) (*gocov.Package, error) {
+	src, err := gocovutil.ReadSource(c.fsys, abspath)
+	if err != nil {
+		return nil, err
+	}
+	index := newLineIndex(src)
+
+	pkg := &gocov.Package{Name: pkgName, Language: sourceLanguage(abspath)}
+	fileFunction := &gocov.Function{Name: "@file", File: abspath, End: len(src)}
+	keys := make([]string, 0, len(f.FnMap))
+	for key := range f.FnMap {
+		keys = append(keys, key)
+	}
+	sortIstanbulKeys(keys)
+	var functions []*gocov.Function
+	for _, key := range keys {
+		fn := f.FnMap[key]
+		start, end := index.offset(fn.Loc.Start), index.offset(fn.Loc.End)
+		if start < 0 || end < start {
+			continue
+		}
+		functions = append(functions, &gocov.Function{Name: fn.Name, File: abspath, Start: start, End: end})
+	}
+	sort.SliceStable(functions, func(i, j int) bool { return functions[i].Start < functions[j].Start })
+
+	mismatch := Mismatch{File: abspath}
+	keys = keys[:0]
+	for key := range f.StatementMap {
+		keys = append(keys, key)
+	}
+	sortIstanbulKeys(keys)
+	for _, key := range keys {
+		r := f.StatementMap[key]
+		start, end := index.offset(r.Start), index.offset(r.End)
+		if start < 0 || end < start {
+			mismatch.OrphanedBlocks++
+			continue
+		}
+		// Functions are ordered by start, so the last that encloses the
+		// statement is the innermost.
+		enclosing := fileFunction
+		for _, fn := range functions {
+			if fn.Start > start {
+				break
+			}
+			if end <= fn.End {
+				enclosing = fn
+			}
+		}
+		enclosing.Statements = append(enclosing.Statements, &gocov.Statement{
+			Start:   start,
+			End:     end,
+			Reached: f.S[key],
+		})
+	}
+	if c.mismatched != nil {
+		c.reportMismatch(mismatch)
+	}
+	if len(fileFunction.Statements) > 0 {
+		pkg.Functions = append(pkg.Functions, fileFunction)
+	}
+	pkg.Functions = append(pkg.Functions, functions...)
+	return pkg, nil
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// parseLCOV parses an LCOV tracefile. The records of a source file in
+// several tests are summed.
+//
+// LCOV names source files by path; see lineFileName.
+func (c *Converter) parseLCOV(r io.Reader) ([]*lineCoverage, error) {
+	var files lineFiles
+	var file *lineCoverage
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			file = files.file(c.lineFileName(strings.TrimPrefix(line, "SF:"), nil), "")
+		case strings.HasPrefix(line, "FN:"):
+			if file == nil {
+				return nil, fmt.Errorf("line %d: FN record outside of a source file", n)
+			}
+			fields := strings.SplitN(strings.TrimPrefix(line, "FN:"), ",", 2)
+			lineno, err := strconv.Atoi(fields[0])
+			if len(fields) < 2 || err != nil || lineno < 1 {
+				return nil, fmt.Errorf("line %d: invalid FN record %q", n, line)
+			}
+			file.function(fields[1], lineno)
+		case strings.HasPrefix(line, "DA:"):
+			if file == nil {
+				return nil, fmt.Errorf("line %d: DA record outside of a source file", n)
+			}
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("line %d: invalid DA record %q", n, line)
+			}
+			lineno, err := strconv.Atoi(fields[0])
+			if err != nil || lineno < 1 {
+				return nil, fmt.Errorf("line %d: invalid line number in %q", n, line)
+			}
+			count, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil || count < 0 {
+				return nil, fmt.Errorf("line %d: invalid execution count in %q", n, line)
+			}
+			file.counts[lineno] += count
+		case line == "end_of_record":
+			file = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return files.sorted(), nil
+}
@@ -0,0 +1,311 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"bytes"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+	"golang.org/x/tools/cover"
+)
+
+// lineCoverage is the coverage of a source file by line, as recorded by
+// LCOV and Cobertura.
+type lineCoverage struct {
+	// name is the name of the file, as in coverprofiles.
+	name string
+
+	// pkg is the name of the file's package, or "" to name it by the
+	// file's directory.
+	pkg string
+
+	// functions holds the first line of each function, by name.
+	functions map[string]int
+
+	// counts holds the execution count of each line.
+	counts map[int]int64
+}
+
+// function records the first line of a function. A function recorded
+// more than once, as by several tests, starts at its earliest line.
+func (f *lineCoverage) function(name string, line int) {
+	if first, ok := f.functions[name]; !ok || line < first {
+		f.functions[name] = line
+	}
+}
+
+// profile converts the line coverage of a Go source file to coverprofile
+// form, so that it is matched to the statements found by parsing the file:
+// each line becomes a block spanning the whole line.
+func (f *lineCoverage) profile() *cover.Profile {
+	p := &cover.Profile{FileName: f.name, Mode: "count"}
+	for _, line := range f.lines() {
+		p.Blocks = append(p.Blocks, cover.ProfileBlock{
+			StartLine: line,
+			StartCol:  1,
+			EndLine:   line,
+			EndCol:    math.MaxInt32,
+			NumStmt:   1,
+			Count:     int(f.counts[line]),
+		})
+	}
+	return p
+}
+
+// lines returns the numbers of the lines with coverage, in order.
+func (f *lineCoverage) lines() []int {
+	lines := make([]int, 0, len(f.counts))
+	for line := range f.counts {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// isGo reports whether the file is a Go source file.
+func (f *lineCoverage) isGo() bool {
+	return strings.HasSuffix(f.name, ".go")
+}
+
+// languages maps the extensions of source files to the names of their
+// languages, as recorded in the packages imported from them.
+var languages = map[string]string{
+	".c":     "c",
+	".cc":    "c++",
+	".cjs":   "javascript",
+	".cpp":   "c++",
+	".cs":    "c#",
+	".h":     "c",
+	".hpp":   "c++",
+	".java":  "java",
+	".js":    "javascript",
+	".jsx":   "javascript",
+	".kt":    "kotlin",
+	".mjs":   "javascript",
+	".php":   "php",
+	".py":    "python",
+	".rb":    "ruby",
+	".rs":    "rust",
+	".scala": "scala",
+	".swift": "swift",
+	".ts":    "typescript",
+	".tsx":   "typescript",
+}
+
+// sourceLanguage returns the language of a source file other than Go,
+// by its extension.
+func sourceLanguage(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if lang, ok := languages[ext]; ok {
+		return lang
+	}
+	if ext == "" {
+		return "unknown"
+	}
+	return ext[1:]
+}
+
+// lineFiles collects the line coverage of source files by name, merging
+// the records of a file.
+type lineFiles map[string]*lineCoverage
+
+// file returns the coverage of the named file, adding it if need be.
+func (files *lineFiles) file(name, pkg string) *lineCoverage {
+	if *files == nil {
+		*files = make(lineFiles)
+	}
+	f := (*files)[name]
+	if f == nil {
+		f = &lineCoverage{name: name, pkg: pkg, functions: make(map[string]int), counts: make(map[int]int64)}
+		(*files)[name] = f
+	}
+	return f
+}
+
+// add merges the coverage of a file into the collection.
+func (files *lineFiles) add(f *lineCoverage) {
+	merged := files.file(f.name, f.pkg)
+	for name, line := range f.functions {
+		merged.function(name, line)
+	}
+	for line, count := range f.counts {
+		merged.counts[line] += count
+	}
+}
+
+// sorted returns the files in order of name.
+func (files lineFiles) sorted() []*lineCoverage {
+	sorted := make([]*lineCoverage, 0, len(files))
+	for _, f := range files {
+		sorted = append(sorted, f)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].name < sorted[j].name })
+	return sorted
+}
+
+// lineFileName returns the coverprofile file name of a source file named
+// by path in a line coverage report. Packages are thus named by directory,
+// as for profiles of packages outside any module. A relative path is
+// looked up in each of the report's source roots, then in the directory in
+// which packages are resolved; it is made absolute if the file is found,
+// and otherwise taken to start with an import path.
+func (c *Converter) lineFileName(name string, roots []string) string {
+	if filepath.IsAbs(name) {
+		return filepath.ToSlash(name)
+	}
+	for _, root := range append(roots, c.dir) {
+		path := filepath.Join(root, name)
+		if c.fsys == nil {
+			if abs, err := filepath.Abs(path); err == nil {
+				path = abs
+			}
+			if _, err := os.Stat(path); err == nil {
+				return filepath.ToSlash(path)
+			}
+		} else if filepath.IsAbs(path) {
+			if _, err := fs.Stat(c.fsys, gocovutil.SourcePath(path)); err == nil {
+				return filepath.ToSlash(path)
+			}
+		}
+	}
+	return filepath.ToSlash(name)
+}
+
+// lineIndex locates the lines of a source file.
+type lineIndex struct {
+	src []byte
+
+	// starts[i] is the offset of line i+1; the last entry is the end of
+	// the source.
+	starts []int
+}
+
+func newLineIndex(src []byte) *lineIndex {
+	starts := []int{0}
+	for i, b := range src {
+		if b == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		starts = append(starts, len(src))
+	}
+	return &lineIndex{src, starts}
+}
+
+// lines returns the number of lines.
+func (x *lineIndex) lines() int {
+	return len(x.starts) - 1
+}
+
+// start returns the offset of the start of a line.
+func (x *lineIndex) start(line int) int {
+	return x.starts[line-1]
+}
+
+// end returns the offset of the end of a line, before its newline.
+func (x *lineIndex) end(line int) int {
+	if line >= len(x.starts) {
+		// An empty source has no lines; see importFile.
+		return len(x.src)
+	}
+	end := x.starts[line]
+	if end > x.starts[line-1] && x.src[end-1] == '\n' {
+		end--
+	}
+	return end
+}
+
+// importFile imports the line coverage of a source file other than Go
+// into gocov's model without parsing it: each line with coverage becomes
+// a statement spanning the line, in the function whose first line is the
+// closest before it, or in a synthetic "@file" function. The source is
+// read for the offsets of its lines; lines past its end are reported as
+// orphaned blocks.
+func (c *Converter) importFile(f *lineCoverage, pkgName, abspath string) (*gocov.Package, error) {
+	src, err := gocovutil.ReadSource(c.fsys, abspath)
+	if err != nil {
+		return nil, err
+	}
+	index := newLineIndex(src)
+	nlines := index.lines()
+
+	// Lines before the first function belong to "@file", which is
+	// dropped if none has coverage.
+	type function struct {
+		name string
+		line int
+	}
+	functions := []function{{"@file", 1}}
+	for name, line := range f.functions {
+		if line <= nlines {
+			functions = append(functions, function{name, line})
+		}
+	}
+	sort.SliceStable(functions[1:], func(i, j int) bool {
+		a, b := functions[1+i], functions[1+j]
+		return a.line < b.line || a.line == b.line && a.name < b.name
+	})
+	converted := make([]*gocov.Function, len(functions))
+	for i, fn := range functions {
+		end := nlines
+		if i+1 < len(functions) {
+			end = functions[i+1].line - 1
+		}
+		if end < fn.line {
+			end = fn.line
+		}
+		converted[i] = &gocov.Function{Name: fn.name, File: abspath, Start: index.start(fn.line), End: index.end(end)}
+	}
+
+	mismatch := Mismatch{File: abspath}
+	for _, line := range f.lines() {
+		if line > nlines {
+			mismatch.OrphanedBlocks++
+			continue
+		}
+		i := sort.Search(len(functions), func(i int) bool { return functions[i].line > line }) - 1
+		text := src[index.start(line):index.end(line)]
+		start := index.end(line) - len(bytes.TrimLeft(text, " \t"))
+		converted[i].Statements = append(converted[i].Statements, &gocov.Statement{
+			Start:   start,
+			End:     index.end(line),
+			Reached: f.counts[line],
+		})
+	}
+	if c.mismatched != nil {
+		c.reportMismatch(mismatch)
+	}
+	pkg := &gocov.Package{Name: pkgName, Language: sourceLanguage(abspath)}
+	for _, fn := range converted {
+		if fn.Name != "@file" || len(fn.Statements) > 0 {
+			pkg.Functions = append(pkg.Functions, fn)
+		}
+	}
+	return pkg, nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"github.com/hihoak/gocov"
+)
+
+// BlockMatch records the statements to which a profile block was matched
+// during conversion, for diagnosing mismatches between profiles and
+// sources, as when the sources were reformatted or changed after the
+// profile was recorded.
+type BlockMatch struct {
+	// File is the absolute path of the source file.
+	File string
+
+	// Block is the profile block.
+	Block gocov.Block
+
+	// Statements holds the statements whose extents overlap the block.
+	// A block matched to no statement is orphaned; see
+	// Mismatch.OrphanedBlocks for what becomes of its count.
+	Statements []MatchedStatement
+}
+
+// MatchedStatement describes a statement to which a profile block was
+// matched, by its function and 1-based line and column extent.
+type MatchedStatement struct {
+	Function            string
+	StartLine, StartCol int
+	EndLine, EndCol     int
+}
+
+// WithMatchHandler calls fn for each profile block of each converted
+// source file, with the statements to which the block was matched. The
+// blocks of a file are reported together and in profile order, although
+// files may be reported in any order.
+func WithMatchHandler(fn func(BlockMatch)) Option {
+	return func(c *Converter) {
+		c.matched = fn
+	}
+}
+
+// reportMatches reports the matches of a file's blocks to the match
+// handler, matches[i] holding the statements of blocks[i].
+func (c *Converter) reportMatches(absFilePath string, blocks []*gocov.Block, matches [][]MatchedStatement) {
+	c.handlerMu.Lock()
+	defer c.handlerMu.Unlock()
+	for i, b := range blocks {
+		c.matched(BlockMatch{File: absFilePath, Block: *b, Statements: matches[i]})
+	}
+}
+
+// Mismatch counts the profile blocks and statements of a source file that
+// could not be matched to one another, whose coverage would otherwise be
+// silently lost or misreported.
+type Mismatch struct {
+	// File is the absolute path of the source file.
+	File string
+
+	// OrphanedBlocks is the number of profile blocks of one or more
+	// statements that matched no statement. The counts of those lying
+	// within a function are lost; those of blocks outside every
+	// function are kept in a synthetic "@file" function if
+	// WithLenientMatching is used, and lost otherwise.
+	OrphanedBlocks int
+
+	// UnmatchedStatements is the number of statements that matched no
+	// profile block, and so are reported as never reached.
+	UnmatchedStatements int
+}
+
+// WithMismatchHandler calls fn for each converted source file with
+// orphaned blocks or unmatched statements.
+func WithMismatchHandler(fn func(Mismatch)) Option {
+	return func(c *Converter) {
+		c.mismatched = fn
+	}
+}
+
+// reportMismatch reports the mismatches of a file to the mismatch
+// handler, if there are any.
+func (c *Converter) reportMismatch(m Mismatch) {
+	if m.OrphanedBlocks == 0 && m.UnmatchedStatements == 0 {
+		return
+	}
+	c.handlerMu.Lock()
+	defer c.handlerMu.Unlock()
+	c.mismatched(m)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// MockTag is the tag attached to the statements of generated mocks
+// retained by WithMocks.
+const MockTag = "mock"
+
+// mockGenerators maps the generator names of "Code generated by" headers
+// to the mock frameworks they belong to.
+var mockGenerators = map[string]string{
+	"MockGen": "gomock",
+	"mockery": "mockery",
+	"moq":     "moq",
+}
+
+// WithMocks retains the files of generated mocks, which are otherwise
+// left out of the conversion since their coverage inflates that of the
+// code they stand in for. Their statements are tagged with MockTag, so
+// that they can be reported separately.
+func WithMocks() Option {
+	return func(c *Converter) {
+		c.mocks = true
+	}
+}
+
+// mockGenerator returns the mock framework that generated the source
+// file, as named by its "Code generated by" header comment, or "" if it
+// was not generated by a known mock framework.
+func mockGenerator(src []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+		generator := strings.TrimPrefix(line, "// Code generated by ")
+		if generator == line || !strings.HasSuffix(line, "DO NOT EDIT.") {
+			continue
+		}
+		if i := strings.IndexAny(generator, " .;,"); i >= 0 {
+			generator = generator[:i]
+		}
+		if mock, ok := mockGenerators[generator]; ok {
+			return mock
+		}
+	}
+	return ""
+}
@@ -0,0 +1,273 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Option configures a Converter.
+type Option func(*Converter)
+
+// WithBlocks retains the coverprofile blocks of each source file in the
+// converted packages' Files, alongside the statements derived from them.
+func WithBlocks() Option {
+	return func(c *Converter) {
+		c.blocks = true
+	}
+}
+
+// WithConcurrency sets the maximum number of profiles, and of source files,
+// parsed at once. It defaults to GOMAXPROCS.
+func WithConcurrency(n int) Option {
+	return func(c *Converter) {
+		c.concurrency = n
+	}
+}
+
+// WithExcludes excludes packages, or individual files, whose import path
+// or profile file name (the import path followed by "/" and the base name)
+// matches one of the given patterns, as for WithPackages.
+func WithExcludes(patterns ...string) Option {
+	return func(c *Converter) {
+		c.excludes = append(c.excludes, patterns...)
+	}
+}
+
+// WithFS sets the file system from which source files are read. Files are
+// opened by their absolute path without the leading slash (or volume
+// name), as for os.DirFS("/"). By default, the operating system's file
+// system is used.
+func WithFS(fsys fs.FS) Option {
+	return func(c *Converter) {
+		c.fsys = fsys
+	}
+}
+
+// WithMmap memory-maps source files while they are parsed, on platforms
+// that support it, rather than reading them into memory, which saves
+// allocating and copying their contents when converting very large source
+// trees. It has no effect when WithFS is used.
+func WithMmap() Option {
+	return func(c *Converter) {
+		c.mmap = true
+	}
+}
+
+// WithShard restricts conversion to shard index (counting from zero) of
+// total shards. Packages are partitioned by a hash of their import path,
+// so the partition is stable across runs and machines, and the outputs
+// of all shards may be merged to obtain the full conversion.
+func WithShard(index, total int) Option {
+	return func(c *Converter) {
+		c.shardIndex, c.shardTotal = index, total
+	}
+}
+
+// inShard reports whether the package with the given import path belongs
+// to shard index of total.
+func inShard(pkgpath string, index, total int) bool {
+	h := fnv.New32a()
+	h.Write([]byte(pkgpath))
+	return int(h.Sum32()%uint32(total)) == index
+}
+
+// WithResolver sets the Resolver used to locate the source files of the
+// packages named by profiles. By default, packages are resolved with the
+// go tool.
+func WithResolver(r Resolver) Option {
+	return func(c *Converter) {
+		c.resolver = r
+	}
+}
+
+// WithDir sets the directory in which the go tool resolves the packages
+// named by profiles. By default, they are resolved in the current
+// directory. WithDir has no effect when WithResolver is used.
+func WithDir(dir string) Option {
+	return func(c *Converter) {
+		c.dir = dir
+	}
+}
+
+// WithBuildFlags sets build flags, such as "-tags=integration", with which
+// the go tool resolves the packages named by profiles. Profiles gathered
+// with build tags should be converted with the same tags, so that the
+// files those tags select are found. WithBuildFlags has no effect when
+// WithResolver is used.
+func WithBuildFlags(flags ...string) Option {
+	return func(c *Converter) {
+		c.buildFlags = append(c.buildFlags, flags...)
+	}
+}
+
+// WithEnv sets additional environment variables, such as "GOOS=windows",
+// in which the go tool resolves the packages named by profiles, as for
+// profiles gathered for another platform. WithEnv has no effect when
+// WithResolver is used.
+func WithEnv(env ...string) Option {
+	return func(c *Converter) {
+		c.env = append(c.env, env...)
+	}
+}
+
+// WithLabels attaches labels describing the run that produced the
+// profiles, e.g. "kind": "benchmark", to the converted document.
+func WithLabels(labels map[string]string) Option {
+	return func(c *Converter) {
+		if c.labels == nil {
+			c.labels = make(map[string]string)
+		}
+		for k, v := range labels {
+			c.labels[k] = v
+		}
+	}
+}
+
+// WithLenientMatching attributes profile blocks that lie outside every
+// function, as emitted by some alternative toolchains for init wrappers,
+// to a synthetic "@file" function instead of dropping them.
+func WithLenientMatching() Option {
+	return func(c *Converter) {
+		c.lenient = true
+	}
+}
+
+// WithRewrite rewrites the import path prefix old to new in the file names
+// recorded in profiles, before packages are selected and resolved, for
+// projects whose profiles name packages by a vanity import path or that of
+// a replaced or forked module. Rewrites are tried in the order given, and
+// the first whose prefix matches applies.
+func WithRewrite(old, new string) Option {
+	return func(c *Converter) {
+		c.rewrites = append(c.rewrites, [2]string{old, new})
+	}
+}
+
+// rewrite applies the first matching rewrite to a profile file name.
+func (c *Converter) rewrite(filename string) string {
+	for _, r := range c.rewrites {
+		if filename == r[0] || strings.HasPrefix(filename, r[0]+"/") {
+			return r[1] + filename[len(r[0]):]
+		}
+	}
+	return filename
+}
+
+// WithCaseInsensitivePaths matches the file names recorded in profiles to
+// source files regardless of case, for profiles gathered on
+// case-insensitive file systems, such as those of macOS, whose paths may
+// differ in case from those reported by the go tool.
+func WithCaseInsensitivePaths() Option {
+	return func(c *Converter) {
+		c.foldCase = true
+	}
+}
+
+// PathStyle controls how the file names recorded in coverprofiles are
+// split into a package and a base name.
+type PathStyle int
+
+const (
+	// PathStyleAuto treats file names containing a backslash or starting
+	// with a drive letter as Windows paths, and all others as slash
+	// separated.
+	PathStyleAuto PathStyle = iota
+	// PathStyleSlash treats all file names as slash separated.
+	PathStyleSlash
+	// PathStyleWindows treats all file names as Windows paths.
+	PathStyleWindows
+)
+
+// ParsePathStyle parses "auto", "slash" or "windows" as a PathStyle.
+func ParsePathStyle(s string) (PathStyle, error) {
+	switch s {
+	case "auto", "":
+		return PathStyleAuto, nil
+	case "slash":
+		return PathStyleSlash, nil
+	case "windows":
+		return PathStyleWindows, nil
+	}
+	return PathStyleAuto, fmt.Errorf("unknown path style %q", s)
+}
+
+// WithPathStyle overrides the detection of Windows file names in
+// coverprofiles.
+func WithPathStyle(style PathStyle) Option {
+	return func(c *Converter) {
+		c.pathStyle = style
+	}
+}
+
+// isWindowsPath reports whether name looks like a Windows file name.
+func isWindowsPath(name string) bool {
+	if strings.Contains(name, `\`) {
+		return true
+	}
+	return len(name) >= 2 && name[1] == ':' &&
+		('a' <= name[0] && name[0] <= 'z' || 'A' <= name[0] && name[0] <= 'Z')
+}
+
+// splitProfileFileName splits a coverprofile file name into its package
+// (an import path or, for packages outside any module, a slash-separated
+// directory) and the base name of the file.
+func splitProfileFileName(name string, style PathStyle) (pkgpath, filename string) {
+	if style == PathStyleWindows || (style == PathStyleAuto && isWindowsPath(name)) {
+		name = strings.Replace(name, `\`, "/", -1)
+	}
+	pkgpath, filename = path.Split(name)
+	pkgpath = strings.TrimSuffix(pkgpath, "/")
+	if pkgpath == "" {
+		pkgpath = "."
+	}
+	return pkgpath, filename
+}
+
+// WithPackages restricts conversion to packages whose import path matches
+// one of the given patterns. As with "go list", a pattern may contain
+// "..." wildcards, and a trailing "/..." also matches the parent path.
+// Profiles generated with -coverpkg often cover far more packages than
+// are of interest.
+func WithPackages(patterns ...string) Option {
+	return func(c *Converter) {
+		c.packages = append(c.packages, patterns...)
+	}
+}
+
+// MatchPattern returns a function reporting whether an import path
+// matches the given "go list" style pattern, in which "..." matches any
+// string and a trailing "/..." also matches the parent path.
+func MatchPattern(pattern string) func(string) bool {
+	re := regexp.QuoteMeta(pattern)
+	re = strings.Replace(re, `\.\.\.`, `.*`, -1)
+	// Special case: foo/... matches foo too.
+	if strings.HasSuffix(re, `/.*`) {
+		re = re[:len(re)-len(`/.*`)] + `(/.*)?`
+	}
+	reg := regexp.MustCompile(`^` + re + `$`)
+	return reg.MatchString
+}
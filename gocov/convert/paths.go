@@ -0,0 +1,171 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// ErrorPathTag is the tag ErrorPaths attaches to error handling statements.
+const ErrorPathTag = "error-path"
+
+// ErrorPaths is a Classifier tagging the statements that handle errors
+// with ErrorPathTag: those in the body of an "if err != nil" statement
+// (or the else branch of "if err == nil"), and return statements whose
+// last result is an error variable or a new error. Errors are recognized
+// by name, as variables named err or ending in Err or err.
+func ErrorPaths(info StatementInfo) Classification {
+	if isErrorReturn(info.Stmt) {
+		return Classification{Tags: []string{ErrorPathTag}}
+	}
+	for i, stmt := range info.Enclosing {
+		ifStmt, ok := stmt.(*ast.IfStmt)
+		if !ok || i+1 == len(info.Enclosing) {
+			continue
+		}
+		branch := info.Enclosing[i+1]
+		if branch == ifStmt.Body && isErrorCheck(ifStmt.Cond, token.NEQ) ||
+			branch == ifStmt.Else && isErrorCheck(ifStmt.Cond, token.EQL) {
+			return Classification{Tags: []string{ErrorPathTag}}
+		}
+	}
+	return Classification{}
+}
+
+// PanicPathTag is the tag PanicPaths attaches to statements on panic and
+// recovery paths.
+const PanicPathTag = "panic-path"
+
+// PanicPaths is a Classifier tagging the statements on panic and recovery
+// paths with PanicPathTag: those in function literals called by defer
+// statements, where panics are recovered, and those in branches that
+// panic, such as an if statement's body or a case calling panic.
+func PanicPaths(info StatementInfo) Classification {
+	if info.Deferred || isPanic(info.Stmt) {
+		return Classification{Tags: []string{PanicPathTag}}
+	}
+	// The first enclosing statement is the function body, which is not
+	// a branch.
+	for i, stmt := range info.Enclosing {
+		var body []ast.Stmt
+		switch stmt := stmt.(type) {
+		case *ast.BlockStmt:
+			if i > 0 {
+				body = stmt.List
+			}
+		case *ast.CaseClause:
+			body = stmt.Body
+		case *ast.CommClause:
+			body = stmt.Body
+		}
+		for _, s := range body {
+			if isPanic(s) {
+				return Classification{Tags: []string{PanicPathTag}}
+			}
+		}
+	}
+	return Classification{}
+}
+
+// GoroutineTag is the tag Goroutines attaches to statements spawning and
+// running goroutines.
+const GoroutineTag = "goroutine"
+
+// Goroutines is a Classifier tagging go statements, and the statements of
+// function literals they run as goroutines, with GoroutineTag.
+func Goroutines(info StatementInfo) Classification {
+	if _, ok := info.Stmt.(*ast.GoStmt); ok || info.Spawned {
+		return Classification{Tags: []string{GoroutineTag}}
+	}
+	return Classification{}
+}
+
+// isPanic reports whether s calls the panic builtin.
+func isPanic(s ast.Stmt) bool {
+	expr, ok := s.(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+	call, ok := expr.X.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	id, ok := call.Fun.(*ast.Ident)
+	return ok && id.Name == "panic"
+}
+
+// isErrorName reports whether name looks like the name of an error.
+func isErrorName(name string) bool {
+	return name == "err" || strings.HasSuffix(name, "Err") || strings.HasSuffix(name, "err")
+}
+
+// isErrorCheck reports whether cond compares an error with nil by op.
+func isErrorCheck(cond ast.Expr, op token.Token) bool {
+	b, ok := cond.(*ast.BinaryExpr)
+	if !ok || b.Op != op {
+		return false
+	}
+	x, y := b.X, b.Y
+	if isNil(x) {
+		x, y = y, x
+	}
+	id, ok := x.(*ast.Ident)
+	return ok && isNil(y) && isErrorName(id.Name)
+}
+
+// isErrorReturn reports whether s is a return statement whose last result
+// is an error variable or a new error, made by errors.New, fmt.Errorf or
+// the like.
+func isErrorReturn(s ast.Stmt) bool {
+	ret, ok := s.(*ast.ReturnStmt)
+	if !ok || len(ret.Results) == 0 {
+		return false
+	}
+	switch x := ret.Results[len(ret.Results)-1].(type) {
+	case *ast.Ident:
+		return isErrorName(x.Name)
+	case *ast.CallExpr:
+		sel, ok := x.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return false
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		return ok && errorConstructors[pkg.Name+"."+sel.Sel.Name]
+	}
+	return false
+}
+
+// errorConstructors holds the functions recognized as making new errors.
+var errorConstructors = map[string]bool{
+	"errors.New":    true,
+	"errors.Errorf": true,
+	"errors.Join":   true,
+	"errors.Wrap":   true,
+	"errors.Wrapf":  true,
+	"fmt.Errorf":    true,
+}
+
+func isNil(x ast.Expr) bool {
+	id, ok := x.(*ast.Ident)
+	return ok && id.Name == "nil"
+}
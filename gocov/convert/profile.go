@@ -0,0 +1,70 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/hihoak/gocov"
+)
+
+// WriteProfile writes the profile blocks retained by WithBlocks as a
+// coverprofile, reconstructing the profile the packages were converted
+// from. Files are written in order of package and file name.
+func WriteProfile(w io.Writer, packages []*gocov.Package) error {
+	var mode string
+	type profileFile struct {
+		name string
+		file *gocov.File
+	}
+	var files []profileFile
+	for _, pkg := range packages {
+		for _, f := range pkg.Files {
+			if mode == "" {
+				mode = f.Mode
+			} else if f.Mode != "" && f.Mode != mode {
+				return fmt.Errorf("mixed coverage modes %s and %s", mode, f.Mode)
+			}
+			files = append(files, profileFile{pkg.Name + "/" + filepath.Base(f.File), f})
+		}
+	}
+	if files == nil {
+		return fmt.Errorf("no profile blocks to write; convert with blocks retained")
+	}
+	if mode == "" {
+		mode = "set"
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].name < files[j].name })
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "mode: %s\n", mode)
+	for _, f := range files {
+		for _, b := range f.file.Blocks {
+			fmt.Fprintf(bw, "%s:%d.%d,%d.%d %d %d\n",
+				f.name, b.StartLine, b.StartCol, b.EndLine, b.EndCol, b.NumStmt, b.Count)
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,329 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	goPackages "golang.org/x/tools/go/packages"
+)
+
+// Resolver locates the source files of the packages named in
+// coverprofiles.
+type Resolver interface {
+	// Resolve returns the absolute paths of the Go source files of the
+	// package with the given import path. Profiles of packages outside
+	// any module or GOPATH name packages by directory instead.
+	Resolve(importPath string) ([]string, error)
+}
+
+// preloader is implemented by Resolvers that can resolve many packages at
+// once more cheaply than one at a time.
+type preloader interface {
+	preload(importPaths []string) error
+}
+
+// StaticResolver resolves packages from a fixed map of import paths to
+// source files, as may be read from a build manifest or a cache.
+type StaticResolver map[string][]string
+
+// Resolve implements Resolver.
+func (r StaticResolver) Resolve(importPath string) ([]string, error) {
+	files, ok := r[importPath]
+	if !ok {
+		return nil, fmt.Errorf("package %s not found", importPath)
+	}
+	return files, nil
+}
+
+// packagesResolver resolves packages with golang.org/x/tools/go/packages,
+// and so with the go tool.
+type packagesResolver struct {
+	dir   string
+	files map[string][]string
+
+	// buildFlags and env are the build flags and additional environment
+	// variables with which packages are loaded.
+	buildFlags []string
+	env        []string
+
+	// foldCase makes Resolve fall back to matching import paths (and
+	// directories) regardless of case.
+	foldCase bool
+
+	// types, if not nil, holds the type information of the packages
+	// loaded, which is loaded only if it is; see WithTypes.
+	types map[string]*types.Package
+
+	// errors holds the errors the go tool reported loading packages,
+	// and modules the directories of the modules of packages loaded.
+	errors  map[string][]string
+	modules map[string]string
+}
+
+func newPackagesResolver(dir string, foldCase bool) *packagesResolver {
+	return &packagesResolver{
+		dir:      dir,
+		files:    make(map[string][]string),
+		foldCase: foldCase,
+		errors:   make(map[string][]string),
+		modules:  make(map[string]string),
+	}
+}
+
+// UnresolvedPackageError is the error of converting a profile naming a
+// package that cannot be resolved, as when the profile was gathered in
+// another module or checkout than the one it is converted in. Such
+// profiles convert with WithDir set to the root of their module, or with
+// their import paths mapped to ones that resolve by WithRewrite.
+type UnresolvedPackageError struct {
+	// ImportPath is the import path, or the directory, of the package.
+	ImportPath string
+
+	// File is the profile file name naming the package.
+	File string
+
+	// Dir is the directory packages were resolved from; see WithDir.
+	Dir string
+
+	// Err is the error of resolving the package.
+	Err error
+}
+
+func (e *UnresolvedPackageError) Error() string {
+	dir := e.Dir
+	if dir == "" {
+		dir = "the current directory"
+	}
+	return fmt.Sprintf("cannot resolve package %s of %s from %s: %v", e.ImportPath, e.File, dir, e.Err)
+}
+
+// Unwrap returns the error of resolving the package.
+func (e *UnresolvedPackageError) Unwrap() error {
+	return e.Err
+}
+
+// LoadError records the errors the go tool reported loading a package.
+// Packages that fail to load, as when build constraints exclude all of
+// their files for the platform converting the profiles, are converted
+// from the source files found in their directory instead.
+type LoadError struct {
+	// Package is the import path of the package.
+	Package string
+
+	// Errors holds the errors reported for the package.
+	Errors []string
+}
+
+// WithLoadErrorHandler calls fn for each converted package the go tool
+// reported errors loading.
+func WithLoadErrorHandler(fn func(LoadError)) Option {
+	return func(c *Converter) {
+		c.loadFailed = fn
+	}
+}
+
+// loadErrorResolver is implemented by Resolvers that report the errors
+// of loading the packages they resolve.
+type loadErrorResolver interface {
+	// loadErrors returns the errors reported loading the package with
+	// the given import path.
+	loadErrors(importPath string) []string
+}
+
+func (r *packagesResolver) loadErrors(importPath string) []string {
+	return r.errors[importPath]
+}
+
+// loadErrors returns the errors reported by the resolver loading the
+// package with the given import path.
+func (c *Converter) loadErrors(pkgpath string) []string {
+	if r, ok := c.resolver.(loadErrorResolver); ok {
+		return r.loadErrors(pkgpath)
+	}
+	return nil
+}
+
+// reportLoadErrors reports the errors of loading a package to the load
+// error handler, if there are any.
+func (c *Converter) reportLoadErrors(pkgpath string) {
+	errs := c.loadErrors(pkgpath)
+	if len(errs) == 0 || c.loadFailed == nil {
+		return
+	}
+	c.handlerMu.Lock()
+	defer c.handlerMu.Unlock()
+	c.loadFailed(LoadError{Package: pkgpath, Errors: errs})
+}
+
+// typesResolver is implemented by Resolvers that load the type
+// information of the packages they resolve.
+type typesResolver interface {
+	// packageTypes returns the type information of the package with
+	// the given import path, or nil if it was not loaded.
+	packageTypes(importPath string) *types.Package
+}
+
+func (r *packagesResolver) packageTypes(importPath string) *types.Package {
+	return r.types[importPath]
+}
+
+func (r *packagesResolver) preload(importPaths []string) error {
+	var missing []string
+	for _, p := range importPaths {
+		if _, ok := r.files[p]; !ok {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	// GoFiles are needed as well as CompiledGoFiles: profiles refer to
+	// the original source, whereas the compiled files of cgo packages
+	// (common in -coverpkg=all profiles) are generated into the cache.
+	mode := goPackages.NeedName | goPackages.NeedFiles | goPackages.NeedCompiledGoFiles | goPackages.NeedModule
+	if r.types != nil {
+		// Types loaded from export data list no imports, so the imports
+		// are loaded too, for the interfaces they declare.
+		mode |= goPackages.NeedTypes | goPackages.NeedImports | goPackages.NeedDeps
+	}
+	cfg := &goPackages.Config{
+		Mode:       mode,
+		Dir:        r.dir,
+		BuildFlags: r.buildFlags,
+	}
+	if len(r.env) > 0 {
+		cfg.Env = append(os.Environ(), r.env...)
+	}
+	packages, err := goPackages.Load(cfg, missing...)
+	if err != nil {
+		return fmt.Errorf("load packages: %v", err)
+	}
+	for _, pkg := range packages {
+		if pkg.Module != nil && pkg.Module.Dir != "" {
+			r.modules[pkg.Module.Path] = pkg.Module.Dir
+		}
+	}
+	for _, pkg := range packages {
+		files := append(append([]string(nil), pkg.GoFiles...), pkg.CompiledGoFiles...)
+		if len(pkg.Errors) > 0 {
+			errs := make([]string, len(pkg.Errors))
+			for i, e := range pkg.Errors {
+				errs[i] = e.Error()
+			}
+			r.errors[pkg.PkgPath] = errs
+			if len(files) == 0 {
+				files = r.filesByPath(pkg.PkgPath)
+			}
+		}
+		r.files[pkg.PkgPath] = files
+		if r.types != nil && pkg.Types != nil {
+			if len(pkg.Types.Imports()) == 0 {
+				pkg.Types.SetImports(importedTypes(pkg))
+			}
+			r.types[pkg.PkgPath] = pkg.Types
+		}
+		// Profiles of packages outside any module or GOPATH, such as
+		// those named on the command line, refer to files by directory.
+		for _, f := range pkg.GoFiles {
+			dirs := []string{filepath.Dir(f)}
+			if dir, err := filepath.EvalSymlinks(dirs[0]); err == nil {
+				dirs = append(dirs, dir)
+			}
+			for _, dir := range dirs {
+				if _, ok := r.files[filepath.ToSlash(dir)]; !ok {
+					r.files[filepath.ToSlash(dir)] = files
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// importedTypes returns the type information of the packages a package
+// imports, sorted by import path.
+func importedTypes(pkg *goPackages.Package) []*types.Package {
+	var imports []*types.Package
+	for _, imp := range pkg.Imports {
+		if imp.Types != nil {
+			imports = append(imports, imp.Types)
+		}
+	}
+	sort.Slice(imports, func(i, j int) bool { return imports[i].Path() < imports[j].Path() })
+	return imports
+}
+
+// filesByPath returns the Go source files in the directory of the package
+// with the given import path, for packages the go tool failed to load.
+// The directory is found from the modules of the packages that did load,
+// or is the import path itself for packages named by directory.
+func (r *packagesResolver) filesByPath(importPath string) []string {
+	dir := ""
+	if filepath.IsAbs(filepath.FromSlash(importPath)) {
+		dir = filepath.FromSlash(importPath)
+	} else {
+		module := ""
+		for path, mdir := range r.modules {
+			if len(path) > len(module) && (importPath == path || strings.HasPrefix(importPath, path+"/")) {
+				module, dir = path, filepath.Join(mdir, filepath.FromSlash(strings.TrimPrefix(importPath, path)))
+			}
+		}
+	}
+	if dir == "" {
+		return nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Resolve implements Resolver.
+func (r *packagesResolver) Resolve(importPath string) ([]string, error) {
+	if err := r.preload([]string{importPath}); err != nil {
+		return nil, err
+	}
+	files, ok := r.files[importPath]
+	if len(files) == 0 && r.foldCase {
+		for p, f := range r.files {
+			if len(f) > 0 && strings.EqualFold(p, importPath) {
+				files, ok = f, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("package %s not found", importPath)
+	}
+	// The go tool reports packages it cannot find as packages with
+	// errors and no files.
+	if errs := r.errors[importPath]; len(files) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("package %s not found: %s", importPath, strings.Join(errs, "; "))
+	}
+	return files, nil
+}
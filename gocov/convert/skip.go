@@ -0,0 +1,67 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"bufio"
+	"path/filepath"
+	"strings"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+// SkipPackageMarker is the comment that excludes a package from the
+// conversion when it appears before the package clause of any of the
+// package's files, typically doc.go.
+const SkipPackageMarker = "//gocov:skip-package"
+
+// skipMarkerFile returns the base name of the first of the package's
+// files marked with SkipPackageMarker, or "" if there is none. Files that
+// cannot be read are ignored here, and reported when converted.
+func (c *Converter) skipMarkerFile(files []string) string {
+	for _, abspath := range files {
+		if c.hasSkipMarker(abspath) {
+			return filepath.Base(abspath)
+		}
+	}
+	return ""
+}
+
+// hasSkipMarker reports whether the source file has SkipPackageMarker
+// before its package clause.
+func (c *Converter) hasSkipMarker(abspath string) bool {
+	f, err := gocovutil.OpenSource(c.fsys, abspath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == SkipPackageMarker {
+			return true
+		}
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package convert
+
+import (
+	"go/types"
+	"sort"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+// WithTypes loads the type information of the converted packages, and of
+// the packages they import, at the cost of type-checking them, and records
+// for their functions what only it tells: the receivers of methods, with
+// aliases resolved, whether functions are part of their package's API, and
+// the interfaces methods implement. Type information is only loaded by the
+// default Resolver.
+func WithTypes() Option {
+	return func(c *Converter) {
+		c.loadTypes = true
+	}
+}
+
+// describeTypes records the type information of the package's functions,
+// if it was loaded; see WithTypes.
+func (c *Converter) describeTypes(pkg *gocov.Package) {
+	r, ok := c.resolver.(typesResolver)
+	if !ok {
+		return
+	}
+	tpkg := r.packageTypes(pkg.Name)
+	if tpkg == nil {
+		return
+	}
+
+	// Functions are found by name, as convert names them: methods by
+	// their receiver's type name, which may be that of an alias.
+	funcs := make(map[string]*types.Func)
+	api := make(map[*types.Func]bool)
+	scope := tpkg.Scope()
+	for _, name := range scope.Names() {
+		switch obj := scope.Lookup(name).(type) {
+		case *types.Func:
+			funcs[name] = obj
+			api[obj] = obj.Exported()
+		case *types.TypeName:
+			named, ok := obj.Type().(*types.Named)
+			if !ok || named.Obj().Pkg() != tpkg {
+				continue
+			}
+			for i := 0; i < named.NumMethods(); i++ {
+				m := named.Method(i)
+				funcs[name+"."+m.Name()] = m
+			}
+			if !obj.Exported() {
+				continue
+			}
+			mset := types.NewMethodSet(types.NewPointer(named))
+			for i := 0; i < mset.Len(); i++ {
+				if m, ok := mset.At(i).Obj().(*types.Func); ok && m.Exported() {
+					api[m] = true
+				}
+			}
+		}
+	}
+	implements := interfacesImplemented(tpkg)
+
+	for _, fn := range pkg.Functions {
+		obj := funcs[gocovutil.WithoutTypeParams(fn.Name)]
+		if obj == nil || fn.Parent != "" {
+			continue
+		}
+		exported := api[obj]
+		fn.Exported = &exported
+		if recv := obj.Type().(*types.Signature).Recv(); recv != nil {
+			fn.Receiver = types.TypeString(recv.Type(), nil)
+		}
+		fn.Implements = implements[obj]
+	}
+}
+
+// interfacesImplemented returns, for the methods of the types declared by
+// a package, the interfaces declared by the package or by those it
+// imports that their receivers implement with them, sorted by name.
+func interfacesImplemented(pkg *types.Package) map[*types.Func][]string {
+	var interfaces []*types.Named
+	for _, p := range append([]*types.Package{pkg}, pkg.Imports()...) {
+		scope := p.Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || obj.IsAlias() || (p != pkg && !obj.Exported()) {
+				continue
+			}
+			named, ok := obj.Type().(*types.Named)
+			if !ok || !types.IsInterface(named) || named.Underlying().(*types.Interface).NumMethods() == 0 {
+				continue
+			}
+			interfaces = append(interfaces, named)
+		}
+	}
+
+	// A method promoted to several types implements an interface once.
+	implemented := make(map[*types.Func]map[string]bool)
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || obj.IsAlias() || types.IsInterface(obj.Type()) {
+			continue
+		}
+		t := types.NewPointer(obj.Type())
+		mset := types.NewMethodSet(t)
+		for _, iface := range interfaces {
+			it := iface.Underlying().(*types.Interface)
+			if !types.Implements(t, it) {
+				continue
+			}
+			for i := 0; i < it.NumMethods(); i++ {
+				m := it.Method(i)
+				sel := mset.Lookup(m.Pkg(), m.Name())
+				if sel == nil {
+					continue
+				}
+				if fn, ok := sel.Obj().(*types.Func); ok && fn.Pkg() == pkg {
+					if implemented[fn] == nil {
+						implemented[fn] = make(map[string]bool)
+					}
+					implemented[fn][types.TypeString(iface, nil)] = true
+				}
+			}
+		}
+	}
+	implements := make(map[*types.Func][]string, len(implemented))
+	for fn, set := range implemented {
+		for name := range set {
+			implements[fn] = append(implements[fn], name)
+		}
+		sort.Strings(implements[fn])
+	}
+	return implements
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+// Package covtest asserts coverage from within Go tests, so that coverage
+// gates fail "go test" like any other test:
+//
+//	func TestCoverage(t *testing.T) {
+//		cov := covtest.Run(t, "./...")
+//		cov.AtLeast(t, "example.com/project/parser", 80)
+//		cov.Covered(t, "example.com/project/parser", "Parse")
+//	}
+//
+// Coverage is either measured by running the tests of other packages with
+// Run, or loaded from existing coverprofiles with Load.
+package covtest
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocov/convert"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+// nestedEnv is set in the environment of the tests run by Run, so that a
+// test calling Run on packages including its own is skipped rather than
+// run recursively.
+const nestedEnv = "GOCOV_COVTEST"
+
+// Coverage holds converted coverage for assertions.
+type Coverage struct {
+	Packages gocovutil.Packages
+}
+
+// New returns the Coverage of the given packages, as read from gocov's
+// JSON interchange format.
+func New(packages gocovutil.Packages) *Coverage {
+	return &Coverage{Packages: packages}
+}
+
+// Load converts coverprofiles, directories of them or directories of
+// binary coverage data, as "gocov convert" does, failing tb if they cannot
+// be converted.
+func Load(tb testing.TB, profiles ...string) *Coverage {
+	tb.Helper()
+	packages, err := convert.NewConverter().Packages(profiles...)
+	if err != nil {
+		tb.Fatalf("covtest: failed to convert coverage: %s", err)
+	}
+	return New(packages)
+}
+
+// Run runs "go test" with the given arguments, such as package patterns,
+// in the current directory, and converts the coverage it records. A
+// failure of the tests fails tb. Tests calling Run are skipped when they
+// are themselves run by Run.
+func Run(tb testing.TB, args ...string) *Coverage {
+	tb.Helper()
+	if os.Getenv(nestedEnv) != "" {
+		tb.Skip("covtest: skipped within covtest.Run")
+	}
+	profile := filepath.Join(tb.TempDir(), "coverage.out")
+	cmd := exec.Command("go", append([]string{"test", "-coverprofile=" + profile}, args...)...)
+	cmd.Env = append(os.Environ(), nestedEnv+"=1")
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		tb.Fatalf("covtest: go test %s: %s\n%s", strings.Join(args, " "), err, output.String())
+	}
+	return Load(tb, profile)
+}
+
+// Counts returns the number of statements reached and in total in the
+// packages matching the import path pattern, in which "..." matches any
+// string as for the go tool.
+func (c *Coverage) Counts(pattern string) (reached, total int) {
+	match := convert.MatchPattern(pattern)
+	for _, pkg := range c.Packages {
+		if !match(pkg.Name) {
+			continue
+		}
+		for _, fn := range pkg.Functions {
+			for _, stmt := range fn.Statements {
+				total++
+				if stmt.Reached > 0 {
+					reached++
+				}
+			}
+		}
+	}
+	return reached, total
+}
+
+// AtLeast fails tb unless the statement coverage of the packages matching
+// the import path pattern is at least min percent.
+func (c *Coverage) AtLeast(tb testing.TB, pattern string, min float64) {
+	tb.Helper()
+	reached, total := c.Counts(pattern)
+	if total == 0 {
+		tb.Errorf("covtest: no statements in packages matching %s", pattern)
+		return
+	}
+	if p := gocovutil.ExactPercentage(reached, total); p.Cmp(gocovutil.ExactDecimal(min)) < 0 {
+		tb.Errorf("covtest: %s: coverage %s%% (%d/%d) is below %.2f%%", pattern, gocovutil.FormatDecimal(p, 2, "."), reached, total, min)
+	}
+}
+
+// Covered fails tb unless the named function of the package was reached.
+// Functions are named as in gocov's output, e.g. "Parse" or "Parser.Next".
+func (c *Coverage) Covered(tb testing.TB, pkgpath, function string) {
+	tb.Helper()
+	fn := c.function(pkgpath, function)
+	if fn == nil {
+		tb.Errorf("covtest: no function %s in the coverage of %s", function, pkgpath)
+		return
+	}
+	for _, stmt := range fn.Statements {
+		if stmt.Reached > 0 {
+			return
+		}
+	}
+	tb.Errorf("covtest: %s.%s was not covered", pkgpath, function)
+}
+
+// function returns the named function of the package, or nil.
+func (c *Coverage) function(pkgpath, name string) *gocov.Function {
+	for _, pkg := range c.Packages {
+		if pkg.Name != pkgpath {
+			continue
+		}
+		for _, fn := range pkg.Functions {
+			if fn.Name == name {
+				return fn
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package covtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder is a testing.TB recording the failures reported to it.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func function(name string, reached ...int64) *gocov.Function {
+	fn := &gocov.Function{Name: name}
+	for _, n := range reached {
+		fn.Statements = append(fn.Statements, &gocov.Statement{Reached: n})
+	}
+	return fn
+}
+
+func TestCoverage(t *testing.T) {
+	cov := New(gocovutil.Packages{
+		{Name: "example.com/p", Functions: []*gocov.Function{function("F", 1, 0), function("T.M", 0)}},
+		{Name: "example.com/p/q", Functions: []*gocov.Function{function("G", 2, 3, 0)}},
+	})
+	reached, total := cov.Counts("example.com/p/...")
+	assert.Equal(t, 3, reached)
+	assert.Equal(t, 6, total)
+
+	r := &recorder{TB: t}
+	cov.AtLeast(r, "example.com/p/...", 50)
+	cov.AtLeast(r, "example.com/p/q", 66)
+	cov.Covered(r, "example.com/p", "F")
+	assert.Empty(t, r.errors)
+
+	cov.AtLeast(r, "example.com/p", 50)
+	cov.AtLeast(r, "example.com/r", 0)
+	cov.Covered(r, "example.com/p", "T.M")
+	cov.Covered(r, "example.com/p", "Missing")
+	assert.Equal(t, []string{
+		"covtest: example.com/p: coverage 33.33% (1/3) is below 50.00%",
+		"covtest: no statements in packages matching example.com/r",
+		"covtest: example.com/p.T.M was not covered",
+		"covtest: no function Missing in the coverage of example.com/p",
+	}, r.errors)
+
+	// 57 of 100 statements are exactly 57%.
+	reachedCounts := make([]int64, 100)
+	for i := 0; i < 57; i++ {
+		reachedCounts[i] = 1
+	}
+	cov = New(gocovutil.Packages{{Name: "example.com/s", Functions: []*gocov.Function{function("H", reachedCounts...)}}})
+	r = &recorder{TB: t}
+	cov.AtLeast(r, "example.com/s", 57)
+	assert.Empty(t, r.errors)
+}
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	cov := Run(t, "../internal/testflag")
+	cov.AtLeast(t, "github.com/hihoak/gocov/gocov/internal/testflag", 1)
+}
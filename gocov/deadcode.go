@@ -0,0 +1,193 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hihoak/gocov"
+	"golang.org/x/tools/go/callgraph/cha"
+	goPackages "golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// funcKey identifies a function declaration by file and offset.
+type funcKey struct {
+	file   string
+	offset int
+}
+
+// isExportedFunction reports whether fn is an exported function or
+// method; function literals are never exported.
+func isExportedFunction(fn *gocov.Function) bool {
+	name := fn.Name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return ast.IsExported(name)
+}
+
+// unreached reports whether none of the function's statements was reached.
+func unreached(fn *gocov.Function) bool {
+	for _, stmt := range fn.Statements {
+		if stmt.Reached > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// referencedFunctions loads the named packages in dir, or the current
+// directory if dir is empty, and returns the set of function declarations
+// that are called or referenced as values from elsewhere in those
+// packages.
+//
+// Calls are found using class hierarchy analysis, so a method is
+// considered referenced if any interface method call might dispatch to
+// it. Calls through function values are ignored, since CHA assumes they
+// may reach every function with a matching signature; functions used as
+// values are instead found by scanning instruction operands.
+func referencedFunctions(dir string, pkgNames []string) (map[funcKey]bool, error) {
+	pkgs, err := goPackages.Load(&goPackages.Config{
+		Mode: goPackages.LoadAllSyntax,
+		Dir:  dir,
+	}, pkgNames...)
+	if err != nil {
+		return nil, fmt.Errorf("load packages: %v", err)
+	}
+	prog, ssaPkgs := ssautil.Packages(pkgs, ssa.InstantiateGenerics)
+	for i, ssaPkg := range ssaPkgs {
+		if ssaPkg == nil {
+			return nil, fmt.Errorf("failed to analyze package %s: %v", pkgs[i].PkgPath, pkgs[i].Errors)
+		}
+	}
+	prog.Build()
+
+	key := func(fn *ssa.Function) (funcKey, bool) {
+		// Instances of generic functions are keyed by the function
+		// they were instantiated from.
+		if origin := fn.Origin(); origin != nil {
+			fn = origin
+		}
+		// Only declared functions have objects. Once built, functions
+		// keep only the extent of their syntax, which starts where the
+		// declaration does.
+		if fn.Object() == nil || fn.Synthetic != "" || fn.Syntax() == nil {
+			return funcKey{}, false
+		}
+		pos := prog.Fset.Position(fn.Syntax().Pos())
+		return funcKey{pos.Filename, pos.Offset}, true
+	}
+
+	referenced := make(map[funcKey]bool)
+	for _, node := range cha.CallGraph(prog).Nodes {
+		if node.Func == nil {
+			continue
+		}
+		k, ok := key(node.Func)
+		if !ok {
+			continue
+		}
+		for _, edge := range node.In {
+			common := edge.Site.Common()
+			if common.StaticCallee() == nil && !common.IsInvoke() {
+				continue
+			}
+			if edge.Caller.Func != node.Func {
+				referenced[k] = true
+				break
+			}
+		}
+	}
+	for fn := range ssautil.AllFunctions(prog) {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				var rands []*ssa.Value
+				if call, ok := instr.(ssa.CallInstruction); ok {
+					// Skip the callee itself; only arguments are values.
+					rands = call.Common().Operands(rands)[1:]
+				} else {
+					rands = instr.Operands(rands)
+				}
+				for _, rand := range rands {
+					if rand == nil {
+						continue
+					}
+					if value, ok := (*rand).(*ssa.Function); ok && value != fn {
+						if k, ok := key(value); ok {
+							referenced[k] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return referenced, nil
+}
+
+// printDeadCode lists the exported functions of the report that were not
+// reached and are not referenced from within the reported packages. These
+// are candidates for deletion, though they may still be used by code
+// outside of the report, such as importers of a library. Packages of
+// languages other than Go are skipped. The packages are loaded in dir, as
+// by referencedFunctions.
+func printDeadCode(w io.Writer, r *report, dir string) error {
+	var pkgNames []string
+	for _, pkg := range r.packages {
+		if pkg.IsGo() {
+			pkgNames = append(pkgNames, pkg.Name)
+		}
+	}
+	if len(pkgNames) == 0 {
+		return nil
+	}
+	referenced, err := referencedFunctions(dir, pkgNames)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 0, '\t', 0)
+	for _, pkg := range r.packages {
+		if !pkg.IsGo() {
+			continue
+		}
+		functions := make(functionList, len(pkg.Functions))
+		copy(functions, pkg.Functions)
+		sort.Sort(functions)
+		for _, fn := range functions {
+			if !isExportedFunction(fn) || !unreached(fn) {
+				continue
+			}
+			if referenced[funcKey{fn.File, fn.Start}] {
+				continue
+			}
+			fmt.Fprintf(tw, "%s/%s\t %s\n", pkg.Name, filepath.Base(fn.File), fn.Name)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestPrintDeadCode(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := `package p
+
+type Greeter interface{ Greet() string }
+
+type English struct{}
+
+func (English) Greet() string { return "hello" }
+
+func Greet(g Greeter) string { return g.Greet() }
+
+func Identity[T any](v T) T { return v }
+
+func helper() string { return Identity(Greet(English{})) }
+
+func Used() string { return helper() }
+
+func Unused() {}
+
+func Callback() {}
+
+var callbacks = []func(){Callback}
+
+func Covered() {}
+`
+	files := map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.18\n",
+		"p/p.go": src,
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	filename := filepath.Join(dir, "p", "p.go")
+	fn := func(name, decl string, reached int64) *gocov.Function {
+		return &gocov.Function{Name: name, File: filename, Start: strings.Index(src, decl),
+			Statements: []*gocov.Statement{{Reached: reached}}}
+	}
+	r := newReport()
+	r.addPackage(&gocov.Package{Name: "example.com/m/p", Functions: []*gocov.Function{
+		fn("English.Greet", "func (English) Greet", 0),
+		fn("Greet", "func Greet", 0),
+		fn("Identity", "func Identity", 0),
+		fn("helper", "func helper", 0),
+		fn("Used", "func Used", 0),
+		fn("Unused", "func Unused", 0),
+		fn("Callback", "func Callback", 0),
+		fn("Covered", "func Covered", 1),
+	}})
+	var buf bytes.Buffer
+	if err := printDeadCode(&buf, r, dir); err != nil {
+		t.Fatal(err)
+	}
+	want := "example.com/m/p/p.go\t Unused\nexample.com/m/p/p.go\t Used\n"
+	if buf.String() != want {
+		t.Errorf("printDeadCode = %q, want %q", buf.String(), want)
+	}
+}
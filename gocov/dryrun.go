@@ -0,0 +1,110 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// redacted replaces secrets in gocov's output.
+const redacted = "REDACTED"
+
+// sensitiveHeaders are the request headers whose values dry runs redact.
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Private-Token": true,
+}
+
+// dryRunTransport is the http.RoundTripper of the -dry-run flags of the
+// commands that change remote state. Requests that only read are sent,
+// so that a dry run plans against the actual state, while the others are
+// printed instead, with credentials and known secrets redacted, and
+// answered with an empty JSON object.
+type dryRunTransport struct {
+	w    io.Writer
+	next http.RoundTripper
+}
+
+// newDryRunClient returns a copy of the client that makes a dry run of
+// the requests changing remote state.
+func newDryRunClient(client *http.Client, w io.Writer) *http.Client {
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	dryRun := *client
+	dryRun.Transport = &dryRunTransport{w: w, next: next}
+	return &dryRun
+}
+
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == "GET" || req.Method == "HEAD" {
+		return t.next.RoundTrip(req)
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	u := *req.URL
+	if u.User != nil {
+		u.User = url.User(u.User.Username())
+	}
+	fmt.Fprintf(t.w, "dry run: %s %s\n", req.Method, redactSecrets(u.String()))
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := strings.Join(req.Header[name], ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			value = redacted
+		}
+		fmt.Fprintf(t.w, "%s: %s\n", name, redactSecrets(value))
+	}
+	if len(body) > 0 {
+		fmt.Fprintf(t.w, "\n%s\n", strings.TrimSuffix(redactSecrets(string(body)), "\n"))
+	}
+	fmt.Fprintln(t.w)
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte("{}"))),
+		ContentLength: 2,
+		Request:       req,
+	}, nil
+}
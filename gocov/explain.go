@@ -0,0 +1,237 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocov/convert"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var (
+	explainFlags      = flag.NewFlagSet("explain", flag.ExitOnError)
+	explainFlagValues = addConvertFlags(explainFlags)
+)
+
+// parseFileLine parses a file:line argument, making the file absolute.
+func parseFileLine(arg string) (string, int, error) {
+	i := strings.LastIndex(arg, ":")
+	if i <= 0 {
+		return "", 0, fmt.Errorf("invalid location %q, expected file:line", arg)
+	}
+	line, err := strconv.Atoi(arg[i+1:])
+	if err != nil || line < 1 {
+		return "", 0, fmt.Errorf("invalid location %q, expected file:line", arg)
+	}
+	file, err := filepath.Abs(arg[:i])
+	if err != nil {
+		return "", 0, err
+	}
+	return file, line, nil
+}
+
+// explainLine describes how the conversion of coverage treated a line of
+// a source file: the function enclosing it and that function's statements,
+// the profile blocks recorded for the file and the exclusions that applied
+// to it. Sources are read from fsys, or the operating system's file system
+// if fsys is nil.
+func explainLine(w io.Writer, fsys fs.FS, packages gocovutil.Packages, exclusions []convert.Exclusion, filename string, line int) error {
+	fmt.Fprintf(w, "%s:%d\n", filename, line)
+
+	found, enclosed := false, false
+	pkgName := filePackage(packages, filename)
+	for _, e := range exclusions {
+		if !excludesFile(e, pkgName, filename) {
+			continue
+		}
+		what := e.Package + "/" + e.File
+		if e.Function != "" {
+			what += ": " + e.Function
+		}
+		fmt.Fprintf(w, "excluded: %s: %s\n", what, e.Rule)
+		found = true
+	}
+
+	var file *token.File
+	position := func(offset int) token.Position {
+		if offset < 0 || offset > file.Size() {
+			return token.Position{}
+		}
+		return file.Position(file.Pos(offset))
+	}
+	for _, pkg := range packages {
+		for _, f := range pkg.Files {
+			if f.File != filename {
+				continue
+			}
+			found = true
+			var covering []*gocov.Block
+			for _, b := range f.Blocks {
+				if b.StartLine <= line && line <= b.EndLine {
+					covering = append(covering, b)
+				}
+			}
+			fmt.Fprintf(w, "profile: %d blocks in %s (mode %s), %d covering the line\n", len(f.Blocks), pkg.Name, f.Mode, len(covering))
+			for _, b := range covering {
+				fmt.Fprintf(w, "\t%d.%d,%d.%d %d statements, count %d\n", b.StartLine, b.StartCol, b.EndLine, b.EndCol, b.NumStmt, b.Count)
+			}
+		}
+		for _, fn := range pkg.Functions {
+			if fn.File != filename {
+				continue
+			}
+			found = true
+			if file == nil {
+				src, err := gocovutil.ReadSource(fsys, filename)
+				if err != nil {
+					return err
+				}
+				file = token.NewFileSet().AddFile(filename, -1, len(src))
+				file.SetLinesForContent(src)
+			}
+			start, end := position(fn.Start), position(fn.End)
+			if line < start.Line || line > end.Line || enclosedByClosure(pkg, fn, position, line) {
+				continue
+			}
+			enclosed = true
+			fmt.Fprintf(w, "function %s (%s, lines %d-%d):\n", fn.Name, pkg.Name, start.Line, end.Line)
+			if len(fn.Statements) == 0 {
+				fmt.Fprintln(w, "\tno statements")
+			}
+			for _, stmt := range fn.Statements {
+				s, e := position(stmt.Start), position(stmt.End)
+				marker := ""
+				if s.Line <= line && line <= e.Line {
+					marker = " <-"
+				}
+				fmt.Fprintf(w, "\t%d.%d,%d.%d reached %d%s\n", s.Line, s.Column, e.Line, e.Column, stmt.Reached, marker)
+			}
+		}
+	}
+	switch {
+	case !found:
+		fmt.Fprintln(w, "not in the converted coverage: the file is not in any profile, or its package was not resolved")
+	case file != nil && !enclosed:
+		fmt.Fprintln(w, "no function encloses the line")
+	}
+	return nil
+}
+
+// filePackage returns the name of the converted package whose sources
+// are in the directory of filename, or "" if none is.
+func filePackage(packages gocovutil.Packages, filename string) string {
+	dir := filepath.Dir(filename)
+	for _, pkg := range packages {
+		for _, fn := range pkg.Functions {
+			if filepath.Dir(fn.File) == dir {
+				return pkg.Name
+			}
+		}
+		for _, f := range pkg.Files {
+			if filepath.Dir(f.File) == dir {
+				return pkg.Name
+			}
+		}
+	}
+	return ""
+}
+
+// excludesFile reports whether the exclusion applies to filename, of the
+// package pkgName: whether it names the file's path, or its base name in
+// that package. If no converted package holds the file, as when all of
+// it was excluded, the package's last element must name the directory of
+// the file instead.
+func excludesFile(e convert.Exclusion, pkgName, filename string) bool {
+	if e.File != filepath.Base(filename) {
+		return false
+	}
+	if path.Join(e.Package, e.File) == filepath.ToSlash(filename) {
+		return true
+	}
+	if pkgName != "" {
+		return e.Package == pkgName
+	}
+	return path.Base(e.Package) == filepath.Base(filepath.Dir(filename))
+}
+
+// enclosedByClosure reports whether the line lies within a function
+// literal nested in fn, which is explained instead.
+func enclosedByClosure(pkg *gocov.Package, fn *gocov.Function, position func(int) token.Position, line int) bool {
+	for _, other := range pkg.Functions {
+		if other == fn || other.File != fn.File || other.Start < fn.Start || other.End > fn.End {
+			continue
+		}
+		if position(other.Start).Line <= line && line <= position(other.End).Line {
+			return true
+		}
+	}
+	return false
+}
+
+// explainCoverage converts the profiles as "gocov convert" would, and
+// explains the coverage of a line.
+func explainCoverage() (rc int) {
+	explainFlags.Parse(os.Args[2:])
+	if explainFlags.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, "usage: gocov explain [flags] file:line profile...")
+		return 2
+	}
+	filename, line, err := parseFileLine(explainFlags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+	opts, err := explainFlagValues.options()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	var excluded []convert.Exclusion
+	opts = append(opts, convert.WithBlocks(), convert.WithExclusionHandler(func(e convert.Exclusion) {
+		excluded = append(excluded, e)
+	}))
+	packages, err := convert.NewConverter(opts...).Packages(explainFlags.Args()[1:]...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		printResolveHint(os.Stderr, err)
+		return 1
+	}
+	if p, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = p
+	}
+	fsys := sourceFS(*explainFlagValues.sourceRoot)
+	if err := explainLine(os.Stdout, fsys, packages, excluded, filename, line); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
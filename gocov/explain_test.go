@@ -0,0 +1,93 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocov/convert"
+)
+
+func TestExplainLine(t *testing.T) {
+	src := "package p\n\nfunc f(x int) int {\n\tif x > 0 {\n\t\treturn x\n\t}\n\treturn 0\n}\n"
+	fsys := fstest.MapFS{"src/p/p.go": {Data: []byte(src)}}
+	offset := func(s string) int { return strings.Index(src, s) }
+	fn := &gocov.Function{Name: "f", File: "/src/p/p.go", Start: offset("func"), End: len(src) - 1}
+	fn.Statements = []*gocov.Statement{
+		{Start: offset("if"), End: offset(" {\n\t\t"), Reached: 1},
+		{Start: offset("return x"), End: offset("\n\t}"), Reached: 0},
+		{Start: offset("return 0"), End: offset("\n}"), Reached: 1},
+	}
+	pkg := &gocov.Package{Name: "p", Functions: []*gocov.Function{fn}}
+	pkg.Files = []*gocov.File{{File: "/src/p/p.go", Mode: "set", Blocks: []*gocov.Block{
+		{StartLine: 3, StartCol: 19, EndLine: 4, EndCol: 11, NumStmt: 1, Count: 1},
+		{StartLine: 4, StartCol: 11, EndLine: 6, EndCol: 3, NumStmt: 1},
+	}}}
+	exclusions := []convert.Exclusion{
+		{Package: "p", File: "p.go", Function: "g", Rule: "generated"},
+		{Package: "q", File: "q.go", Rule: "generated"},
+		{Package: "q", File: "p.go", Rule: "generated"},
+	}
+	var buf bytes.Buffer
+	if err := explainLine(&buf, fsys, []*gocov.Package{pkg}, exclusions, "/src/p/p.go", 5); err != nil {
+		t.Fatal(err)
+	}
+	expected := `/src/p/p.go:5
+excluded: p/p.go: g: generated
+profile: 2 blocks in p (mode set), 1 covering the line
+	4.11,6.3 1 statements, count 0
+function f (p, lines 3-8):
+	4.2,4.10 reached 1
+	5.3,5.11 reached 0 <-
+	7.2,7.10 reached 1
+`
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := explainLine(&buf, fsys, []*gocov.Package{pkg}, nil, "/src/p/p.go", 1); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), "no function encloses the line\n") {
+		t.Errorf("Expected no enclosing function, got:\n%s", buf.String())
+	}
+
+	// Files of packages excluded altogether are told apart by their
+	// directory, or matched by their full path.
+	exclusions = []convert.Exclusion{
+		{Package: "example.com/p", File: "p.go", Rule: "generated"},
+		{Package: "example.com/q", File: "p.go", Rule: "generated"},
+		{Package: "/src/p", File: "p.go", Rule: "source file not found"},
+	}
+	buf.Reset()
+	if err := explainLine(&buf, fsys, nil, exclusions, "/src/p/p.go", 5); err != nil {
+		t.Fatal(err)
+	}
+	expected = "/src/p/p.go:5\nexcluded: example.com/p/p.go: generated\nexcluded: /src/p/p.go: source file not found\n"
+	if !strings.HasPrefix(buf.String(), expected) {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
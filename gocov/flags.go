@@ -0,0 +1,88 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+// labelFlags collects repeated -label key=value flags.
+type labelFlags map[string]string
+
+func (l labelFlags) String() string {
+	return (&gocovutil.Document{Labels: l}).LabelString()
+}
+
+func (l labelFlags) Set(value string) error {
+	i := strings.Index(value, "=")
+	if i <= 0 {
+		return fmt.Errorf("label %q is not of the form key=value", value)
+	}
+	l[value[:i]] = value[i+1:]
+	return nil
+}
+
+// stringsFlag collects the values of a repeated flag.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// selectFlag collects -select flags, each a comma-separated list of
+// key=value labels that a document must carry to be selected.
+type selectFlag struct {
+	labels labelFlags
+}
+
+func (s *selectFlag) String() string {
+	return s.labels.String()
+}
+
+func (s *selectFlag) Set(value string) error {
+	if s.labels == nil {
+		s.labels = make(labelFlags)
+	}
+	for _, pair := range strings.Split(value, ",") {
+		if err := s.labels.Set(pair); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selects reports whether a document with the given labels is selected.
+func (s *selectFlag) selects(labels map[string]string) bool {
+	for k, v := range s.labels {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
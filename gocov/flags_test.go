@@ -0,0 +1,44 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestSelectFlag(t *testing.T) {
+	var s selectFlag
+	if !s.selects(nil) {
+		t.Errorf("Expected an empty selection to select everything")
+	}
+	if err := s.Set("suite=integration,team=payments"); err != nil {
+		t.Fatal(err)
+	}
+	if !s.selects(map[string]string{"suite": "integration", "team": "payments", "os": "linux"}) {
+		t.Errorf("Expected matching labels to be selected")
+	}
+	if s.selects(map[string]string{"suite": "integration"}) {
+		t.Errorf("Expected partially matching labels not to be selected")
+	}
+	if err := s.Set("suite"); err == nil {
+		t.Errorf("Expected an error for a malformed selection")
+	}
+}
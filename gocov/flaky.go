@@ -0,0 +1,76 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/hihoak/gocov"
+)
+
+// runsReaching returns the number of the n inputs that reached the
+// statement.
+func runsReaching(stmt *gocov.Statement, n int) int {
+	runs := 0
+	for i := 0; i < n; i++ {
+		if stmt.AttributedTo(i) {
+			runs++
+		}
+	}
+	return runs
+}
+
+// printFlaky prints the statements reached by some, but not all, of the
+// inputs of an attributed merge. When the inputs are repeated runs of the
+// same tests, such statements point to nondeterministic code paths or
+// flaky tests.
+func printFlaky(w io.Writer, r *report) error {
+	if len(r.inputs) < 2 {
+		return fmt.Errorf("flaky coverage requires attributed coverage of at least two runs (see gocov merge -attribute)")
+	}
+	sources := newSourceFiles()
+	sources.preload(r.packages)
+	tw := tabwriter.NewWriter(w, 0, 8, 0, '\t', 0)
+	for _, pkg := range r.packages {
+		functions := make(functionList, len(pkg.Functions))
+		copy(functions, pkg.Functions)
+		sort.Sort(functions)
+		for _, fn := range functions {
+			for _, stmt := range fn.Statements {
+				runs := runsReaching(stmt, len(r.inputs))
+				if runs == 0 || runs == len(r.inputs) {
+					continue
+				}
+				pos, err := sources.position(fn.File, stmt.Start)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(tw, "%s/%s:%d\t %s\t %d/%d runs\n",
+					pkg.Name, filepath.Base(fn.File), pos.Line, fn.Name, runs, len(r.inputs))
+			}
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var (
+	gerritFlags   = flag.NewFlagSet("gerrit", flag.ExitOnError)
+	gerritURLFlag = gerritFlags.String(
+		"url", "",
+		"Base URL of the Gerrit server, optionally with user:password credentials; environment variables are expanded")
+	gerritChangeFlag = gerritFlags.String(
+		"change", os.Getenv("GERRIT_CHANGE_NUMBER"),
+		"The change to comment on; defaults to $GERRIT_CHANGE_NUMBER")
+	gerritRevisionFlag = gerritFlags.String(
+		"revision", envOr("GERRIT_PATCHSET_REVISION", "current"),
+		"The revision of the change to comment on; defaults to $GERRIT_PATCHSET_REVISION")
+	gerritBaseFlag = gerritFlags.String(
+		"base", "HEAD~1",
+		"The git revision the change is diffed against")
+	gerritDryRunFlag = gerritFlags.Bool(
+		"dry-run", false,
+		"Print the request posting the review instead of sending it")
+	gerritClientOptions = clientFlags(gerritFlags)
+)
+
+// envOr returns the value of the environment variable, or def if it is
+// empty.
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// gerritReview is the ReviewInput posted to a revision of a change.
+type gerritReview struct {
+	Message       string                          `json:"message"`
+	Tag           string                          `json:"tag"`
+	RobotComments map[string][]gerritRobotComment `json:"robot_comments,omitempty"`
+}
+
+// gerritRobotComment is a RobotCommentInput on a line of a file.
+type gerritRobotComment struct {
+	RobotID    string `json:"robot_id"`
+	RobotRunID string `json:"robot_run_id"`
+	Line       int    `json:"line"`
+	Message    string `json:"message"`
+}
+
+// newGerritReview returns a review summarizing the diff coverage of a
+// change, with a robot comment on each uncovered line.
+func newGerritReview(reached, total int, missed []diffLine, runID string) *gerritReview {
+	review := &gerritReview{
+		Message: fmt.Sprintf("gocov: diff coverage %s (%d/%d)", formatPercentage(reached, total), reached, total),
+		// Gerrit hides autogenerated messages on request and replaces
+		// them on later patch sets.
+		Tag: "autogenerated:gocov",
+	}
+	if total == 0 {
+		review.Message = "gocov: no changed statements"
+	}
+	for _, m := range missed {
+		if review.RobotComments == nil {
+			review.RobotComments = make(map[string][]gerritRobotComment)
+		}
+		review.RobotComments[m.file] = append(review.RobotComments[m.file], gerritRobotComment{
+			RobotID:    "gocov",
+			RobotRunID: runID,
+			Line:       m.line,
+			Message:    "This line is not covered by tests.",
+		})
+	}
+	return review
+}
+
+// postGerritReview posts the review to a revision of a change. Requests
+// are authenticated, using the /a/ prefix, if rawurl carries credentials.
+func postGerritReview(client *http.Client, rawurl, change, revision string, review *gerritReview) error {
+	u, err := url.Parse(expandURL(rawurl))
+	if err != nil {
+		return err
+	}
+	var user, password string
+	if u.User != nil {
+		user = u.User.Username()
+		password, _ = u.User.Password()
+		u.User = nil
+	}
+	base := strings.TrimSuffix(u.String(), "/")
+	if user != "" {
+		base += "/a"
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		return err
+	}
+	path := "/changes/" + url.PathEscape(change) + "/revisions/" + url.PathEscape(revision) + "/review"
+	req, err := http.NewRequest("POST", base+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if user != "" {
+		req.SetBasicAuth(user, password)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("gerrit POST %s: %s", path, resp.Status)
+	}
+	return nil
+}
+
+// gerritCoverage posts robot comments on the lines of a Gerrit change
+// that its tests do not cover.
+func gerritCoverage() (rc int) {
+	gerritFlags.Parse(os.Args[2:])
+	if *gerritURLFlag == "" || *gerritChangeFlag == "" {
+		fmt.Fprintln(os.Stderr, "missing -url or -change")
+		return 1
+	}
+	filename := "-"
+	if gerritFlags.NArg() > 0 {
+		filename = gerritFlags.Arg(0)
+	}
+	doc, err := gocovutil.ReadDocument(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read coverage file (%s): %s\n", filename, err)
+		return 1
+	}
+	root, err := git("", "rev-parse", "--show-toplevel")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	diff, err := git(root, "diff", "--no-color", "--no-ext-diff", "-U0", "--diff-filter=AM", *gerritBaseFlag, "--", "*.go")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	added, err := addedLines(strings.NewReader(diff))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	reached, total, missed, err := diffCoverage(added, root, lineCoverage(doc.Packages))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	review := newGerritReview(reached, total, missed, *gerritRevisionFlag)
+	client, err := newHTTPClient(gerritClientOptions)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	if *gerritDryRunFlag {
+		client = newDryRunClient(client, os.Stdout)
+	}
+	if err := postGerritReview(client, *gerritURLFlag, *gerritChangeFlag, *gerritRevisionFlag, review); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to post review: %s\n", redactError(err))
+		return 1
+	}
+	return 0
+}
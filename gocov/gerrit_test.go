@@ -0,0 +1,74 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPostGerritReview(t *testing.T) {
+	var got gerritReview
+	var path, user, password string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		user, password, _ = r.BasicAuth()
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	review := newGerritReview(1, 3, []diffLine{{"p/a.go", 11}, {"p/a.go", 12}}, "run")
+	u := strings.Replace(server.URL, "://", "://bot:secret@", 1)
+	if err := postGerritReview(server.Client(), u, "123", "abc", review); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/a/changes/123/revisions/abc/review" || user != "bot" || password != "secret" {
+		t.Errorf("Unexpected request to %s as %s:%s", path, user, password)
+	}
+	if got.Message != "gocov: diff coverage 33.33% (1/3)" || got.Tag != "autogenerated:gocov" {
+		t.Errorf("Unexpected review %+v", got)
+	}
+	want := []gerritRobotComment{
+		{RobotID: "gocov", RobotRunID: "run", Line: 11, Message: "This line is not covered by tests."},
+		{RobotID: "gocov", RobotRunID: "run", Line: 12, Message: "This line is not covered by tests."},
+	}
+	if !reflect.DeepEqual(got.RobotComments, map[string][]gerritRobotComment{"p/a.go": want}) {
+		t.Errorf("Unexpected robot comments %+v", got.RobotComments)
+	}
+
+	// A dry run prints the request, as those of other targets do.
+	var buf bytes.Buffer
+	review = newGerritReview(0, 0, nil, "run")
+	if err := postGerritReview(newDryRunClient(server.Client(), &buf), u, "123", "abc", review); err != nil {
+		t.Fatal(err)
+	}
+	expected := "dry run: POST " + server.URL + "/a/changes/123/revisions/abc/review\n" +
+		"Authorization: REDACTED\nContent-Type: application/json\n\n" +
+		`{"message":"gocov: no changed statements","tag":"autogenerated:gocov"}` + "\n\n"
+	if buf.String() != expected {
+		t.Errorf("Dry run printed:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocov/convert"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+// ungroupedName names the group of the packages that no configured group
+// holds.
+const ungroupedName = "(ungrouped)"
+
+// packageGroups maps the names of package groups, such as the teams
+// owning them, to the import path patterns of their packages. Patterns
+// are as for go list: "..." matches any string, and a trailing "/..."
+// also matches the path before it.
+type packageGroups map[string][]string
+
+// matcher returns a function returning the sorted names of the groups
+// holding a package. The patterns are compiled once, for all packages.
+func (g packageGroups) matcher() func(pkg string) []string {
+	matches := make(map[string][]func(string) bool, len(g))
+	for name, patterns := range g {
+		for _, pattern := range patterns {
+			matches[name] = append(matches[name], convert.MatchPattern(pattern))
+		}
+	}
+	return func(pkg string) []string {
+		var names []string
+		for name, match := range matches {
+			for _, m := range match {
+				if m(pkg) {
+					names = append(names, name)
+					break
+				}
+			}
+		}
+		sort.Strings(names)
+		return names
+	}
+}
+
+// groupPackages combines the packages into one per group, named after the
+// group, sorted by name and followed by the packages of no group as
+// ungroupedName. A package held by several groups counts towards each.
+// The group packages share their functions with the packages combined,
+// and have the combinedLanguage of them.
+func (g packageGroups) groupPackages(packages []*gocov.Package) []*gocov.Package {
+	byName := make(map[string]*gocov.Package)
+	members := make(map[*gocov.Package][]*gocov.Package)
+	var grouped []*gocov.Package
+	var ungrouped *gocov.Package
+	groups := g.matcher()
+	for _, pkg := range packages {
+		names := groups(pkg.Name)
+		if len(names) == 0 {
+			if ungrouped == nil {
+				ungrouped = &gocov.Package{Name: ungroupedName}
+			}
+			ungrouped.Functions = append(ungrouped.Functions, pkg.Functions...)
+			members[ungrouped] = append(members[ungrouped], pkg)
+			continue
+		}
+		for _, name := range names {
+			group, ok := byName[name]
+			if !ok {
+				group = &gocov.Package{Name: name}
+				byName[name] = group
+				grouped = append(grouped, group)
+			}
+			group.Functions = append(group.Functions, pkg.Functions...)
+			members[group] = append(members[group], pkg)
+		}
+	}
+	for group, pkgs := range members {
+		group.Language = combinedLanguage(pkgs)
+	}
+	sort.Slice(grouped, func(i, j int) bool {
+		return grouped[i].Name < grouped[j].Name
+	})
+	if ungrouped != nil {
+		grouped = append(grouped, ungrouped)
+	}
+	return grouped
+}
+
+// groupDocument returns a copy of the document with its packages
+// combined into groups by groupPackages.
+func (g packageGroups) groupDocument(doc *gocovutil.Document) *gocovutil.Document {
+	grouped := *doc
+	grouped.Packages = g.groupPackages(doc.Packages)
+	return &grouped
+}
+
+// weights returns the weight of a package in the weighted total coverage:
+// the largest weight of the groups holding it, or 1 if no weighted group
+// does.
+func (g packageGroups) weights(weights map[string]float64) (func(pkg string) float64, error) {
+	for name, weight := range weights {
+		if _, ok := g[name]; !ok && name != ungroupedName {
+			return nil, fmt.Errorf("weight of unknown package group %q", name)
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("negative weight of package group %q", name)
+		}
+	}
+	groups := g.matcher()
+	return func(pkg string) float64 {
+		names := groups(pkg)
+		if len(names) == 0 {
+			names = []string{ungroupedName}
+		}
+		max := -1.0
+		for _, name := range names {
+			if weight, ok := weights[name]; ok && weight > max {
+				max = weight
+			}
+		}
+		if max < 0 {
+			return 1
+		}
+		return max
+	}, nil
+}
+
+// weightedCounts returns the reached and total statements of the
+// packages, each counted as many times as its weight, in thousandths.
+func weightedCounts(packages []*gocov.Package, weight func(pkg string) float64) (reached, total int) {
+	for _, pkg := range packages {
+		r, t := coverageCounts(pkg)
+		w := int(math.Round(weight(pkg.Name) * 1000))
+		reached += r * w
+		total += t * w
+	}
+	return reached, total
+}
+
+// loadGroups returns the package groups of the named configuration file,
+// which must define some.
+func loadGroups(filename string) (packageGroups, error) {
+	cfg, err := loadConfig(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration: %v", err)
+	}
+	if len(cfg.Groups) == 0 {
+		return nil, fmt.Errorf("no package groups configured in %s", filename)
+	}
+	return cfg.Groups, nil
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+func TestGroupWeights(t *testing.T) {
+	packages := []*gocov.Package{
+		{Name: "example.com/core", Functions: []*gocov.Function{{Statements: []*gocov.Statement{{Reached: 1}, {Reached: 1}}}}},
+		{Name: "example.com/tools", Functions: []*gocov.Function{{Statements: []*gocov.Statement{{}, {}}}}},
+		{Name: "example.com/other", Functions: []*gocov.Function{{Statements: []*gocov.Statement{{Reached: 1}, {}}}}},
+	}
+	groups := packageGroups{"core": {"example.com/core"}, "tooling": {"example.com/tools"}}
+	weight, err := groups.weights(map[string]float64{"core": 2, "tooling": 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// (2*2 + 0.5*0 + 1*1) / (2*2 + 0.5*2 + 1*2)
+	if reached, total := weightedCounts(packages, weight); formatPercentage(reached, total) != "71.42%" {
+		t.Errorf("Weighted coverage %s (%d/%d), want 71.42%%", formatPercentage(reached, total), reached, total)
+	}
+	if _, err := groups.weights(map[string]float64{"payments": 2}); err == nil {
+		t.Error("Expected an error for the weight of an unknown group")
+	}
+}
+
+func TestGroupTotalCoverage(t *testing.T) {
+	// Package example.com/core is held by both groups, and counts once
+	// towards the total, weighted or not.
+	packages := func() []*gocov.Package {
+		return []*gocov.Package{
+			{Name: "example.com/core", Functions: []*gocov.Function{{Statements: []*gocov.Statement{{Reached: 1}, {Reached: 1}}}}},
+			{Name: "example.com/tools", Functions: []*gocov.Function{{Statements: []*gocov.Statement{{}, {}}}}},
+		}
+	}
+	groups := packageGroups{"core": {"example.com/core"}, "all": {"example.com/..."}}
+	weight, err := groups.weights(map[string]float64{"core": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := func(byGroup bool) string {
+		r := newReport()
+		for _, pkg := range packages() {
+			r.addPackage(pkg)
+		}
+		r.weight = weight
+		if byGroup {
+			r.grouped = r.packages
+			r.packages = groups.groupPackages(r.packages)
+		}
+		var buf bytes.Buffer
+		r.printTotalCoverage(&buf, defaultPercentFormat)
+		return buf.String()
+	}
+	want := "Total Coverage: 50.00% (2/4)\nWeighted Coverage: 75.00%\nExported Coverage: 0.00% (0/0)\n"
+	if got := total(false); got != want {
+		t.Errorf("printTotalCoverage = %q, want %q", got, want)
+	}
+	if got := total(true); got != want {
+		t.Errorf("printTotalCoverage by group = %q, want %q", got, want)
+	}
+}
+
+func TestGroupPackages(t *testing.T) {
+	fn := func(reached ...int64) *gocov.Function {
+		fn := &gocov.Function{}
+		for _, r := range reached {
+			fn.Statements = append(fn.Statements, &gocov.Statement{Reached: r})
+		}
+		return fn
+	}
+	doc := &gocovutil.Document{Packages: gocovutil.Packages{
+		{Name: "example.com/payments", Functions: []*gocov.Function{fn(1, 0)}},
+		{Name: "example.com/payments/ledger", Functions: []*gocov.Function{fn(0, 0)}},
+		{Name: "example.com/platform/log", Functions: []*gocov.Function{fn(1)}},
+		{Name: "example.com/paymentsx", Functions: []*gocov.Function{fn(1)}},
+	}}
+	groups := packageGroups{
+		"payments": {"example.com/payments/..."},
+		"platform": {"example.com/platform/...", "example.com/payments/ledger"},
+	}
+	grouped := groups.groupDocument(doc)
+	var got []string
+	for _, pkg := range grouped.Packages {
+		reached, total := coverageCounts(pkg)
+		got = append(got, fmt.Sprintf("%s %d/%d", pkg.Name, reached, total))
+	}
+	want := []string{"payments 1/4", "platform 1/3", "(ungrouped) 1/1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupDocument = %q, want %q", got, want)
+	}
+	if len(doc.Packages) != 4 {
+		t.Errorf("Expected the document to be left alone, got %d packages", len(doc.Packages))
+	}
+	messages := thresholdViolations(grouped, map[string]float64{"payments": 50, "platform": 30})
+	if len(messages) != 1 || !strings.HasPrefix(messages[0], "payments: ") {
+		t.Errorf("Expected a violation for group payments, got %q", messages)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var (
+	harbormasterFlags   = flag.NewFlagSet("harbormaster", flag.ExitOnError)
+	harbormasterURLFlag = harbormasterFlags.String(
+		"url", "",
+		"Base URL of the Phabricator instance to send the results to with harbormaster.sendmessage; print them if empty")
+	harbormasterTokenFlag = harbormasterFlags.String(
+		"token", "env:CONDUIT_TOKEN",
+		"Conduit API token, as a secret reference: env:NAME, file:PATH or keychain:SERVICE")
+	harbormasterTargetFlag = harbormasterFlags.String(
+		"target", "",
+		"PHID of the build target to report to, e.g. ${target.phid}")
+	harbormasterTypeFlag = harbormasterFlags.String(
+		"type", "work",
+		"Message type: work to leave the build target running, pass or fail to finish it")
+	harbormasterDryRunFlag = harbormasterFlags.Bool(
+		"dry-run", false,
+		"Print the request to -url instead of sending it")
+	harbormasterClientOptions = clientFlags(harbormasterFlags)
+)
+
+// harbormasterUnit is a Harbormaster unit result.
+type harbormasterUnit struct {
+	Name   string `json:"name"`
+	Result string `json:"result"`
+
+	// Coverage maps file names, relative to the repository root, to a
+	// string with a character for each of the file's lines: C for a
+	// covered line, U for an uncovered one, and N for a line that is not
+	// executable.
+	Coverage map[string]string `json:"coverage"`
+}
+
+// harbormasterCoverage returns the coverage strings of the report's
+// source files. File names are made relative to root where they are
+// within it.
+func harbormasterCoverage(r *report, sources *sourceFiles, root string) (map[string]string, error) {
+	coverage := make(map[string]string)
+	sources.preload(r.packages)
+	for _, pkg := range r.packages {
+		for _, file := range pkg.SourceFiles() {
+			f, err := sources.file(file.File)
+			if err != nil {
+				return nil, err
+			}
+			lines := []byte(strings.Repeat("N", f.LineCount()))
+			for _, fn := range file.Functions {
+				for _, stmt := range fn.Statements {
+					start, err := sources.position(file.File, stmt.Start)
+					if err != nil {
+						return nil, err
+					}
+					end, err := sources.position(file.File, stmt.End)
+					if err != nil {
+						return nil, err
+					}
+					for line := start.Line; line <= end.Line; line++ {
+						switch {
+						case stmt.Reached > 0:
+							lines[line-1] = 'C'
+						case lines[line-1] == 'N':
+							lines[line-1] = 'U'
+						}
+					}
+				}
+			}
+			filename := file.File
+			if rel, err := filepath.Rel(root, filename); err == nil && !strings.HasPrefix(rel, "..") {
+				filename = filepath.ToSlash(rel)
+			}
+			coverage[filename] = string(lines)
+		}
+	}
+	return coverage, nil
+}
+
+// sendHarbormasterMessage reports the unit results to a build target with
+// the harbormaster.sendmessage Conduit method.
+func sendHarbormasterMessage(client *http.Client, base, token, target, messageType string, units []harbormasterUnit) error {
+	params, err := json.Marshal(map[string]interface{}{
+		"__conduit__":     map[string]string{"token": token},
+		"buildTargetPHID": target,
+		"type":            messageType,
+		"unit":            units,
+	})
+	if err != nil {
+		return err
+	}
+	form := url.Values{"params": {string(params)}, "output": {"json"}}.Encode()
+	endpoint := strings.TrimSuffix(expandURL(base), "/") + "/api/harbormaster.sendmessage"
+	req, err := http.NewRequest("POST", endpoint, strings.NewReader(form))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// Reporting the same results again is harmless.
+	req.Header.Set("Idempotency-Key", idempotencyKey(endpoint, []byte(form)))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("harbormaster.sendmessage: %s", resp.Status)
+	}
+	// Conduit reports errors in the body of successful responses.
+	var result struct {
+		ErrorCode string `json:"error_code"`
+		ErrorInfo string `json:"error_info"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if result.ErrorCode != "" {
+		return fmt.Errorf("harbormaster.sendmessage: %s: %s", result.ErrorCode, result.ErrorInfo)
+	}
+	return nil
+}
+
+// harbormasterResults reports coverage as Harbormaster unit results,
+// printing them or sending them to a build target.
+func harbormasterResults() (rc int) {
+	harbormasterFlags.Parse(os.Args[2:])
+	filename := "-"
+	if harbormasterFlags.NArg() > 0 {
+		filename = harbormasterFlags.Arg(0)
+	}
+	doc, err := gocovutil.ReadDocument(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read coverage file (%s): %s\n", filename, err)
+		return 1
+	}
+	r := newReport()
+	for _, pkg := range doc.Packages {
+		r.addPackage(pkg)
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	coverage, err := harbormasterCoverage(r, newSourceFiles(), root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to compute line coverage: %s\n", err)
+		return 1
+	}
+	units := []harbormasterUnit{{Name: "coverage", Result: "pass", Coverage: coverage}}
+	if *harbormasterURLFlag == "" {
+		if err := json.NewEncoder(os.Stdout).Encode(units); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write unit results: %s\n", err)
+			return 1
+		}
+		return 0
+	}
+	token, err := resolveSecret(*harbormasterTokenFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	if *harbormasterTargetFlag == "" || token == "" {
+		fmt.Fprintln(os.Stderr, "missing -target or -token")
+		return 1
+	}
+	client, err := newHTTPClient(harbormasterClientOptions)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	if *harbormasterDryRunFlag {
+		client = newDryRunClient(client, os.Stdout)
+	}
+	if err := sendHarbormasterMessage(client, *harbormasterURLFlag, token, *harbormasterTargetFlag, *harbormasterTypeFlag, units); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to send unit results: %s\n", redactError(err))
+		return 1
+	}
+	return 0
+}
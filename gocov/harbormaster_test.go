@@ -0,0 +1,90 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestHarbormasterCoverage(t *testing.T) {
+	src := "package p\n\nfunc f(x int) int {\n\tif x > 0 {\n\t\treturn x\n\t}\n\treturn 0\n}\n"
+	offset := func(s string) int { return strings.Index(src, s) }
+	r := newReport()
+	r.addPackage(&gocov.Package{Name: "p", Functions: []*gocov.Function{{
+		Name: "f", File: "/src/p/p.go",
+		Statements: []*gocov.Statement{
+			{Start: offset("if"), End: offset(" {\n\t\t"), Reached: 2},
+			{Start: offset("return x"), End: offset("\n\t}"), Reached: 0},
+			{Start: offset("return 0"), End: offset("\n}"), Reached: 2},
+		},
+	}}})
+	sources := &sourceFiles{
+		fset:  token.NewFileSet(),
+		files: make(map[string]*token.File),
+		fsys:  fstest.MapFS{"src/p/p.go": {Data: []byte(src)}},
+	}
+	coverage, err := harbormasterCoverage(r, sources, "/src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"p/p.go": "NNNCUNCN"}; !reflect.DeepEqual(coverage, want) {
+		t.Errorf("harbormasterCoverage = %v, want %v", coverage, want)
+	}
+}
+
+func TestSendHarbormasterMessage(t *testing.T) {
+	var params map[string]interface{}
+	response := `{"result":null,"error_code":null,"error_info":null}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/harbormaster.sendmessage" {
+			http.NotFound(w, r)
+			return
+		}
+		json.Unmarshal([]byte(r.FormValue("params")), &params)
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	units := []harbormasterUnit{{Name: "coverage", Result: "pass", Coverage: map[string]string{"p/p.go": "NCU"}}}
+	if err := sendHarbormasterMessage(server.Client(), server.URL, "api-token", "PHID-HMBT-1", "work", units); err != nil {
+		t.Fatal(err)
+	}
+	if params["buildTargetPHID"] != "PHID-HMBT-1" || params["type"] != "work" {
+		t.Errorf("Unexpected params %v", params)
+	}
+	if conduit, _ := params["__conduit__"].(map[string]interface{}); conduit["token"] != "api-token" {
+		t.Errorf("Unexpected conduit params %v", params["__conduit__"])
+	}
+
+	response = `{"result":null,"error_code":"ERR-INVALID-AUTH","error_info":"API token is invalid."}`
+	if err := sendHarbormasterMessage(server.Client(), server.URL, "bad", "PHID-HMBT-1", "work", units); err == nil || !strings.Contains(err.Error(), "ERR-INVALID-AUTH") {
+		t.Errorf("Expected a Conduit error, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// histogramBucket returns the bucket of the statement hit-count histogram
+// that a hit count falls in: 0 for statements never reached, 1 for those
+// reached exactly once, and k for those reached more than 10^(k-2) and at
+// most 10^(k-1) times.
+func histogramBucket(count int64) int {
+	if count <= 0 {
+		return 0
+	}
+	bucket := 1
+	for limit := int64(1); bucket < 19 && count > limit; limit *= 10 {
+		bucket++
+	}
+	return bucket
+}
+
+// histogramLabel describes the hit counts in a histogram bucket.
+func histogramLabel(bucket int) string {
+	switch bucket {
+	case 0:
+		return "0"
+	case 1:
+		return "1"
+	}
+	lo := int64(1)
+	for i := 2; i < bucket; i++ {
+		lo *= 10
+	}
+	return fmt.Sprintf("%d-%d", lo+1, lo*10)
+}
+
+// histogramBarWidth is the width of the bar drawn for the largest bucket.
+const histogramBarWidth = 40
+
+// printHistogram prints the distribution of statement hit counts, which
+// shows both paths that are barely exercised and loops that tests spin
+// far more often than needed.
+func printHistogram(w io.Writer, r *report) error {
+	var counts []int
+	var total int
+	for _, pkg := range r.packages {
+		for _, fn := range pkg.Functions {
+			for _, stmt := range fn.Statements {
+				bucket := histogramBucket(stmt.Reached)
+				for len(counts) <= bucket {
+					counts = append(counts, 0)
+				}
+				counts[bucket]++
+				total++
+			}
+		}
+	}
+	max := 0
+	for _, n := range counts {
+		if n > max {
+			max = n
+		}
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "Hits\tStatements\tPercent\t\n")
+	for bucket, n := range counts {
+		bar := strings.Repeat("#", (n*histogramBarWidth+max-1)/max)
+		fmt.Fprintf(tw, "%s\t%d\t%s\t %s\n", histogramLabel(bucket), n, formatPercentage(n, total), bar)
+	}
+	return tw.Flush()
+}
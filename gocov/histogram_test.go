@@ -0,0 +1,48 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import "testing"
+
+func TestHistogramBucket(t *testing.T) {
+	tests := []struct {
+		count  int64
+		bucket int
+		label  string
+	}{
+		{0, 0, "0"},
+		{1, 1, "1"},
+		{2, 2, "2-10"},
+		{10, 2, "2-10"},
+		{11, 3, "11-100"},
+		{10001, 6, "10001-100000"},
+	}
+	for _, test := range tests {
+		bucket := histogramBucket(test.count)
+		if bucket != test.bucket {
+			t.Errorf("histogramBucket(%d) = %d, want %d", test.count, bucket, test.bucket)
+			continue
+		}
+		if label := histogramLabel(bucket); label != test.label {
+			t.Errorf("histogramLabel(%d) = %q, want %q", bucket, label, test.label)
+		}
+	}
+}
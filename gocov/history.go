@@ -0,0 +1,269 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var (
+	historyFlags    = flag.NewFlagSet("history", flag.ExitOnError)
+	historyFileFlag = historyFlags.String(
+		"file", "gocov-history.json",
+		"The history file to update or read")
+	historyTimeFlag = historyFlags.String(
+		"time", "",
+		"The RFC 3339 time of the recorded run, or of the stale report; defaults to now")
+	historyDaysFlag = historyFlags.Int(
+		"days", 30,
+		"Report statements not covered by any recorded run in this many days")
+)
+
+// historyEntry records when a statement was seen and covered by recorded
+// runs. Statements are identified by the stable identity of their
+// function (see gocovutil.StableID) and their index within the function,
+// which survive checkouts moving, declarations being reordered and
+// unrelated code changing. Entries recorded before identities were have
+// no ID, and are identified by package, file base name and function.
+type historyEntry struct {
+	Package  string
+	File     string
+	Function string
+	Index    int
+	ID       string `json:",omitempty"`
+
+	FirstSeen   time.Time
+	LastSeen    time.Time
+	LastCovered *time.Time `json:",omitempty"`
+}
+
+func (e *historyEntry) key() string {
+	if e.ID != "" {
+		return historyKey(e.ID, e.Index)
+	}
+	return fmt.Sprintf("%s/%s:%s#%d", e.Package, e.File, e.Function, e.Index)
+}
+
+// historyKey returns the key of the entry for statement i of the function
+// of the given identity.
+func historyKey(id string, i int) string {
+	return fmt.Sprintf("%s[%d]", id, i)
+}
+
+// coverageHistory holds the entries of a history file, sorted by key.
+type coverageHistory struct {
+	Entries []*historyEntry
+}
+
+// readHistory reads the named history file; a missing file yields the
+// empty history.
+func readHistory(filename string) (*coverageHistory, error) {
+	h := &coverageHistory{}
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return h, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// record updates the history with a run's coverage at time now. The
+// entries of functions whose file was renamed, and those recorded before
+// identities were, are carried over to the functions' current identities.
+func (h *coverageHistory) record(doc *gocovutil.Document, now time.Time) {
+	byKey := make(map[string]*historyEntry, len(h.Entries))
+	var ids gocovutil.Identities
+	for _, e := range h.Entries {
+		byKey[e.key()] = e
+		if e.ID != "" {
+			ids.Add(e.ID)
+		}
+	}
+	for _, pkg := range doc.Packages {
+		for _, fn := range pkg.Functions {
+			id := gocovutil.StableID(pkg, fn)
+			recorded, ok := ids.Match(id)
+			for i, stmt := range fn.Statements {
+				e := &historyEntry{Package: pkg.Name, File: filepath.Base(fn.File), Function: fn.Name, Index: i}
+				var old *historyEntry
+				if ok {
+					old = byKey[historyKey(recorded, i)]
+				} else {
+					old = byKey[e.key()]
+				}
+				e.ID = id
+				if old != nil {
+					old.File, old.ID = e.File, e.ID
+					e = old
+				} else {
+					e.FirstSeen = now
+					byKey[e.key()] = e
+					h.Entries = append(h.Entries, e)
+				}
+				if e.LastSeen.Before(now) {
+					e.LastSeen = now
+				}
+				if stmt.Reached > 0 && (e.LastCovered == nil || e.LastCovered.Before(now)) {
+					covered := now
+					e.LastCovered = &covered
+				}
+			}
+		}
+	}
+	sort.Slice(h.Entries, func(i, j int) bool {
+		return h.Entries[i].key() < h.Entries[j].key()
+	})
+}
+
+// staleFunction summarizes the stale statements of a function.
+type staleFunction struct {
+	pkg, file, function string
+	statements          int
+
+	// lastCovered is the last time any of the statements was covered,
+	// or nil if none ever was.
+	lastCovered *time.Time
+}
+
+// stale returns the functions with statements seen in the latest run
+// recorded but not covered since the cutoff, in the order of the history.
+// Statements first seen after the cutoff have not had the chance to go
+// stale, and statements the latest run did not see have presumably been
+// deleted.
+func (h *coverageHistory) stale(cutoff time.Time) []*staleFunction {
+	var latest time.Time
+	for _, e := range h.Entries {
+		if e.LastSeen.After(latest) {
+			latest = e.LastSeen
+		}
+	}
+	var functions []*staleFunction
+	var last *staleFunction
+	for _, e := range h.Entries {
+		if e.LastSeen.Before(latest) || e.FirstSeen.After(cutoff) {
+			continue
+		}
+		if e.LastCovered != nil && !e.LastCovered.Before(cutoff) {
+			continue
+		}
+		if last == nil || last.pkg != e.Package || last.file != e.File || last.function != e.Function {
+			last = &staleFunction{pkg: e.Package, file: e.File, function: e.Function}
+			functions = append(functions, last)
+		}
+		last.statements++
+		if e.LastCovered != nil && (last.lastCovered == nil || last.lastCovered.Before(*e.LastCovered)) {
+			last.lastCovered = e.LastCovered
+		}
+	}
+	return functions
+}
+
+// printStale prints the stale functions, with the number of their stale
+// statements and when those were last covered.
+func printStale(w io.Writer, functions []*staleFunction) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 0, '\t', 0)
+	for _, fn := range functions {
+		since := "never covered"
+		if fn.lastCovered != nil {
+			since = "last covered " + fn.lastCovered.UTC().Format("2006-01-02")
+		}
+		fmt.Fprintf(tw, "%s/%s\t %s\t %d statements %s\n", fn.pkg, fn.file, fn.function, fn.statements, since)
+	}
+	return tw.Flush()
+}
+
+// writeHistory writes the history to the named file.
+func writeHistory(filename string, h *coverageHistory) error {
+	data, err := json.MarshalIndent(h, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(data, '\n'), 0644)
+}
+
+// historyCoverage runs the history subcommand named by the first argument:
+// "record" adds the coverage of a run to the history file, and "stale"
+// reports the code no recorded run has covered in -days days.
+func historyCoverage() (rc int) {
+	if len(os.Args) < 3 {
+		fmt.Fprintln(os.Stderr, "missing history subcommand: record or stale")
+		return 1
+	}
+	historyFlags.Parse(os.Args[3:])
+	now := time.Now()
+	if *historyTimeFlag != "" {
+		t, err := time.Parse(time.RFC3339, *historyTimeFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		now = t
+	}
+	h, err := readHistory(*historyFileFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read history (%s): %s\n", *historyFileFlag, err)
+		return 1
+	}
+	switch os.Args[2] {
+	case "record":
+		filenames := historyFlags.Args()
+		if len(filenames) == 0 {
+			filenames = []string{"-"}
+		}
+		for _, filename := range filenames {
+			doc, err := gocovutil.ReadDocument(filename)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to read coverage file (%s): %s\n", filename, err)
+				return 1
+			}
+			h.record(doc, now)
+		}
+		if err := writeHistory(*historyFileFlag, h); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write history (%s): %s\n", *historyFileFlag, err)
+			return 1
+		}
+		return 0
+	case "stale":
+		cutoff := now.AddDate(0, 0, -*historyDaysFlag)
+		if err := printStale(os.Stdout, h.stale(cutoff)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to print stale coverage: %s\n", err)
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "unknown history subcommand %q\n", os.Args[2])
+	return 1
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+func TestCoverageHistory(t *testing.T) {
+	doc := func(reached ...int64) *gocovutil.Document {
+		fn := &gocov.Function{Name: "F", File: "/src/p/p.go"}
+		for _, r := range reached {
+			fn.Statements = append(fn.Statements, &gocov.Statement{Reached: r})
+		}
+		return &gocovutil.Document{Packages: gocovutil.Packages{{Name: "p", Functions: []*gocov.Function{fn}}}}
+	}
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+	h := &coverageHistory{}
+	h.record(doc(1, 1, 0), day(1))
+	h.record(doc(1, 0, 0), day(20))
+	h.record(doc(1, 0, 0, 0), day(25))
+
+	// Of the statements seen by day 10, the second was last covered on
+	// day 1 and the third never; the fourth was only seen later.
+	var buf bytes.Buffer
+	if err := printStale(&buf, h.stale(day(10))); err != nil {
+		t.Fatal(err)
+	}
+	if want := "p/p.go\t F\t 2 statements last covered 2026-01-01\n"; buf.String() != want {
+		t.Errorf("printStale = %q, want %q", buf.String(), want)
+	}
+
+	// The run of day 28 no longer sees the third and fourth statements,
+	// which have presumably been deleted, and covers the first.
+	h.record(doc(1, 0), day(28))
+	buf.Reset()
+	if err := printStale(&buf, h.stale(day(10))); err != nil {
+		t.Fatal(err)
+	}
+	if want := "p/p.go\t F\t 1 statements last covered 2026-01-01\n"; buf.String() != want {
+		t.Errorf("printStale = %q, want %q", buf.String(), want)
+	}
+	if stale := h.stale(day(30)); len(stale) != 1 || stale[0].statements != 2 {
+		t.Errorf("Expected both statements seen on day 28 to be stale by day 30, got %d functions", len(stale))
+	}
+}
+
+func TestCoverageHistoryIdentity(t *testing.T) {
+	doc := func(file string, start int, reached int64) *gocovutil.Document {
+		fn := &gocov.Function{Name: "F", File: file, Start: start, End: start + 10,
+			ID:         gocovutil.FunctionID("p", filepath.Base(file), "F", "()"),
+			Statements: []*gocov.Statement{{Start: start + 5, End: start + 8, Reached: reached}}}
+		return &gocovutil.Document{Packages: gocovutil.Packages{{Name: "p", Functions: []*gocov.Function{fn}}}}
+	}
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	// An entry recorded before identities were is carried over, and so
+	// is the function's history when it is moved and its file renamed.
+	h := &coverageHistory{Entries: []*historyEntry{
+		{Package: "p", File: "p.go", Function: "F", FirstSeen: day(1), LastSeen: day(1)},
+	}}
+	h.record(doc("/src/p/p.go", 0, 0), day(2))
+	h.record(doc("/src/p/q.go", 100, 1), day(3))
+	if len(h.Entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(h.Entries))
+	}
+	e := h.Entries[0]
+	if want := gocovutil.FunctionID("p", "q.go", "F", "()"); e.ID != want || e.File != "q.go" {
+		t.Errorf("Expected entry of %s in q.go, got %s in %s", want, e.ID, e.File)
+	}
+	if !e.FirstSeen.Equal(day(1)) || !e.LastSeen.Equal(day(3)) || e.LastCovered == nil || !e.LastCovered.Equal(day(3)) {
+		t.Errorf("Expected entry first seen on day 1 and last seen and covered on day 3, got %+v", e)
+	}
+}
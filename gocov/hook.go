@@ -0,0 +1,262 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	hookFlags    = flag.NewFlagSet("hook", flag.ExitOnError)
+	hookTypeFlag = hookFlags.String(
+		"type", "pre-commit",
+		"The git hook to install: pre-commit or pre-push")
+	hookForceFlag = hookFlags.Bool(
+		"force", false,
+		"Replace an existing hook not installed by gocov")
+	hookBaseFlag = hookFlags.String(
+		"base", "",
+		"Check the changes since this git revision instead of the staged changes")
+	hookMinCoverageFlag = hookFlags.Float64(
+		"min-coverage", 80,
+		"Fail if fewer than this percentage of the changed statements are covered")
+	hookDryRunFlag = hookFlags.Bool(
+		"dry-run", false,
+		"Print the hook install would write instead of installing it")
+)
+
+// hookMarker identifies hooks installed by gocov.
+const hookMarker = `# Installed by "gocov hook install".`
+
+// hookScript returns the script of a git hook running "gocov hook run".
+func hookScript(hookType string, minCoverage float64) (string, error) {
+	args := fmt.Sprintf("-min-coverage %g", minCoverage)
+	switch hookType {
+	case "pre-commit":
+	case "pre-push":
+		args = "-base @{upstream} " + args
+	default:
+		return "", fmt.Errorf("unsupported hook type %q", hookType)
+	}
+	return fmt.Sprintf("#!/bin/sh\n%s\nexec gocov hook run %s\n", hookMarker, args), nil
+}
+
+// addedLines returns the numbers of the lines added by a unified diff
+// generated with -U0, by the name of the file they were added to.
+func addedLines(diff io.Reader) (map[string][]int, error) {
+	added := make(map[string][]int)
+	var name string
+	var lineno int
+	scanner := bufio.NewScanner(diff)
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			name = ""
+			if n := strings.TrimPrefix(line, "+++ "); strings.HasPrefix(n, "b/") {
+				name = n[2:]
+			}
+		case strings.HasPrefix(line, "@@ "):
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("malformed hunk header %q", line)
+			}
+			n, err := strconv.Atoi(strings.SplitN(strings.TrimPrefix(fields[2], "+"), ",", 2)[0])
+			if err != nil {
+				return nil, fmt.Errorf("malformed hunk header %q", line)
+			}
+			lineno = n
+		case strings.HasPrefix(line, "+") && name != "":
+			added[name] = append(added[name], lineno)
+			lineno++
+		case strings.HasPrefix(line, " "):
+			lineno++
+		}
+	}
+	return added, scanner.Err()
+}
+
+// diffLine is a line of a file changed by a diff, named relative to the
+// repository root with forward slashes.
+type diffLine struct {
+	file string
+	line int
+}
+
+func (l diffLine) String() string {
+	return fmt.Sprintf("%s:%d", l.file, l.line)
+}
+
+// diffCoverage counts the added lines starting statements, and those of
+// them reached, returning the uncovered lines. File names are relative to
+// root.
+func diffCoverage(added map[string][]int, root string, coverage func(string) (map[int]bool, error)) (reached, total int, missed []diffLine, err error) {
+	names := make([]string, 0, len(added))
+	for name := range added {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lines, err := coverage(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		for _, lineno := range added[name] {
+			hit, ok := lines[lineno]
+			if !ok {
+				continue
+			}
+			total++
+			if hit {
+				reached++
+			} else {
+				missed = append(missed, diffLine{name, lineno})
+			}
+		}
+	}
+	return reached, total, missed, nil
+}
+
+// installHook installs a git hook running "gocov hook run".
+func installHook() (rc int) {
+	script, err := hookScript(*hookTypeFlag, *hookMinCoverageFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	hooks, err := git("", "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	filename := filepath.Join(hooks, *hookTypeFlag)
+	if data, err := ioutil.ReadFile(filename); err == nil && !strings.Contains(string(data), hookMarker) && !*hookForceFlag {
+		fmt.Fprintf(os.Stderr, "%s already exists; use -force to replace it\n", filename)
+		return 1
+	}
+	if *hookDryRunFlag {
+		fmt.Printf("dry run: write %s\n\n%s", filename, script)
+		return 0
+	}
+	if err := os.MkdirAll(hooks, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to install hook: %s\n", err)
+		return 1
+	}
+	if err := ioutil.WriteFile(filename, []byte(script), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to install hook: %s\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "installed %s\n", filename)
+	return 0
+}
+
+// runHook tests the packages touched by the pending changes, failing if
+// too few of the changed statements are covered.
+func runHook() (rc int) {
+	root, err := git("", "rev-parse", "--show-toplevel")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	diffArgs := []string{"diff", "--no-color", "--no-ext-diff", "-U0", "--diff-filter=AM"}
+	if *hookBaseFlag != "" {
+		diffArgs = append(diffArgs, *hookBaseFlag)
+	} else {
+		diffArgs = append(diffArgs, "--cached")
+	}
+	diff, err := git(root, append(diffArgs, "--", "*.go")...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	added, err := addedLines(strings.NewReader(diff))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	if len(added) == 0 {
+		return 0
+	}
+
+	// Only the packages touched by the changes are tested.
+	seen := make(map[string]bool)
+	var pkgs []string
+	for name := range added {
+		pkg := "./" + path.Dir(name)
+		if !seen[pkg] {
+			seen[pkg] = true
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	sort.Strings(pkgs)
+	out, err := testCoverage(root, pkgs, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	doc, err := unmarshalDocument(out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to unmarshal coverage data: %s\n", err)
+		return 1
+	}
+	reached, total, missed, err := diffCoverage(added, root, lineCoverage(doc.Packages))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "diff coverage: %s (%d/%d)\n", formatPercentage(reached, total), reached, total)
+	if total > 0 && belowThreshold(reached, total, *hookMinCoverageFlag) {
+		for _, m := range missed {
+			fmt.Fprintln(os.Stderr, "not covered:", m)
+		}
+		fmt.Fprintf(os.Stderr, "diff coverage is below the minimum of %.2f%%\n", *hookMinCoverageFlag)
+		return exitThreshold
+	}
+	return 0
+}
+
+// hookCoverage runs the hook subcommand named by the first argument.
+func hookCoverage() (rc int) {
+	if len(os.Args) < 3 {
+		fmt.Fprintln(os.Stderr, "missing hook subcommand: install or run")
+		return 1
+	}
+	hookFlags.Parse(os.Args[3:])
+	switch os.Args[2] {
+	case "install":
+		return installHook()
+	case "run":
+		return runHook()
+	}
+	fmt.Fprintf(os.Stderr, "unknown hook subcommand %q\n", os.Args[2])
+	return 1
+}
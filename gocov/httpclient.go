@@ -0,0 +1,264 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultRetries is the number of times network integrations retry a
+	// request that failed transiently.
+	defaultRetries = 3
+
+	// defaultRetryBackoff is the delay before the first retry, doubling
+	// with each further retry.
+	defaultRetryBackoff = time.Second
+
+	// maxRetryBackoff caps the delay between retries.
+	maxRetryBackoff = 30 * time.Second
+
+	// defaultAttemptTimeout is the time each attempt of a request has
+	// to complete, its response body included.
+	defaultAttemptTimeout = time.Minute
+)
+
+// clientOptions configure the HTTP client of a network integration.
+type clientOptions struct {
+	// CACert names a PEM file of CA certificates to trust in addition to
+	// the system's, for endpoints with internal TLS.
+	CACert string `yaml:"ca-cert"`
+
+	// InsecureSkipVerify disables verification of the endpoint's
+	// certificate.
+	InsecureSkipVerify bool `yaml:"insecure-skip-verify"`
+
+	// Retries is the number of times a request that failed transiently
+	// is retried, defaultRetries if unset.
+	Retries *int `yaml:"retries"`
+
+	// RetryBackoff is the delay before the first retry, doubling with
+	// each further retry; defaultRetryBackoff if unset.
+	RetryBackoff time.Duration `yaml:"retry-backoff"`
+
+	// Timeout is the time each attempt of a request has to complete,
+	// defaultAttemptTimeout if unset.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// clientFlags registers the flags configuring the HTTP client of a
+// command with network integrations.
+func clientFlags(flags *flag.FlagSet) *clientOptions {
+	o := &clientOptions{Retries: new(int)}
+	flags.StringVar(&o.CACert, "ca-cert", "", "Also trust the CA certificates in this PEM `file` for TLS connections")
+	flags.BoolVar(&o.InsecureSkipVerify, "insecure-skip-verify", false, "Do not verify TLS certificates")
+	flags.IntVar(o.Retries, "retries", defaultRetries, "Retry requests that failed transiently this many times")
+	flags.DurationVar(&o.RetryBackoff, "retry-backoff", defaultRetryBackoff, "Delay before the first retry, doubling with each further retry")
+	flags.DurationVar(&o.Timeout, "timeout", defaultAttemptTimeout, "Time each attempt of a request has to complete, before it is retried")
+	return o
+}
+
+// tlsConfig returns the TLS configuration of the options, or nil for the
+// default configuration.
+func (o *clientOptions) tlsConfig() (*tls.Config, error) {
+	if o.CACert == "" && !o.InsecureSkipVerify {
+		return nil, nil
+	}
+	config := &tls.Config{InsecureSkipVerify: o.InsecureSkipVerify}
+	if o.CACert != "" {
+		pem, err := ioutil.ReadFile(o.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificates: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no CA certificates in %s", o.CACert)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// newHTTPClient returns the HTTP client of network integrations. It
+// connects through the proxy given by $HTTPS_PROXY or $HTTP_PROXY,
+// except to the hosts of $NO_PROXY, verifies TLS certificates and
+// retries requests as the options configure.
+func newHTTPClient(o *clientOptions) (*http.Client, error) {
+	config, err := o.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if config != nil {
+		transport.TLSClientConfig = config
+	}
+	retry := &retryTransport{next: transport, retries: defaultRetries, backoff: o.RetryBackoff, timeout: o.Timeout}
+	if o.Retries != nil {
+		retry.retries = *o.Retries
+	}
+	if retry.backoff <= 0 {
+		retry.backoff = defaultRetryBackoff
+	}
+	if retry.timeout <= 0 {
+		retry.timeout = defaultAttemptTimeout
+	}
+	// The timeouts apply to each attempt rather than to all of them,
+	// so that a hung attempt is retried rather than waited on forever.
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	return &http.Client{Transport: retry}, nil
+}
+
+// idempotencyKey returns the Idempotency-Key of a request, derived from
+// its endpoint and body, so that receivers honoring the header discard
+// the repeats of retries, including those of rerun CI jobs.
+func idempotencyKey(endpoint string, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, endpoint)
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// retryTransport retries requests that failed transiently: on network
+// errors and responses indicating a temporary failure, with exponential
+// backoff heeding Retry-After. Since a POST that failed may still have
+// taken effect, POST requests without an Idempotency-Key are only
+// retried if the server declined to process them.
+// Each attempt has timeout to complete, its response body included.
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+	backoff time.Duration
+	timeout time.Duration
+}
+
+// transientStatus reports whether a response status indicates a failure
+// that retrying may overcome.
+func transientStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// certificateError reports whether err is a failure to verify the server's
+// certificate, which retrying cannot overcome.
+func certificateError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalid x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	return errors.As(err, &unknownAuthority) || errors.As(err, &invalid) || errors.As(err, &hostname)
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	idempotent := req.Method != "POST" || req.Header.Get("Idempotency-Key") != ""
+	replayable := req.Body == nil || req.GetBody != nil
+	delay := t.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.attempt(req)
+		var retry bool
+		switch {
+		case err != nil:
+			retry = idempotent && !certificateError(err)
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+			retry = true
+		default:
+			retry = idempotent && transientStatus(resp.StatusCode)
+		}
+		if !retry || !replayable || attempt >= t.retries {
+			return resp, err
+		}
+		wait := delay
+		if err == nil {
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+				wait = time.Duration(seconds) * time.Second
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if wait > maxRetryBackoff {
+			wait = maxRetryBackoff
+		}
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		if delay *= 2; delay > maxRetryBackoff {
+			delay = maxRetryBackoff
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// attempt makes one attempt of a request, cancelled if it takes longer
+// than the transport's timeout.
+func (t *retryTransport) attempt(req *http.Request) (*http.Response, error) {
+	if t.timeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody is the body of a response, releasing the context of its
+// attempt when closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
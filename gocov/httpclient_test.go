@@ -0,0 +1,154 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	var requests int
+	failures := 0
+	status := http.StatusBadGateway
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if data, _ := ioutil.ReadAll(r.Body); string(data) != "body" {
+			t.Errorf("Unexpected body %q of attempt %d", data, requests)
+		}
+		if requests <= failures {
+			w.WriteHeader(status)
+		}
+	}))
+	defer server.Close()
+
+	retries := 3
+	client, err := newHTTPClient(&clientOptions{Retries: &retries, RetryBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	post := func(key string) int {
+		requests = 0
+		req, _ := http.NewRequest("POST", server.URL, strings.NewReader("body"))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	failures = 2
+	if code := post("key"); code != http.StatusOK || requests != 3 {
+		t.Errorf("Expected an idempotent POST to be retried, got %d after %d requests", code, requests)
+	}
+	if code := post(""); code != http.StatusBadGateway || requests != 1 {
+		t.Errorf("Expected a POST without an idempotency key not to be retried, got %d after %d requests", code, requests)
+	}
+	status = http.StatusServiceUnavailable
+	if code := post(""); code != http.StatusOK || requests != 3 {
+		t.Errorf("Expected a declined POST to be retried, got %d after %d requests", code, requests)
+	}
+	failures = 10
+	if code := post("key"); code != http.StatusServiceUnavailable || requests != 4 {
+		t.Errorf("Expected retries to give up, got %d after %d requests", code, requests)
+	}
+	if idempotencyKey("a", []byte("b")) != idempotencyKey("a", []byte("b")) || idempotencyKey("a", []byte("b")) == idempotencyKey("a", []byte("c")) {
+		t.Error("Expected idempotency keys to be derived from the request")
+	}
+}
+
+func TestRetryTransportTimeout(t *testing.T) {
+	// The first attempt hangs, until the client gives up on it.
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	retries := 1
+	client, err := newHTTPClient(&clientOptions{Retries: &retries, RetryBackoff: time.Millisecond, Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || requests != 2 {
+		t.Errorf("Expected the hung attempt to be retried, got %d after %d requests", resp.StatusCode, requests)
+	}
+
+	requests, retries = 0, 0
+	if client, err = newHTTPClient(&clientOptions{Retries: &retries, Timeout: 100 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := client.Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Errorf("Expected the hung attempt to time out, got %d", resp.StatusCode)
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	filename := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(filename, cert, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		options clientOptions
+		ok      bool
+	}{
+		{clientOptions{}, false},
+		{clientOptions{CACert: filename}, true},
+		{clientOptions{InsecureSkipVerify: true}, true},
+	} {
+		client, err := newHTTPClient(&test.options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if (err == nil) != test.ok {
+			t.Errorf("GET with %+v: got error %v", test.options, err)
+		}
+	}
+	if _, err := newHTTPClient(&clientOptions{CACert: filename + ".missing"}); err == nil {
+		t.Error("Expected an error for a missing CA certificate file")
+	}
+}
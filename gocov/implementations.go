@@ -0,0 +1,173 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+	goPackages "golang.org/x/tools/go/packages"
+)
+
+// implementation is a concrete type implementing an interface.
+type implementation struct {
+	pkg, typ string
+
+	// methods holds the functions implementing the interface's
+	// methods, in the interface's method order.
+	methods []funcName
+}
+
+// funcName identifies a function by file and name, as in coverage data.
+type funcName struct {
+	file, name string
+}
+
+// findImplementations loads the named packages and returns the types they
+// define that implement the interface named by iface, as import path and
+// name, e.g. io.Reader or example.com/store.Store. Implementations are
+// sorted by package and type name.
+func findImplementations(pkgNames []string, iface string) ([]implementation, error) {
+	i := strings.LastIndex(iface, ".")
+	if i <= 0 || i == len(iface)-1 {
+		return nil, fmt.Errorf("invalid interface %q, expected importpath.Name", iface)
+	}
+	ifacePkg, ifaceName := iface[:i], iface[i+1:]
+	pkgs, err := goPackages.Load(&goPackages.Config{
+		Mode: goPackages.NeedName | goPackages.NeedTypes | goPackages.NeedImports | goPackages.NeedDeps,
+	}, append([]string{ifacePkg}, pkgNames...)...)
+	if err != nil {
+		return nil, fmt.Errorf("load packages: %v", err)
+	}
+	var it *types.Interface
+	goPackages.Visit(pkgs, nil, func(pkg *goPackages.Package) {
+		if pkg.PkgPath != ifacePkg || pkg.Types == nil || it != nil {
+			return
+		}
+		if obj, ok := pkg.Types.Scope().Lookup(ifaceName).(*types.TypeName); ok {
+			it, _ = obj.Type().Underlying().(*types.Interface)
+		}
+	})
+	if it == nil {
+		return nil, fmt.Errorf("interface %s not found", iface)
+	}
+
+	reported := make(map[string]bool)
+	for _, name := range pkgNames {
+		reported[name] = true
+	}
+	var impls []implementation
+	for _, pkg := range pkgs {
+		if pkg.Types == nil || !reported[pkg.PkgPath] {
+			continue
+		}
+		// Only the first of duplicate roots is considered.
+		reported[pkg.PkgPath] = false
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || obj.IsAlias() || types.IsInterface(obj.Type()) {
+				continue
+			}
+			t := types.Type(types.NewPointer(obj.Type()))
+			if !types.Implements(t, it) {
+				continue
+			}
+			impl := implementation{pkg: pkg.PkgPath, typ: name}
+			mset := types.NewMethodSet(t)
+			for j := 0; j < it.NumMethods(); j++ {
+				m := it.Method(j)
+				sel := mset.Lookup(m.Pkg(), m.Name())
+				if sel == nil {
+					continue
+				}
+				fn := sel.Obj().(*types.Func)
+				recv := fn.Type().(*types.Signature).Recv().Type()
+				if p, ok := recv.(*types.Pointer); ok {
+					recv = p.Elem()
+				}
+				named, ok := recv.(*types.Named)
+				if !ok {
+					// Methods promoted from embedded interfaces have
+					// no implementation of their own.
+					continue
+				}
+				impl.methods = append(impl.methods, funcName{
+					file: pkg.Fset.Position(fn.Pos()).Filename,
+					name: named.Obj().Name() + "." + fn.Name(),
+				})
+			}
+			impls = append(impls, impl)
+		}
+	}
+	sort.Slice(impls, func(i, j int) bool {
+		if impls[i].pkg != impls[j].pkg {
+			return impls[i].pkg < impls[j].pkg
+		}
+		return impls[i].typ < impls[j].typ
+	})
+	return impls, nil
+}
+
+// printImplementations prints the coverage of each implementation's
+// methods, and of each implementation as a whole. Methods outside the
+// report, such as those promoted from types of other modules, are listed
+// without coverage.
+func printImplementations(w io.Writer, r *report, impls []implementation) error {
+	functions := make(map[funcName]*gocov.Function)
+	for _, pkg := range r.packages {
+		for _, fn := range pkg.Functions {
+			functions[funcName{fn.File, gocovutil.WithoutTypeParams(fn.Name)}] = fn
+		}
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 0, '\t', 0)
+	for _, impl := range impls {
+		var reached, total int
+		for _, m := range impl.methods {
+			method := m.name[strings.LastIndex(m.name, ".")+1:]
+			fn := functions[m]
+			if fn == nil {
+				fmt.Fprintf(tw, "%s\t %s\t %s\t -\n", impl.pkg, impl.typ, method)
+				continue
+			}
+			var fnReached int
+			for _, stmt := range fn.Statements {
+				if stmt.Reached > 0 {
+					fnReached++
+				}
+			}
+			reached += fnReached
+			total += len(fn.Statements)
+			fmt.Fprintf(tw, "%s/%s\t %s\t %s\t %s (%d/%d)\n",
+				impl.pkg, filepath.Base(fn.File), impl.typ, method,
+				formatPercentage(fnReached, len(fn.Statements)), fnReached, len(fn.Statements))
+		}
+		fmt.Fprintf(tw, "%s\t %s\t -\t %s (%d/%d)\n", impl.pkg, impl.typ, formatPercentage(reached, total), reached, total)
+	}
+	return tw.Flush()
+}
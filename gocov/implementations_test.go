@@ -0,0 +1,52 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestPrintImplementations(t *testing.T) {
+	r := newReport()
+	r.addPackage(&gocov.Package{Name: "p", Functions: []*gocov.Function{
+		{Name: "mem.Get", File: "/src/p/mem.go", Statements: []*gocov.Statement{{Reached: 1}, {}}},
+		{Name: "disk[K].Get", File: "/src/p/disk.go", Statements: []*gocov.Statement{{Reached: 2}}},
+	}})
+	impls := []implementation{
+		{pkg: "p", typ: "disk", methods: []funcName{{"/src/p/disk.go", "disk.Get"}, {"/src/q/q.go", "base.Close"}}},
+		{pkg: "p", typ: "mem", methods: []funcName{{"/src/p/mem.go", "mem.Get"}}},
+	}
+	var buf bytes.Buffer
+	if err := printImplementations(&buf, r, impls); err != nil {
+		t.Fatal(err)
+	}
+	want := "p/disk.go\t disk\t Get\t 100.00% (1/1)\n" +
+		"p\t\t disk\t Close\t -\n" +
+		"p\t\t disk\t -\t 100.00% (1/1)\n" +
+		"p/mem.go\t mem\t Get\t 50.00% (1/2)\n" +
+		"p\t\t mem\t -\t 50.00% (1/2)\n"
+	if buf.String() != want {
+		t.Errorf("printImplementations = %q, want %q", buf.String(), want)
+	}
+}
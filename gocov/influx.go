@@ -0,0 +1,76 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// influxEscaper escapes tag keys and values in InfluxDB line protocol.
+var influxEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+
+// influxTags formats the tags of a line protocol point, sorted by key as
+// InfluxDB recommends.
+func influxTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		if tags[k] == "" {
+			continue
+		}
+		fmt.Fprintf(&b, ",%s=%s", influxEscaper.Replace(k), influxEscaper.Replace(tags[k]))
+	}
+	return b.String()
+}
+
+// printInflux writes the coverage of each package, and the total, as
+// InfluxDB line protocol points, tagged with the run's labels, so that
+// coverage trends can be stored and plotted alongside other engineering
+// metrics. Points carry no timestamp; the database assigns the time of
+// writing.
+func printInflux(w io.Writer, r *report) error {
+	bw := bufio.NewWriter(w)
+	var reached, total int
+	for _, pkg := range r.packages {
+		pkgReached, pkgTotal := coverageCounts(pkg)
+		reached += pkgReached
+		total += pkgTotal
+		tags := map[string]string{"package": pkg.Name}
+		for k, v := range r.labels {
+			if k != "package" {
+				tags[k] = v
+			}
+		}
+		fmt.Fprintf(bw, "gocov_package%s reached=%di,total=%di,percent=%g\n",
+			influxTags(tags), pkgReached, pkgTotal, percentage(pkgReached, pkgTotal))
+	}
+	fmt.Fprintf(bw, "gocov_total%s reached=%di,total=%di,percent=%g\n",
+		influxTags(r.labels), reached, total, percentage(reached, total))
+	return bw.Flush()
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestPrintInflux(t *testing.T) {
+	r := newReport()
+	r.labels = map[string]string{"suite": "unit tests"}
+	r.addPackage(&gocov.Package{Name: "p", Functions: []*gocov.Function{
+		{Statements: []*gocov.Statement{{Reached: 1}, {}}},
+	}})
+	var buf bytes.Buffer
+	if err := printInflux(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	want := "gocov_package,package=p,suite=unit\\ tests reached=1i,total=2i,percent=50\n" +
+		"gocov_total,suite=unit\\ tests reached=1i,total=2i,percent=50\n"
+	if buf.String() != want {
+		t.Errorf("printInflux = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var (
+	initFlags  = flag.NewFlagSet("init", flag.ExitOnError)
+	initCIFlag = initFlags.String(
+		"ci", "github",
+		"The CI configuration to write: github, gitlab, all or none")
+	initCoverageFlag = initFlags.String(
+		"coverage", "",
+		"Seed the configured thresholds from this coverage file")
+	initForceFlag = initFlags.Bool(
+		"force", false,
+		"Overwrite existing files")
+)
+
+// initFile is a file written by "gocov init".
+type initFile struct {
+	name string
+	data string
+}
+
+const initConfig = `# Thresholds maps package import paths to the minimum coverage, in
+# percent, that "gocov check" requires of them. Run
+# "make coverage-thresholds" to set those of packages that have none
+# just below today's coverage.
+thresholds: {}
+`
+
+const initMakefile = `GOCOV ?= gocov
+COVERAGE ?= coverage.json
+
+.PHONY: coverage coverage-report coverage-check coverage-thresholds
+
+coverage:
+	go test -coverprofile=coverage.out ./...
+	$(GOCOV) convert coverage.out > $(COVERAGE)
+
+coverage-report: coverage
+	$(GOCOV) report $(COVERAGE)
+
+coverage-check: coverage
+	$(GOCOV) check $(COVERAGE)
+
+coverage-thresholds: coverage
+	$(GOCOV) suggest-thresholds -write $(COVERAGE)
+`
+
+const initGitHub = `name: coverage
+on: [push, pull_request]
+jobs:
+  coverage:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@v5
+        with:
+          go-version: stable
+      - run: go install github.com/hihoak/gocov/gocov@latest
+      - run: make coverage-report coverage-check
+      - uses: actions/upload-artifact@v4
+        with:
+          name: coverage
+          path: coverage.json
+`
+
+const initGitLab = `coverage:
+  image: golang:latest
+  script:
+    - go install github.com/hihoak/gocov/gocov@latest
+    - make coverage-report coverage-check
+  artifacts:
+    paths:
+      - coverage.json
+`
+
+// initFiles returns the files written by "gocov init" for the CI
+// configuration named by ci. The make targets go to makefile, and the
+// configuration holds the given thresholds, if any.
+func initFiles(ci, makefile string, thresholds map[string]float64) ([]initFile, error) {
+	cfg := initConfig
+	if len(thresholds) > 0 {
+		data, err := setThresholds([]byte(initConfig), thresholds)
+		if err != nil {
+			return nil, err
+		}
+		cfg = string(data)
+	}
+	files := []initFile{
+		{defaultConfigFile, cfg},
+		{makefile, initMakefile},
+	}
+	github := initFile{filepath.Join(".github", "workflows", "coverage.yml"), initGitHub}
+	gitlab := initFile{".gitlab-ci.yml", initGitLab}
+	switch ci {
+	case "github":
+		files = append(files, github)
+	case "gitlab":
+		files = append(files, gitlab)
+	case "all":
+		files = append(files, github, gitlab)
+	case "none":
+	default:
+		return nil, fmt.Errorf("unsupported CI %q", ci)
+	}
+	return files, nil
+}
+
+// initRepository writes a starter configuration, make targets and CI
+// configuration wiring the gocov commands together to the current
+// directory. Existing files are left alone unless -force is given; an
+// existing Makefile gets its targets in gocov.mk instead.
+func initRepository() (rc int) {
+	initFlags.Parse(os.Args[2:])
+	var thresholds map[string]float64
+	if *initCoverageFlag != "" {
+		doc, err := gocovutil.ReadDocument(*initCoverageFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read coverage file (%s): %s\n", *initCoverageFlag, err)
+			return 1
+		}
+		thresholds = suggestThresholds(doc, 5)
+	}
+	makefile := "Makefile"
+	if _, err := os.Stat(makefile); err == nil {
+		makefile = "gocov.mk"
+	}
+	files, err := initFiles(*initCIFlag, makefile, thresholds)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f.name); err == nil && !*initForceFlag {
+			fmt.Fprintf(os.Stderr, "skipping %s: already exists\n", f.name)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(f.name), 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write %s: %s\n", f.name, err)
+			return 1
+		}
+		if err := ioutil.WriteFile(f.name, []byte(f.data), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write %s: %s\n", f.name, err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "wrote %s\n", f.name)
+	}
+	if makefile != "Makefile" {
+		fmt.Fprintf(os.Stderr, "add \"include %s\" to your Makefile\n", makefile)
+	}
+	if len(thresholds) == 0 {
+		fmt.Fprintln(os.Stderr, "run \"make coverage-thresholds\" to configure the thresholds checked by \"make coverage-check\"")
+	}
+	return 0
+}
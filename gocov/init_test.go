@@ -0,0 +1,82 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInitFiles(t *testing.T) {
+	files, err := initFiles("all", "gocov.mk", map[string]float64{"example.com/p": 75})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.name)
+	}
+	want := []string{".gocov.yaml", "gocov.mk", filepath.Join(".github", "workflows", "coverage.yml"), ".gitlab-ci.yml"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("initFiles names = %v, want %v", names, want)
+	}
+	if cfg := files[0].data; !strings.HasPrefix(cfg, "# Thresholds maps") || !strings.HasSuffix(cfg, "\nthresholds:\n  example.com/p: 75\n") {
+		t.Errorf("initFiles config = %q", cfg)
+	}
+	if !strings.Contains(files[1].data, "\n\t$(GOCOV) suggest-thresholds -write $(COVERAGE)\n") {
+		t.Errorf("initFiles make targets do not add thresholds to the configuration:\n%s", files[1].data)
+	}
+	if !strings.Contains(files[1].data, "\n\t$(GOCOV) check $(COVERAGE)\n") {
+		t.Errorf("initFiles make targets lack coverage-check:\n%s", files[1].data)
+	}
+	if _, err := initFiles("jenkins", "Makefile", nil); err == nil {
+		t.Error("initFiles accepted an unsupported CI")
+	}
+}
+
+func TestInitConfig(t *testing.T) {
+	// The starter configuration checks nothing until thresholds are
+	// added to it, without "gocov check" taking it for no configuration.
+	filename := filepath.Join(t.TempDir(), ".gocov.yaml")
+	if err := ioutil.WriteFile(filename, []byte(initConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Thresholds == nil || len(cfg.Thresholds) != 0 {
+		t.Errorf("initConfig thresholds = %#v, want an empty map", cfg.Thresholds)
+	}
+	if err := addThresholds(filename, map[string]float64{"example.com/p": 61}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.Replace(initConfig, "thresholds: {}\n", "thresholds:\n  example.com/p: 61\n", 1); string(data) != want {
+		t.Errorf("addThresholds wrote %q, want %q", data, want)
+	}
+}
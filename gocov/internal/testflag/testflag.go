@@ -41,6 +41,7 @@ var testFlagDefn = []*testFlagSpec{
 	{name: "benchmem", isBool: true},
 	{name: "benchtime"},
 	{name: "covermode"},
+	{name: "coverpkg"},
 	{name: "cpu"},
 	{name: "cpuprofile"},
 	{name: "memprofile"},
@@ -63,6 +63,10 @@ var tests = []test{{
 	input:        []string{"-h", "-?", "-help"},
 	packageNames: nil,
 	passToTest:   []string{"-h", "-?", "-help"},
+}, {
+	input:        []string{"-coverpkg", "./...", "./subdir"},
+	packageNames: []string{"./subdir"},
+	passToTest:   []string{"-coverpkg", "./..."},
 }, {
 	input:        []string{"--v", "--tags=a b c", "pkgname"},
 	packageNames: []string{"pkgname"},
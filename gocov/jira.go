@@ -0,0 +1,206 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// jiraIssueSummary returns the summary of the issue tracking a coverage
+// regression in a package, by which existing issues are found.
+func jiraIssueSummary(pkg string) string {
+	return "Coverage regression in " + pkg
+}
+
+// jiraClient calls the Jira REST API.
+type jiraClient struct {
+	client   *http.Client
+	base     string
+	user     string
+	password string
+}
+
+// newJiraClient returns a client for the Jira instance at rawurl, which
+// may carry the credentials as user information.
+func newJiraClient(client *http.Client, rawurl string) (*jiraClient, error) {
+	u, err := url.Parse(expandURL(rawurl))
+	if err != nil {
+		return nil, err
+	}
+	j := &jiraClient{client: client}
+	if u.User != nil {
+		j.user = u.User.Username()
+		j.password, _ = u.User.Password()
+		u.User = nil
+	}
+	j.base = strings.TrimSuffix(u.String(), "/")
+	return j, nil
+}
+
+// do makes a request to the API, decoding the JSON response into v if it
+// is not nil.
+func (j *jiraClient) do(method, path, contentType string, body io.Reader, v interface{}) error {
+	req, err := http.NewRequest(method, j.base+path, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	// Required by Jira for attachment uploads.
+	req.Header.Set("X-Atlassian-Token", "no-check")
+	if j.user != "" {
+		req.SetBasicAuth(j.user, j.password)
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("jira %s %s: %s", method, path, resp.Status)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// doJSON makes a request to the API with a JSON body.
+func (j *jiraClient) doJSON(method, path string, body, v interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return j.do(method, path, "application/json", bytes.NewReader(data), v)
+}
+
+// findIssue returns the key of the unresolved issue of the project with
+// the given summary, or "" if there is none.
+func (j *jiraClient) findIssue(project, summary string) (string, error) {
+	jql := fmt.Sprintf("project = %q AND summary ~ %q AND statusCategory != Done", project, summary)
+	var result struct {
+		Issues []struct {
+			Key    string `json:"key"`
+			Fields struct {
+				Summary string `json:"summary"`
+			} `json:"fields"`
+		} `json:"issues"`
+	}
+	path := "/rest/api/2/search?fields=summary&jql=" + url.QueryEscape(jql)
+	if err := j.do("GET", path, "", nil, &result); err != nil {
+		return "", err
+	}
+	// Text search is fuzzy, so insist on an exact match.
+	for _, issue := range result.Issues {
+		if issue.Fields.Summary == summary {
+			return issue.Key, nil
+		}
+	}
+	return "", nil
+}
+
+// createIssue creates an issue, returning its key.
+func (j *jiraClient) createIssue(project, issueType, summary, description string) (string, error) {
+	type name struct {
+		Name string `json:"name,omitempty"`
+		Key  string `json:"key,omitempty"`
+	}
+	body := map[string]interface{}{"fields": map[string]interface{}{
+		"project":     name{Key: project},
+		"issuetype":   name{Name: issueType},
+		"summary":     summary,
+		"description": description,
+	}}
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := j.doJSON("POST", "/rest/api/2/issue", body, &created); err != nil {
+		return "", err
+	}
+	return created.Key, nil
+}
+
+// comment adds a comment to an issue.
+func (j *jiraClient) comment(key, text string) error {
+	return j.doJSON("POST", "/rest/api/2/issue/"+key+"/comment", map[string]string{"body": text}, nil)
+}
+
+// attach uploads a file to an issue.
+func (j *jiraClient) attach(key, filename string, data []byte) error {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		return err
+	}
+	part.Write(data)
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return j.do("POST", "/rest/api/2/issue/"+key+"/attachments", w.FormDataContentType(), &buf, nil)
+}
+
+// fileIssues opens an issue for each regression in the summary, or
+// comments on the issue already open for it, attaching the diff report.
+func (t *notifyTarget) fileIssues(client *http.Client, s *notifySummary) error {
+	if t.Project == "" {
+		return fmt.Errorf("jira notification requires project")
+	}
+	issueType := t.IssueType
+	if issueType == "" {
+		issueType = "Bug"
+	}
+	j, err := newJiraClient(client, t.URL)
+	if err != nil {
+		return err
+	}
+	for _, r := range s.Regressions {
+		text := fmt.Sprintf("The %s, below the minimum of %.2f%%.", r, s.MinPackageCoverage)
+		summary := jiraIssueSummary(r.Name)
+		key, err := j.findIssue(t.Project, summary)
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			key, err = j.createIssue(t.Project, issueType, summary, text)
+		} else {
+			err = j.comment(key, text)
+		}
+		if err != nil {
+			return err
+		}
+		if s.Diff != "" {
+			if err := j.attach(key, "coverage-diff.md", []byte(s.Diff)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
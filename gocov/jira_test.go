@@ -0,0 +1,66 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJiraFileIssues(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if user, _, _ := r.BasicAuth(); user != "bot" {
+			t.Errorf("Expected basic auth as bot, got %q", user)
+		}
+		switch r.URL.Path {
+		case "/rest/api/2/search":
+			w.Write([]byte(`{"issues":[{"key":"COV-1","fields":{"summary":"Coverage regression in q"}}]}`))
+		case "/rest/api/2/issue":
+			w.Write([]byte(`{"key":"COV-2"}`))
+		}
+	}))
+	defer server.Close()
+
+	s := &notifySummary{
+		Regressions: []packageRegression{{Name: "p", After: 10}, {Name: "q", After: 20}},
+		Diff:        "## Coverage changes\n",
+	}
+	target := &notifyTarget{Kind: "jira", URL: strings.Replace(server.URL, "://", "://bot:token@", 1), Project: "COV"}
+	if err := target.fileIssues(server.Client(), s); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"GET /rest/api/2/search",
+		"POST /rest/api/2/issue",
+		"POST /rest/api/2/issue/COV-2/attachments",
+		"GET /rest/api/2/search",
+		"POST /rest/api/2/issue/COV-1/comment",
+		"POST /rest/api/2/issue/COV-1/attachments",
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %q, want %q", requests, want)
+	}
+}
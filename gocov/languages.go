@@ -0,0 +1,91 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hihoak/gocov"
+)
+
+// languageCount holds the statement counts of the packages of a language.
+type languageCount struct {
+	name           string
+	reached, total int
+}
+
+// packageLanguage returns the language of a package, naming Go "go".
+func packageLanguage(pkg *gocov.Package) string {
+	if pkg.IsGo() {
+		return "go"
+	}
+	return pkg.Language
+}
+
+// languageCounts returns the statement counts of each language of the
+// packages, in order of name, or nil if they are all of one language, as
+// when coverage of other languages has been imported alongside Go's.
+func languageCounts(packages []*gocov.Package) []languageCount {
+	counts := make(map[string]*languageCount)
+	var names []string
+	for _, pkg := range packages {
+		name := packageLanguage(pkg)
+		c := counts[name]
+		if c == nil {
+			c = &languageCount{name: name}
+			counts[name] = c
+			names = append(names, name)
+		}
+		reached, total := coverageCounts(pkg)
+		c.reached += reached
+		c.total += total
+	}
+	if len(names) < 2 {
+		return nil
+	}
+	sort.Strings(names)
+	languages := make([]languageCount, len(names))
+	for i, name := range names {
+		languages[i] = *counts[name]
+	}
+	return languages
+}
+
+// combinedLanguage returns the language of a package combining the
+// packages: theirs if they share one, or else their languages, sorted
+// and joined with "+", so that analyses specific to Go skip a package
+// that combines Go with other languages.
+func combinedLanguage(packages []*gocov.Package) string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, pkg := range packages {
+		if name := packageLanguage(pkg); !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if len(names) == 1 {
+		return packages[0].Language
+	}
+	sort.Strings(names)
+	return strings.Join(names, "+")
+}
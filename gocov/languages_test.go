@@ -0,0 +1,80 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestPrintSummaryLanguages(t *testing.T) {
+	r := newReport()
+	r.addPackage(&gocov.Package{Name: "p", Functions: []*gocov.Function{
+		{Name: "F", Statements: []*gocov.Statement{{Reached: 1}, {}}},
+	}})
+	r.addPackage(&gocov.Package{Name: "web", Language: "javascript", Functions: []*gocov.Function{
+		{Name: "Render", Statements: []*gocov.Statement{{Reached: 1}}},
+	}})
+	var buf bytes.Buffer
+	printSummary(&buf, r, "", nil)
+	want := "## Coverage\n\n| Package | Language | Coverage | Statements |\n| --- | --- | ---: | ---: |\n" +
+		"| p | go | 50.00% | 1/2 |\n| web | javascript | 100.00% | 1/1 |\n" +
+		"| **Total** | **go** | **50.00%** | **1/2** |\n| **Total** | **javascript** | **100.00%** | **1/1** |\n" +
+		"| **Total** | | **66.66%** | **2/3** |\n"
+	if buf.String() != want {
+		t.Errorf("printSummary = %q, want %q", buf.String(), want)
+	}
+	// Functions of other languages are not Go's exported API.
+	if reached, total := exportedCounts(r.packages[1]); reached != 0 || total != 0 {
+		t.Errorf("exportedCounts of a javascript package = %d, %d, want 0, 0", reached, total)
+	}
+}
+
+func TestGroupLanguages(t *testing.T) {
+	packages := []*gocov.Package{
+		{Name: "example.com/api"},
+		{Name: "example.com/web", Language: "javascript"},
+		{Name: "example.com/web/admin", Language: "javascript"},
+		{Name: "example.com/cmd"},
+	}
+	groups := packageGroups{
+		"frontend": {"example.com/web/..."},
+		"product":  {"example.com/api", "example.com/web"},
+	}
+	grouped := groups.groupPackages(packages)
+	var got []string
+	for _, pkg := range grouped {
+		got = append(got, pkg.Name+" "+packageLanguage(pkg))
+	}
+	want := []string{"frontend javascript", "product go+javascript", "(ungrouped) go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupPackages languages = %q, want %q", got, want)
+	}
+	// Go-specific analyses skip groups holding other languages, without
+	// reading their sources.
+	fn := &gocov.Function{Name: "render", File: "/nonexistent/web/app.go"}
+	if c, err := newComplexities().of(grouped[1], fn); c != 0 || err != nil {
+		t.Errorf("complexity of a function of group product = %d, %v, want 0, nil", c, err)
+	}
+}
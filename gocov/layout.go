@@ -0,0 +1,162 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// textLayout selects the columns of the text report, and the order of its
+// functions.
+type textLayout struct {
+	columns []string
+	sortKey string
+	desc    bool
+
+	// numbers formats the coverage percentages.
+	numbers percentFormat
+
+	complexities *complexities
+	cache        map[reportFunctionKey]int
+}
+
+type reportFunctionKey struct {
+	file  string
+	start int
+}
+
+// textColumns are the columns the text report can show.
+var textColumns = map[string]bool{
+	"file":       true,
+	"function":   true,
+	"coverage":   true,
+	"percent":    true,
+	"reached":    true,
+	"statements": true,
+	"complexity": true,
+}
+
+// parseTextLayout parses the -columns and -sort flags of the text report.
+func parseTextLayout(columns, sortKey string) (*textLayout, error) {
+	layout := &textLayout{
+		numbers:      defaultPercentFormat,
+		complexities: newComplexities(),
+		cache:        make(map[reportFunctionKey]int),
+	}
+	for _, column := range strings.Split(columns, ",") {
+		column = strings.TrimSpace(column)
+		if !textColumns[column] {
+			return nil, fmt.Errorf("unknown report column %q", column)
+		}
+		layout.columns = append(layout.columns, column)
+	}
+	layout.desc = strings.HasPrefix(sortKey, "-")
+	layout.sortKey = strings.TrimPrefix(sortKey, "-")
+	if layout.sortKey == "name" {
+		layout.sortKey = "function"
+	}
+	if !textColumns[layout.sortKey] {
+		return nil, fmt.Errorf("unknown sort column %q", layout.sortKey)
+	}
+	return layout, nil
+}
+
+// complexity returns the cyclomatic complexity of the function.
+func (l *textLayout) complexity(fn reportFunction) (int, error) {
+	key := reportFunctionKey{fn.File, fn.Start}
+	if c, ok := l.cache[key]; ok {
+		return c, nil
+	}
+	c, err := l.complexities.of(fn.pkg, fn.Function)
+	if err != nil {
+		return 0, err
+	}
+	l.cache[key] = c
+	return c, nil
+}
+
+// countCell formats the counts of reached and total statements for one of
+// the numeric columns.
+func (l *textLayout) countCell(column string, reached, total int) string {
+	switch column {
+	case "percent":
+		return l.numbers.format(reached, total)
+	case "reached":
+		return strconv.Itoa(reached)
+	case "statements":
+		return strconv.Itoa(total)
+	}
+	return fmt.Sprintf("%s (%d/%d)", l.numbers.format(reached, total), reached, total)
+}
+
+// sort orders the functions by the layout's sort column. The coverage
+// column, like the percent column, orders functions by the fraction of
+// their statements reached. Functions with equal coverage are ordered by
+// their number of statements, in the same direction.
+func (l *textLayout) sort(functions reportFunctionList) error {
+	if l.sortKey == "percent" || l.sortKey == "coverage" {
+		if l.desc {
+			sort.Sort(reverse{functions})
+		} else {
+			sort.Sort(functions)
+		}
+		return nil
+	}
+	var err error
+	value := func(fn reportFunction) float64 {
+		switch l.sortKey {
+		case "reached":
+			return float64(fn.statementsReached)
+		case "complexity":
+			c, cerr := l.complexity(fn)
+			if cerr != nil && err == nil {
+				err = cerr
+			}
+			return float64(c)
+		}
+		return float64(len(fn.Statements))
+	}
+	less := func(a, b reportFunction) bool {
+		switch l.sortKey {
+		case "file":
+			return a.File < b.File
+		case "function":
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.File < b.File
+		}
+		if va, vb := value(a), value(b); va != vb {
+			return va < vb
+		}
+		return len(a.Statements) < len(b.Statements)
+	}
+	sort.SliceStable(functions, func(i, j int) bool {
+		if l.desc {
+			return less(functions[j], functions[i])
+		}
+		return less(functions[i], functions[j])
+	})
+	return err
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestTextLayoutSort(t *testing.T) {
+	functions := func() reportFunctionList {
+		return reportFunctionList{
+			{Function: &gocov.Function{Name: "b", Statements: make([]*gocov.Statement, 4)}, statementsReached: 1},
+			{Function: &gocov.Function{Name: "c", Statements: make([]*gocov.Statement, 2)}, statementsReached: 2},
+			{Function: &gocov.Function{Name: "a", Statements: make([]*gocov.Statement, 3)}, statementsReached: 0},
+		}
+	}
+	for _, test := range []struct {
+		sort string
+		want string
+	}{
+		{"-percent", "cba"},
+		{"percent", "abc"},
+		{"-coverage", "cba"},
+		{"coverage", "abc"},
+		{"-statements", "bac"},
+		{"reached", "abc"},
+		{"name", "abc"},
+		{"-name", "cba"},
+	} {
+		layout, err := parseTextLayout("function", test.sort)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fns := functions()
+		if err := layout.sort(fns); err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		for _, fn := range fns {
+			got += fn.Name
+		}
+		if got != test.want {
+			t.Errorf("sort %s = %s, want %s", test.sort, got, test.want)
+		}
+	}
+	if _, err := parseTextLayout("file,bogus", "-percent"); err == nil {
+		t.Errorf("Expected an error for an unknown column")
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var lintFlags = flag.NewFlagSet("lint", flag.ExitOnError)
+
+// lintPackage returns the anomalies found in a package's coverage, which
+// point to corrupted profiles, mismatched sources or mistakes in merging.
+func lintPackage(pkg *gocov.Package) []string {
+	var problems []string
+	report := func(fn *gocov.Function, format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf("%s/%s: %s: %s",
+			pkg.Name, filepath.Base(fn.File), fn.Name, fmt.Sprintf(format, args...)))
+	}
+
+	for _, file := range pkg.SourceFiles() {
+		functions := append([]*gocov.Function(nil), file.Functions...)
+		sort.SliceStable(functions, func(i, j int) bool { return functions[i].Start < functions[j].Start })
+		for i, fn := range functions {
+			if len(fn.Statements) == 0 {
+				report(fn, "function has no statements")
+			}
+			for _, stmt := range fn.Statements {
+				if stmt.Start < fn.Start || stmt.End > fn.End {
+					report(fn, "statement at offset %d lies outside the function", stmt.Start)
+				}
+			}
+			// Function literals nest within their enclosing
+			// functions, but extents may not otherwise overlap.
+			for _, next := range functions[i+1:] {
+				if next.Start >= fn.End {
+					break
+				}
+				if next.End > fn.End {
+					report(fn, "extent overlaps that of %s", next.Name)
+				}
+			}
+		}
+	}
+
+	// Profiles merged with themselves double every count, so that even
+	// a package's least used statements run more than once.
+	var count int64
+	var statements int
+	identical := true
+	for _, fn := range pkg.Functions {
+		for _, stmt := range fn.Statements {
+			if statements == 0 {
+				count = stmt.Reached
+			}
+			identical = identical && stmt.Reached == count
+			statements++
+		}
+	}
+	if identical && statements > 1 && count > 1 {
+		problems = append(problems, fmt.Sprintf("%s: all %d statements were reached exactly %d times, as if the coverage was merged with itself",
+			pkg.Name, statements, count))
+	}
+	return problems
+}
+
+// lintCoverage inspects a coverage document for anomalies, printing each
+// and failing if there are any.
+func lintCoverage() (rc int) {
+	lintFlags.Parse(os.Args[2:])
+	filename := "-"
+	if lintFlags.NArg() > 0 {
+		filename = lintFlags.Arg(0)
+	}
+	doc, err := gocovutil.ReadDocument(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read coverage file (%s): %s\n", filename, err)
+		return 1
+	}
+	for _, pkg := range doc.Packages {
+		for _, problem := range lintPackage(pkg) {
+			fmt.Println(problem)
+			rc = 1
+		}
+	}
+	return rc
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestLintPackage(t *testing.T) {
+	clean := &gocov.Package{Name: "p", Functions: []*gocov.Function{
+		{Name: "F", File: "a.go", Start: 0, End: 100, Statements: []*gocov.Statement{{Start: 10, End: 20, Reached: 2}}},
+		{Name: "F.func1", File: "a.go", Start: 30, End: 60, Statements: []*gocov.Statement{{Start: 40, End: 50, Reached: 1}}},
+		{Name: "G", File: "b.go", Start: 0, End: 100, Statements: []*gocov.Statement{{Start: 10, End: 20, Reached: 2}}},
+	}}
+	if problems := lintPackage(clean); len(problems) != 0 {
+		t.Errorf("Expected no problems, got %q", problems)
+	}
+
+	suspicious := &gocov.Package{Name: "p", Functions: []*gocov.Function{
+		{Name: "F", File: "a.go", Start: 0, End: 50, Statements: []*gocov.Statement{{Start: 60, End: 70, Reached: 2}}},
+		{Name: "G", File: "a.go", Start: 40, End: 90, Statements: []*gocov.Statement{{Start: 45, End: 48, Reached: 2}}},
+		{Name: "H", File: "a.go", Start: 100, End: 110},
+	}}
+	want := []string{
+		"p/a.go: F: statement at offset 60 lies outside the function",
+		"p/a.go: F: extent overlaps that of G",
+		"p/a.go: H: function has no statements",
+		"p: all 2 statements were reached exactly 2 times, as if the coverage was merged with itself",
+	}
+	if problems := lintPackage(suspicious); !reflect.DeepEqual(problems, want) {
+		t.Errorf("lintPackage = %q, want %q", problems, want)
+	}
+}
@@ -28,16 +28,38 @@ import (
 	"os"
 
 	"github.com/hihoak/gocov"
-	"github.com/hihoak/gocov/gocov/convert"
+	"github.com/hihoak/gocov/gocovutil"
 )
 
 func usage() {
 	fmt.Fprintf(os.Stderr, "Usage:\n\n\tgocov command [arguments]\n\n")
 	fmt.Fprintf(os.Stderr, "The commands are:\n\n")
 	fmt.Fprintf(os.Stderr, "\tannotate\n")
+	fmt.Fprintf(os.Stderr, "\tannotate-diff\n")
+	fmt.Fprintf(os.Stderr, "\tattest\n")
+	fmt.Fprintf(os.Stderr, "\tazure\n")
+	fmt.Fprintf(os.Stderr, "\tcheck\n")
 	fmt.Fprintf(os.Stderr, "\tconvert\n")
+	fmt.Fprintf(os.Stderr, "\texplain\n")
+	fmt.Fprintf(os.Stderr, "\tgerrit\n")
+	fmt.Fprintf(os.Stderr, "\tharbormaster\n")
+	fmt.Fprintf(os.Stderr, "\thistory\n")
+	fmt.Fprintf(os.Stderr, "\thook\n")
+	fmt.Fprintf(os.Stderr, "\tinit\n")
+	fmt.Fprintf(os.Stderr, "\tlint\n")
+	fmt.Fprintf(os.Stderr, "\tmatrix\n")
+	fmt.Fprintf(os.Stderr, "\tmerge\n")
+	fmt.Fprintf(os.Stderr, "\tratchet\n")
+	fmt.Fprintf(os.Stderr, "\trelease-notes\n")
+	fmt.Fprintf(os.Stderr, "\tnotify\n")
 	fmt.Fprintf(os.Stderr, "\treport\n")
+	fmt.Fprintf(os.Stderr, "\trollup\n")
+	fmt.Fprintf(os.Stderr, "\tshard\n")
+	fmt.Fprintf(os.Stderr, "\tsign\n")
+	fmt.Fprintf(os.Stderr, "\tstability\n")
+	fmt.Fprintf(os.Stderr, "\tsuggest-thresholds\n")
 	fmt.Fprintf(os.Stderr, "\ttest\n")
+	fmt.Fprintf(os.Stderr, "\tverify\n")
 	fmt.Fprintf(os.Stderr, "\n")
 	flag.PrintDefaults()
 	os.Exit(2)
@@ -56,6 +78,14 @@ func unmarshalJson(data []byte) (packages []*gocov.Package, err error) {
 	return
 }
 
+func unmarshalDocument(data []byte) (*gocovutil.Document, error) {
+	doc := &gocovutil.Document{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
 func main() {
 	flag.Usage = usage
 	flag.Parse()
@@ -64,21 +94,56 @@ func main() {
 	if flag.NArg() > 0 {
 		command = flag.Arg(0)
 		switch command {
+		case "attest":
+			os.Exit(attestCoverage())
+		case "azure":
+			os.Exit(azureCoverage())
+		case "check":
+			os.Exit(checkCoverage())
 		case "convert":
-			if flag.NArg() <= 1 {
-				fmt.Fprintln(os.Stderr, "missing cover profile")
-				os.Exit(1)
-			}
-			out, err := convert.ConvertProfiles(flag.Args()[1:]...)
-			if err != nil {
-				fmt.Fprintln(os.Stderr, "error:", err)
-				os.Exit(1)
-			}
-			os.Stdout.Write(out)
+			os.Exit(convertProfiles())
 		case "annotate":
 			os.Exit(annotateSource())
+		case "annotate-diff":
+			os.Exit(annotateDiffCoverage())
+		case "explain":
+			os.Exit(explainCoverage())
+		case "gerrit":
+			os.Exit(gerritCoverage())
+		case "harbormaster":
+			os.Exit(harbormasterResults())
+		case "history":
+			os.Exit(historyCoverage())
+		case "hook":
+			os.Exit(hookCoverage())
+		case "init":
+			os.Exit(initRepository())
+		case "lint":
+			os.Exit(lintCoverage())
+		case "matrix":
+			os.Exit(testMatrix())
+		case "merge":
+			os.Exit(mergeCoverage())
+		case "ratchet":
+			os.Exit(ratchetCoverage())
+		case "release-notes":
+			os.Exit(releaseNotes())
+		case "notify":
+			os.Exit(notifyCoverage())
 		case "report":
 			os.Exit(reportCoverage())
+		case "rollup":
+			os.Exit(rollupCoverage())
+		case "sign":
+			os.Exit(signCoverage())
+		case "shard":
+			os.Exit(convertShard())
+		case "stability":
+			os.Exit(stabilityCoverage())
+		case "suggest-thresholds":
+			os.Exit(suggestCoverageThresholds())
+		case "verify":
+			os.Exit(verifyProfiles())
 		case "test":
 			if err := runTests(flag.Args()[1:]); err != nil {
 				fmt.Fprintln(os.Stderr, "error:", err)
@@ -0,0 +1,143 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var (
+	matrixFlags      = flag.NewFlagSet("matrix", flag.ExitOnError)
+	matrixComboFlags stringsFlag
+)
+
+func init() {
+	matrixFlags.Var(&matrixComboFlags, "combo",
+		"Run tests for the `goos/goarch[:tag,...]` combination (repeatable); omit goos/goarch for the host platform")
+	matrixFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: gocov matrix -combo spec... [test arguments]\n\n")
+		matrixFlags.PrintDefaults()
+	}
+}
+
+// combo is a platform and build tag combination to test.
+type combo struct {
+	goos, goarch string
+	tags         string
+}
+
+// parseCombo parses a combination of the form "goos/goarch:tag,tag", in
+// which either part may be omitted.
+func parseCombo(spec string) (combo, error) {
+	var c combo
+	platform := spec
+	if i := strings.Index(spec, ":"); i >= 0 {
+		platform, c.tags = spec[:i], spec[i+1:]
+	}
+	if platform != "" {
+		i := strings.Index(platform, "/")
+		if i <= 0 || i == len(platform)-1 {
+			return c, fmt.Errorf("combination %q is not of the form goos/goarch[:tags]", spec)
+		}
+		c.goos, c.goarch = platform[:i], platform[i+1:]
+	}
+	return c, nil
+}
+
+// env returns the environment variables selecting the platform.
+func (c combo) env() []string {
+	if c.goos == "" {
+		return nil
+	}
+	return []string{"GOOS=" + c.goos, "GOARCH=" + c.goarch}
+}
+
+// labels describes the combination as document labels.
+func (c combo) labels() map[string]string {
+	labels := make(map[string]string)
+	if c.goos != "" {
+		labels["goos"] = c.goos
+		labels["goarch"] = c.goarch
+	}
+	if c.tags != "" {
+		labels["tags"] = c.tags
+	}
+	return labels
+}
+
+// testMatrix runs the tests once per combination, and writes the merged
+// coverage with each statement attributed to the combinations that
+// reached it. "gocov report -format=breakdown" then shows the coverage of
+// each combination.
+//
+// Tests for a foreign platform are run by "go test" as usual, and so need
+// an exec wrapper such as go_$GOOS_$GOARCH_exec to be installed.
+func testMatrix() (rc int) {
+	matrixFlags.Parse(os.Args[2:])
+	if len(matrixComboFlags) == 0 {
+		fmt.Fprintf(os.Stderr, "missing -combo\n")
+		return 1
+	}
+	var combos []combo
+	for _, spec := range matrixComboFlags {
+		c, err := parseCombo(spec)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		combos = append(combos, c)
+	}
+
+	merged := &gocovutil.Document{}
+	for i, c := range combos {
+		args := matrixFlags.Args()
+		if c.tags != "" {
+			args = append([]string{"-tags", c.tags}, args...)
+		}
+		out, err := testCoverage("", args, c.env())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: testing %s: %s\n", matrixComboFlags[i], err)
+			return 1
+		}
+		doc, err := unmarshalDocument(out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to unmarshal coverage data: %s\n", err)
+			return 1
+		}
+		doc.Labels = c.labels()
+		attributeDocument(doc, i)
+		merged.Inputs = append(merged.Inputs, inputName(matrixComboFlags[i], doc))
+		if err := merged.Packages.MergePackages(doc.Packages); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to merge coverage: %s\n", err)
+			return 1
+		}
+	}
+	if err := gocovutil.WriteDocument(os.Stdout, merged); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write coverage data: %s\n", err)
+		return 1
+	}
+	return 0
+}
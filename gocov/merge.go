@@ -0,0 +1,117 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var (
+	mergeFlags         = flag.NewFlagSet("merge", flag.ExitOnError)
+	mergeAttributeFlag = mergeFlags.Bool(
+		"attribute", false,
+		"Record which inputs reached each statement")
+	mergeVerifyKeyFlag = mergeFlags.String(
+		"verify-key", "",
+		"Require inputs to be signed by the private key of this ed25519 public key file")
+	mergeLabels = make(labelFlags)
+	mergeSelect selectFlag
+)
+
+func init() {
+	mergeFlags.Var(mergeLabels, "label", "Attach a key=value `label` to the merged output (repeatable)")
+	mergeFlags.Var(&mergeSelect, "select", "Merge only inputs carrying all of the comma-separated key=value `labels`")
+}
+
+// inputName returns the name by which a merge input is identified in
+// attribution data: its labels if it has any, otherwise its file name.
+func inputName(filename string, doc *gocovutil.Document) string {
+	if len(doc.Labels) > 0 {
+		return doc.LabelString()
+	}
+	return filename
+}
+
+// attributeDocument attributes each reached statement of the document to
+// input i of a merge.
+func attributeDocument(doc *gocovutil.Document, i int) {
+	for _, pkg := range doc.Packages {
+		for _, fn := range pkg.Functions {
+			for _, stmt := range fn.Statements {
+				// Attribution carried over from an earlier merge
+				// refers to that merge's inputs, not ours.
+				stmt.Attribution = nil
+				if stmt.Reached > 0 {
+					stmt.Attribute(i)
+				}
+			}
+		}
+	}
+}
+
+func mergeCoverage() (rc int) {
+	mergeFlags.Parse(os.Args[2:])
+	if mergeFlags.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "missing coverage file\n")
+		return 1
+	}
+
+	key, err := verifyKey(*mergeVerifyKeyFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read key: %s\n", err)
+		return 1
+	}
+	merged := &gocovutil.Document{}
+	for _, filename := range mergeFlags.Args() {
+		doc, err := gocovutil.ReadDocument(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read coverage file (%s): %s\n", filename, err)
+			return 1
+		}
+		if key != nil {
+			if err := gocovutil.VerifyDocument(doc, key); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to verify coverage file (%s): %s\n", filename, err)
+				return 1
+			}
+		}
+		if !mergeSelect.selects(doc.Labels) {
+			continue
+		}
+		if *mergeAttributeFlag {
+			attributeDocument(doc, len(merged.Inputs))
+			merged.Inputs = append(merged.Inputs, inputName(filename, doc))
+		}
+		merged.Packages.AddPackages(doc.Packages)
+	}
+	if len(mergeLabels) > 0 {
+		merged.Labels = mergeLabels
+	}
+
+	if err := gocovutil.WriteDocument(os.Stdout, merged); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write coverage data: %s\n", err)
+		return 1
+	}
+	return 0
+}
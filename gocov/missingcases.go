@@ -0,0 +1,122 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+// missingCase is a case of a switch or type switch statement none of whose
+// statements was reached.
+type missingCase struct {
+	fn    *gocov.Function
+	line  int
+	label string
+}
+
+// missingCases returns the cases of the file's switch and type switch
+// statements that have statements, none of them reached by the coverage
+// of the file's functions.
+func missingCases(fset *token.FileSet, file *ast.File, src []byte, functions []*gocov.Function) []missingCase {
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	var cases []missingCase
+	ast.Inspect(file, func(n ast.Node) bool {
+		clause, ok := n.(*ast.CaseClause)
+		if !ok {
+			return true
+		}
+		start, end := offset(clause.Colon)+1, offset(clause.End())
+		var fn *gocov.Function
+		for _, f := range functions {
+			for _, stmt := range f.Statements {
+				if stmt.Start < start || stmt.Start >= end {
+					continue
+				}
+				if stmt.Reached > 0 {
+					return true
+				}
+				// Statements of closures within the case belong to
+				// the closures; the case belongs to the function
+				// enclosing them.
+				if fn == nil || f.Start <= fn.Start && f.End >= fn.End {
+					fn = f
+				}
+			}
+		}
+		if fn == nil {
+			return true
+		}
+		label := "default"
+		if len(clause.List) > 0 {
+			label = "case " + string(src[offset(clause.List[0].Pos()):offset(clause.List[len(clause.List)-1].End())])
+		}
+		cases = append(cases, missingCase{fn, fset.Position(clause.Pos()).Line, label})
+		return true
+	})
+	return cases
+}
+
+// printMissingCases lists the uncovered cases of switch and type switch
+// statements, grouped by function, as suggestions of missing test cases.
+// Packages of languages other than Go are skipped.
+func printMissingCases(w io.Writer, r *report) error {
+	fsys := sourceFS(*reportSourceRootFlag)
+	tw := tabwriter.NewWriter(w, 0, 8, 0, '\t', 0)
+	for _, pkg := range r.packages {
+		if !pkg.IsGo() {
+			continue
+		}
+		var cases []missingCase
+		for _, sf := range pkg.SourceFiles() {
+			src, err := gocovutil.ReadSource(fsys, sf.File)
+			if err != nil {
+				return err
+			}
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, sf.File, src, 0)
+			if err != nil {
+				return err
+			}
+			cases = append(cases, missingCases(fset, file, src, sf.Functions)...)
+		}
+		sort.SliceStable(cases, func(i, j int) bool {
+			a, b := cases[i].fn, cases[j].fn
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.File < b.File
+		})
+		for _, c := range cases {
+			fmt.Fprintf(tw, "%s/%s\t %s\t line %d: missing %s\n", pkg.Name, filepath.Base(c.fn.File), c.fn.Name, c.line, c.label)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestMissingCases(t *testing.T) {
+	src := []byte(`package p
+
+func F(x interface{}) {
+	switch x.(type) {
+	case int, uint:
+		println(1)
+	case string:
+		println(2)
+	default:
+		println(3)
+	}
+}
+`)
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := &gocov.Function{Name: "F", File: "p.go", Start: 11, End: len(src) - 1, Statements: []*gocov.Statement{
+		{Start: 73, End: 83},
+		{Start: 100, End: 110, Reached: 1},
+		{Start: 123, End: 133},
+	}}
+	cases := missingCases(fset, file, src, []*gocov.Function{fn})
+	var got []string
+	for _, c := range cases {
+		got = append(got, fmt.Sprintf("%d %s", c.line, c.label))
+	}
+	want := []string{"5 case int, uint", "9 default"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("missingCases = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// mutantMapBlock describes a statement extent, with the fields of the
+// blocks of cover profiles, and the tests that reached it.
+type mutantMapBlock struct {
+	StartLine, StartCol int
+	EndLine, EndCol     int
+	Tests               []string
+}
+
+// mutantMap maps the cover profile name of each Go source file, its
+// package's import path and base name, to its statement extents. These
+// are the names and positions by which gremlins and go-mutesting locate
+// the code they mutate.
+type mutantMap map[string][]mutantMapBlock
+
+// testName returns the test named by a merge input: the value of its
+// "test" label if it has one, otherwise the input name itself.
+func testName(input string) string {
+	for _, pair := range strings.Split(input, ",") {
+		if strings.HasPrefix(pair, "test=") {
+			return pair[len("test="):]
+		}
+	}
+	return input
+}
+
+// buildMutantMap returns, for each statement in the report, the names of
+// the tests that reached it. Per-test data is taken from the attribution
+// recorded by "gocov merge -attribute", so statements list no tests if
+// the report has none. Packages of languages other than Go are skipped.
+func buildMutantMap(r *report, sources *sourceFiles) (mutantMap, error) {
+	m := make(mutantMap)
+	sources.preload(r.packages)
+	for _, pkg := range r.packages {
+		if !pkg.IsGo() {
+			continue
+		}
+		for _, file := range pkg.SourceFiles() {
+			name := pkg.Name + "/" + filepath.Base(file.File)
+			for _, fn := range file.Functions {
+				for _, stmt := range fn.Statements {
+					start, err := sources.position(file.File, stmt.Start)
+					if err != nil {
+						return nil, err
+					}
+					end, err := sources.position(file.File, stmt.End)
+					if err != nil {
+						return nil, err
+					}
+					tests := []string{}
+					for i, input := range r.inputs {
+						if stmt.AttributedTo(i) {
+							tests = append(tests, testName(input))
+						}
+					}
+					m[name] = append(m[name], mutantMapBlock{
+						StartLine: start.Line,
+						StartCol:  start.Column,
+						EndLine:   end.Line,
+						EndCol:    end.Column,
+						Tests:     tests,
+					})
+				}
+			}
+		}
+	}
+	for _, blocks := range m {
+		sort.Slice(blocks, func(i, j int) bool {
+			if blocks[i].StartLine != blocks[j].StartLine {
+				return blocks[i].StartLine < blocks[j].StartLine
+			}
+			return blocks[i].StartCol < blocks[j].StartCol
+		})
+	}
+	return m, nil
+}
+
+// printMutantMap writes the mutant map of the report as JSON.
+func printMutantMap(w io.Writer, r *report) error {
+	m, err := buildMutantMap(r, newSourceFiles())
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
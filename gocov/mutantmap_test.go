@@ -0,0 +1,98 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"go/token"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestMutantMap(t *testing.T) {
+	src := "package p\n\nfunc F(x int) int {\n\tif x > 0 {\n\t\treturn x\n\t}\n\treturn 0\n}\n"
+	offset := func(s string) int { return strings.Index(src, s) }
+	r := newReport()
+	r.inputs = []string{"suite=unit,test=TestNegative", "test=TestPositive"}
+	r.addPackage(&gocov.Package{Name: "example.com/p", Functions: []*gocov.Function{{
+		Name: "F", File: "/src/p/p.go",
+		Statements: []*gocov.Statement{
+			{Start: offset("if"), End: offset(" {\n\t\treturn"), Reached: 2, Attribution: []uint64{3}},
+			{Start: offset("return x"), End: offset("\n\t}"), Reached: 1, Attribution: []uint64{2}},
+			{Start: offset("return 0"), End: offset("\n}"), Reached: 1, Attribution: []uint64{1}},
+		},
+	}}})
+	r.addPackage(&gocov.Package{Name: "/src/web", Language: "javascript", Functions: []*gocov.Function{{
+		Name: "render", File: "/src/web/app.js",
+		Statements: []*gocov.Statement{{Start: 0, End: 1, Reached: 1}},
+	}}})
+	sources := &sourceFiles{
+		fset:  token.NewFileSet(),
+		files: make(map[string]*token.File),
+		fsys:  fstest.MapFS{"src/p/p.go": {Data: []byte(src)}},
+	}
+	m, err := buildMutantMap(r, sources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "example.com/p/p.go": [
+    {
+      "StartLine": 4,
+      "StartCol": 2,
+      "EndLine": 4,
+      "EndCol": 10,
+      "Tests": [
+        "TestNegative",
+        "TestPositive"
+      ]
+    },
+    {
+      "StartLine": 5,
+      "StartCol": 3,
+      "EndLine": 5,
+      "EndCol": 11,
+      "Tests": [
+        "TestPositive"
+      ]
+    },
+    {
+      "StartLine": 7,
+      "StartCol": 2,
+      "EndLine": 7,
+      "EndCol": 10,
+      "Tests": [
+        "TestNegative"
+      ]
+    }
+  ]
+}`
+	if string(data) != want {
+		t.Errorf("mutant map:\n%s\nwant:\n%s", data, want)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+func TestNotifyTarget(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, &got)
+	}))
+	defer server.Close()
+
+	s := &notifySummary{Reached: 1, Total: 4, Percent: 25, MinCoverage: 50, Failed: true}
+	target := &notifyTarget{Kind: "slack", URL: server.URL}
+	if err := target.post(server.Client(), s); err != nil {
+		t.Fatal(err)
+	}
+	want := "Coverage check failed: total coverage 25.00% (1/4), below the minimum of 50.00%"
+	if got["text"] != want {
+		t.Errorf("text = %q, want %q", got["text"], want)
+	}
+
+	target = &notifyTarget{Kind: "webhook", URL: server.URL, Template: "{{.Reached}} of {{.Total}}"}
+	if err := target.post(server.Client(), s); err != nil {
+		t.Fatal(err)
+	}
+	if got["text"] != "1 of 4" || got["summary"] == nil {
+		t.Errorf("unexpected webhook payload %v", got)
+	}
+
+	target = &notifyTarget{Kind: "teams", On: "failure"}
+	if ok, err := target.notifies(&notifySummary{}); ok || err != nil {
+		t.Errorf("notifies = %v, %v; want false, nil", ok, err)
+	}
+}
+
+func TestEmailMessage(t *testing.T) {
+	got := string(emailMessage("ci@example.com", []string{"a@example.com", "b@example.com"}, "Coverage", "line 1\nline 2\n"))
+	want := "From: ci@example.com\r\n" +
+		"To: a@example.com, b@example.com\r\n" +
+		"Subject: Coverage\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/markdown; charset=utf-8\r\n" +
+		"\r\n" +
+		"line 1\r\nline 2\r\n"
+	if got != want {
+		t.Errorf("emailMessage = %q, want %q", got, want)
+	}
+}
+
+func TestPackageRegressions(t *testing.T) {
+	packages := []packageSummary{
+		{Name: "dropped", Reached: 40, Total: 100, Percent: 40},
+		{Name: "improved", Reached: 45, Total: 100, Percent: 45},
+		{Name: "passing", Reached: 90, Total: 100, Percent: 90},
+		{Name: "new", Reached: 10, Total: 100, Percent: 10},
+	}
+	baseline := &gocovutil.Document{Packages: gocovutil.Packages{
+		{Name: "dropped", Functions: []*gocov.Function{{Statements: []*gocov.Statement{{Reached: 1}}}}},
+		{Name: "improved", Functions: []*gocov.Function{{Statements: []*gocov.Statement{{}}}}},
+		{Name: "passing"},
+	}}
+	regressions := packageRegressions(baseline, packages, 50)
+	if len(regressions) != 1 || regressions[0].Name != "dropped" || regressions[0].Before != 100 {
+		t.Errorf("Expected only dropped to regress from 100%%, got %v", regressions)
+	}
+	if got := len(packageRegressions(nil, packages, 50)); got != 3 {
+		t.Errorf("Expected 3 regressions without a baseline, got %d", got)
+	}
+}
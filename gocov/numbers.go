@@ -0,0 +1,129 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+// percentFormat formats coverage percentages.
+//
+// Percentages are computed exactly from the statement counts and rounded
+// down (toward zero) to the format's precision, so that a percentage
+// is never shown higher than it is: coverage shown as 80.00% is at least
+// 80%, whereas 79.999% is shown as 79.99%. All of gocov's outputs format
+// coverage percentages, and changes in coverage, this way; only
+// statistics estimated from them, such as the standard deviations of
+// "gocov stability", are rounded as floating-point numbers.
+type percentFormat struct {
+	// precision is the number of decimal places.
+	precision int
+
+	// perMille formats coverage in parts per thousand (‰) instead.
+	perMille bool
+
+	// decimal is the decimal separator.
+	decimal string
+}
+
+var defaultPercentFormat = percentFormat{precision: 2, decimal: "."}
+
+// decimalCommaLanguages are the languages whose locales write decimals
+// with a comma.
+var decimalCommaLanguages = map[string]bool{
+	"cs": true, "da": true, "de": true, "es": true, "fi": true, "fr": true,
+	"id": true, "it": true, "nb": true, "nl": true, "pl": true, "pt": true,
+	"ru": true, "sv": true, "tr": true, "uk": true,
+}
+
+// parsePercentFormat returns the format selected by the -precision,
+// -per-mille and -locale flags. The locale, e.g. de_DE or fr-CA, only
+// selects the decimal separator.
+func parsePercentFormat(precision int, perMille bool, locale string) (percentFormat, error) {
+	if precision < 0 || precision > 10 {
+		return percentFormat{}, fmt.Errorf("invalid precision %d, expected 0 to 10", precision)
+	}
+	f := percentFormat{precision: precision, perMille: perMille, decimal: "."}
+	language := locale
+	if i := strings.IndexAny(language, "_-."); i >= 0 {
+		language = language[:i]
+	}
+	if decimalCommaLanguages[strings.ToLower(language)] {
+		f.decimal = ","
+	}
+	return f, nil
+}
+
+// format formats reached as a percentage, or per-mille, of total, which
+// is 0 if total is 0.
+func (f percentFormat) format(reached, total int) string {
+	return f.formatExact(gocovutil.ExactPercentage(reached, total))
+}
+
+// formatExact formats an exact percentage, or its per-mille, rounded
+// toward zero.
+func (f percentFormat) formatExact(p *big.Rat) string {
+	if f.perMille {
+		return gocovutil.FormatDecimal(new(big.Rat).Mul(p, big.NewRat(10, 1)), f.precision, f.decimal) + "‰"
+	}
+	return gocovutil.FormatDecimal(p, f.precision, f.decimal) + "%"
+}
+
+// formatPercentage formats reached as a percentage of total with two
+// decimal places; see percentFormat.
+func formatPercentage(reached, total int) string {
+	return defaultPercentFormat.format(reached, total)
+}
+
+// formatChange formats the change in percentage points from the coverage
+// of beforeReached of beforeTotal statements to that of afterReached of
+// afterTotal, signed and, like percentages, rounded toward zero.
+func formatChange(beforeReached, beforeTotal, afterReached, afterTotal int) string {
+	d := new(big.Rat).Sub(gocovutil.ExactPercentage(afterReached, afterTotal), gocovutil.ExactPercentage(beforeReached, beforeTotal))
+	s := strings.TrimSuffix(defaultPercentFormat.formatExact(d), "%")
+	if !strings.HasPrefix(s, "-") {
+		s = "+" + s
+	}
+	return s
+}
+
+// belowThreshold reports whether the coverage of reached statements of
+// total is below the threshold percentage, comparing exactly: 57 of 100
+// statements meet a threshold of 57.
+func belowThreshold(reached, total int, threshold float64) bool {
+	return gocovutil.ExactPercentage(reached, total).Cmp(gocovutil.ExactDecimal(threshold)) < 0
+}
+
+// floorPercentage returns the coverage of reached statements of total,
+// less margin percentage points, rounded down exactly to a multiple of
+// step.
+func floorPercentage(reached, total int, margin, step float64) float64 {
+	q := new(big.Rat).Sub(gocovutil.ExactPercentage(reached, total), gocovutil.ExactDecimal(margin))
+	q.Quo(q, gocovutil.ExactDecimal(step))
+	// Euclidean division by the positive denominator rounds down.
+	n := new(big.Int).Div(q.Num(), q.Denom())
+	f, _ := new(big.Rat).Mul(new(big.Rat).SetInt(n), gocovutil.ExactDecimal(step)).Float64()
+	return f
+}
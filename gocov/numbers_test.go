@@ -0,0 +1,81 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestPercentFormat(t *testing.T) {
+	tests := []struct {
+		precision int
+		perMille  bool
+		locale    string
+		want      string
+	}{
+		{2, false, "", "79.95%"},
+		{1, false, "", "79.9%"},
+		{3, false, "de_DE.UTF-8", "79,950%"},
+		{1, true, "fr-CA", "799,5‰"},
+		{0, true, "en_US", "799‰"},
+	}
+	for _, test := range tests {
+		f, err := parsePercentFormat(test.precision, test.perMille, test.locale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := f.format(7995, 10000); got != test.want {
+			t.Errorf("format(%d, %v, %q) = %q, want %q", test.precision, test.perMille, test.locale, got, test.want)
+		}
+	}
+	// Percentages are rounded down, exactly.
+	for _, test := range []struct {
+		reached, total int
+		want           string
+	}{
+		{2, 3, "66.66%"},
+		{79999, 100000, "79.99%"},
+		{1, 1000000, "0.00%"},
+		{3, 3, "100.00%"},
+		{0, 0, "0.00%"},
+	} {
+		if got := formatPercentage(test.reached, test.total); got != test.want {
+			t.Errorf("formatPercentage(%d, %d) = %q, want %q", test.reached, test.total, got, test.want)
+		}
+	}
+	// Changes are rounded toward zero too, keeping their sign.
+	for _, test := range []struct {
+		beforeReached, beforeTotal, afterReached, afterTotal int
+		want                                                 string
+	}{
+		{1, 2, 2, 3, "+16.66"},
+		{2, 3, 1, 2, "-16.66"},
+		{80000, 100000, 79999, 100000, "-0.00"},
+		{1, 2, 2, 4, "+0.00"},
+	} {
+		if got := formatChange(test.beforeReached, test.beforeTotal, test.afterReached, test.afterTotal); got != test.want {
+			t.Errorf("formatChange(%d, %d, %d, %d) = %q, want %q", test.beforeReached, test.beforeTotal, test.afterReached, test.afterTotal, got, test.want)
+		}
+	}
+	if _, err := parsePercentFormat(-1, false, ""); err == nil {
+		t.Error("parsePercentFormat accepted a negative precision")
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var (
+	ratchetFlags      = flag.NewFlagSet("ratchet", flag.ExitOnError)
+	ratchetConfigFlag = ratchetFlags.String(
+		"config", defaultConfigFile,
+		"Configuration `file` holding the thresholds to raise")
+	ratchetMarginFlag = ratchetFlags.Float64(
+		"margin", 0,
+		"Raise each threshold to this many percentage points below the current coverage")
+	ratchetStepFlag = ratchetFlags.Float64(
+		"step", 1,
+		"Round raised thresholds down to a multiple of this many percentage points")
+	ratchetWriteFlag = ratchetFlags.Bool(
+		"write", false,
+		"Rewrite the configuration file with the raised thresholds")
+	ratchetDryRunFlag = ratchetFlags.Bool(
+		"dry-run", false,
+		"With -write, print the configuration file it would write instead of writing it")
+)
+
+// thresholdRaise is a threshold raised by ratchetThresholds.
+type thresholdRaise struct {
+	name          string
+	before, after float64
+}
+
+// ratchetThresholds returns the configured thresholds of packages and
+// package groups whose coverage has improved, raised to the margin below
+// their current coverage rounded down to a multiple of step. Thresholds
+// are never lowered, and those of packages absent from the coverage are
+// left alone. A threshold naming both a package and a group applies to
+// both, and so is raised no further than the lower coverage allows.
+func ratchetThresholds(doc *gocovutil.Document, cfg *config, margin, step float64) []thresholdRaise {
+	packages := doc.Packages
+	if len(cfg.Groups) > 0 {
+		packages = append(packages, cfg.Groups.groupPackages(doc.Packages)...)
+	}
+	floors := make(map[string]float64)
+	for _, pkg := range packages {
+		if _, ok := cfg.Thresholds[pkg.Name]; !ok {
+			continue
+		}
+		reached, total := coverageCounts(pkg)
+		if total == 0 {
+			continue
+		}
+		floor := floorPercentage(reached, total, margin, step)
+		if before, ok := floors[pkg.Name]; !ok || floor < before {
+			floors[pkg.Name] = floor
+		}
+	}
+	var raised []thresholdRaise
+	for name, after := range floors {
+		if threshold := cfg.Thresholds[name]; after > threshold {
+			raised = append(raised, thresholdRaise{name, threshold, after})
+		}
+	}
+	sort.Slice(raised, func(i, j int) bool {
+		return raised[i].name < raised[j].name
+	})
+	return raised
+}
+
+// printRaises lists the raised thresholds.
+func printRaises(w io.Writer, raised []thresholdRaise) {
+	if len(raised) == 0 {
+		fmt.Fprintln(w, "no thresholds to raise")
+		return
+	}
+	for _, r := range raised {
+		fmt.Fprintf(w, "%s: %s%% → %s%%\n", r.name, strconv.FormatFloat(r.before, 'f', -1, 64), strconv.FormatFloat(r.after, 'f', -1, 64))
+	}
+}
+
+// ratchetCoverage raises the configured thresholds of packages whose
+// coverage improved, locking in the gains when run by a scheduled job.
+// Without -write the raises are only listed; -dry-run, which requires
+// -write, prints the file that would be written.
+func ratchetCoverage() (rc int) {
+	ratchetFlags.Parse(os.Args[2:])
+	if *ratchetDryRunFlag && !*ratchetWriteFlag {
+		fmt.Fprintln(os.Stderr, "-dry-run requires -write")
+		return 1
+	}
+	if *ratchetStepFlag <= 0 {
+		fmt.Fprintln(os.Stderr, "-step must be positive")
+		return 1
+	}
+	filename := "-"
+	if ratchetFlags.NArg() > 0 {
+		filename = ratchetFlags.Arg(0)
+	}
+	doc, err := gocovutil.ReadDocument(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read coverage file (%s): %s\n", filename, err)
+		return 1
+	}
+	cfg, err := loadConfig(*ratchetConfigFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read configuration: %s\n", err)
+		return 1
+	}
+	raised := ratchetThresholds(doc, cfg, *ratchetMarginFlag, *ratchetStepFlag)
+	printRaises(os.Stdout, raised)
+	if !*ratchetWriteFlag || len(raised) == 0 {
+		return 0
+	}
+	data, err := ioutil.ReadFile(*ratchetConfigFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read configuration: %s\n", err)
+		return 1
+	}
+	values := make(map[string]float64, len(raised))
+	for _, r := range raised {
+		values[r.name] = r.after
+	}
+	data, err = setThresholds(data, values)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to rewrite thresholds: %s\n", err)
+		return 1
+	}
+	if *ratchetDryRunFlag {
+		fmt.Printf("dry run: write %s\n\n%s", *ratchetConfigFlag, data)
+		return 0
+	}
+	if err := ioutil.WriteFile(*ratchetConfigFlag, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write configuration: %s\n", err)
+		return 1
+	}
+	return 0
+}
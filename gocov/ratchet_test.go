@@ -0,0 +1,132 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+func TestRatchetThresholds(t *testing.T) {
+	doc := &gocovutil.Document{Packages: gocovutil.Packages{
+		{Name: "a", Functions: []*gocov.Function{{Statements: []*gocov.Statement{{Reached: 1}, {Reached: 1}, {}}}}},
+		{Name: "b", Functions: []*gocov.Function{{Statements: []*gocov.Statement{{}}}}},
+	}}
+	cfg := &config{
+		Thresholds: map[string]float64{"a": 50, "b": 10, "g": 20, "missing": 5},
+		Groups:     packageGroups{"g": {"a", "b"}},
+	}
+	got := ratchetThresholds(doc, cfg, 1, 2.5)
+	want := []thresholdRaise{{"a", 50, 65}, {"g", 20, 47.5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ratchetThresholds = %v, want %v", got, want)
+	}
+
+	// The threshold of g also applies to a fully covered package g, and
+	// so is raised once, as far as group g allows.
+	withG := &gocovutil.Document{Packages: append(gocovutil.Packages{
+		{Name: "g", Functions: []*gocov.Function{{Statements: []*gocov.Statement{{Reached: 1}}}}},
+	}, doc.Packages...)}
+	if got := ratchetThresholds(withG, cfg, 1, 2.5); !reflect.DeepEqual(got, want) {
+		t.Errorf("ratchetThresholds = %v, want %v", got, want)
+	}
+
+	data := []byte("# floors\nthresholds:\n  a: 50 # for now\n  b: 10\n  g: 20\n")
+	out, err := setThresholds(data, map[string]float64{"a": 65, "g": 47.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# floors\nthresholds:\n  a: 65 # for now\n  b: 10\n  g: 47.5\n"; string(out) != want {
+		t.Errorf("setThresholds = %q, want %q", out, want)
+	}
+
+	// 57 of 100 statements are exactly 57%, not the 56.99999999999999
+	// of floating point.
+	fn := &gocov.Function{}
+	for i := 0; i < 100; i++ {
+		stmt := &gocov.Statement{}
+		if i < 57 {
+			stmt.Reached = 1
+		}
+		fn.Statements = append(fn.Statements, stmt)
+	}
+	doc = &gocovutil.Document{Packages: gocovutil.Packages{{Name: "p", Functions: []*gocov.Function{fn}}}}
+	cfg = &config{Thresholds: map[string]float64{"p": 50}}
+	got = ratchetThresholds(doc, cfg, 0, 1)
+	if want := []thresholdRaise{{"p", 50, 57}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ratchetThresholds = %v, want %v", got, want)
+	}
+	got = ratchetThresholds(doc, cfg, 0.1, 0.1)
+	if want := []thresholdRaise{{"p", 50, 56.9}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ratchetThresholds = %v, want %v", got, want)
+	}
+}
+
+func TestSetThresholds(t *testing.T) {
+	tests := []struct {
+		data   string
+		values map[string]float64
+		want   string
+	}{
+		// The layout of the file is kept.
+		{
+			"thresholds:\n    a:   50   # floor\n\n    b: 10\nother: {x: 1}\n",
+			map[string]float64{"a": 62.5},
+			"thresholds:\n    a:   62.5   # floor\n\n    b: 10\nother: {x: 1}\n",
+		},
+		{
+			"thresholds:\n    a: 50\nmin_hits:\n    a: 1",
+			map[string]float64{"c": 1, "b": 2},
+			"thresholds:\n    a: 50\n    b: 2\n    c: 1\nmin_hits:\n    a: 1",
+		},
+		{
+			"thresholds:\n  a: 50",
+			map[string]float64{"b": 2},
+			"thresholds:\n  a: 50\n  b: 2\n",
+		},
+		{
+			"groups:\n  g: [a]",
+			map[string]float64{"g": 20},
+			"groups:\n  g: [a]\nthresholds:\n  g: 20\n",
+		},
+		{
+			"",
+			map[string]float64{"a": 1},
+			"thresholds:\n  a: 1\n",
+		},
+	}
+	for _, test := range tests {
+		got, err := setThresholds([]byte(test.data), test.values)
+		if err != nil {
+			t.Errorf("setThresholds(%q): %v", test.data, err)
+		} else if string(got) != test.want {
+			t.Errorf("setThresholds(%q) = %q, want %q", test.data, got, test.want)
+		}
+	}
+	for _, data := range []string{"thresholds: {a: 50}", "thresholds:\n  a: \"50\"", "- a"} {
+		if _, err := setThresholds([]byte(data), map[string]float64{"a": 60, "b": 1}); err == nil {
+			t.Errorf("Expected an error setting the thresholds of %q", data)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var (
+	releaseNotesFlags    = flag.NewFlagSet("release-notes", flag.ExitOnError)
+	releaseNotesFromFlag = releaseNotesFlags.String(
+		"from", "",
+		"Baseline to compare from: a git revision or a coverage file")
+	releaseNotesToFlag = releaseNotesFlags.String(
+		"to", "HEAD",
+		"Baseline to compare to: a git revision or a coverage file")
+	releaseNotesTopFlag = releaseNotesFlags.Int(
+		"top", 5,
+		"Number of biggest gains and losses to list")
+	releaseNotesByGroupFlag = releaseNotesFlags.Bool(
+		"by-group", false,
+		"Compare the package groups of the configuration instead of packages")
+	releaseNotesConfigFlag = releaseNotesFlags.String(
+		"config", defaultConfigFile,
+		"Configuration `file` defining the package groups of -by-group")
+)
+
+// coverageCounts returns the number of statements in pkg that were
+// reached, and the total number of statements.
+func coverageCounts(pkg *gocov.Package) (reached, total int) {
+	for _, fn := range pkg.Functions {
+		for _, stmt := range fn.Statements {
+			if stmt.Reached > 0 {
+				reached++
+			}
+		}
+		total += len(fn.Statements)
+	}
+	return reached, total
+}
+
+// percentage returns reached as a percentage of total, or 0 if total is 0.
+func percentage(reached, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(reached) / float64(total) * 100
+}
+
+// git runs a git command in dir, returning its trimmed standard output.
+func git(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// loadBaseline returns the coverage of a baseline, given either a coverage
+// file or a git revision. A revision is checked out into a temporary
+// worktree, where "gocov test" is run with the given arguments.
+func loadBaseline(spec string, testArgs []string) (*gocovutil.Document, error) {
+	if _, err := os.Stat(spec); err == nil {
+		return gocovutil.ReadDocument(spec)
+	}
+	if _, err := git("", "rev-parse", "--verify", spec+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("%s is neither a coverage file nor a git revision", spec)
+	}
+	prefix, err := git("", "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	tmpDir, err := ioutil.TempDir("", "gocov-baseline")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	worktree := filepath.Join(tmpDir, "src")
+	if _, err := git("", "worktree", "add", "--detach", worktree, spec); err != nil {
+		return nil, err
+	}
+	defer git("", "worktree", "remove", "--force", worktree)
+
+	out, err := testCoverage(filepath.Join(worktree, prefix), testArgs, nil)
+	if err != nil {
+		return nil, fmt.Errorf("testing %s: %v", spec, err)
+	}
+	return unmarshalDocument(out)
+}
+
+// packageChange describes the change in coverage of a package.
+type packageChange struct {
+	name          string
+	before, after float64
+
+	// The statement counts of the package, from which its coverage is
+	// formatted.
+	beforeReached, beforeTotal int
+	afterReached, afterTotal   int
+}
+
+// printReleaseNotes writes a markdown summary of the coverage changes
+// between two baselines.
+func printReleaseNotes(w io.Writer, fromName, toName string, from, to *gocovutil.Document, top int) {
+	type counts struct{ reached, total int }
+	fromCounts := make(map[string]counts)
+	var fromTotal, toTotal counts
+	for _, pkg := range from.Packages {
+		reached, total := coverageCounts(pkg)
+		fromCounts[pkg.Name] = counts{reached, total}
+		fromTotal.reached += reached
+		fromTotal.total += total
+	}
+
+	var added, changed []packageChange
+	seen := make(map[string]bool)
+	for _, pkg := range to.Packages {
+		reached, total := coverageCounts(pkg)
+		toTotal.reached += reached
+		toTotal.total += total
+		seen[pkg.Name] = true
+		c := packageChange{name: pkg.Name, after: percentage(reached, total), afterReached: reached, afterTotal: total}
+		if before, ok := fromCounts[pkg.Name]; ok {
+			c.before = percentage(before.reached, before.total)
+			c.beforeReached, c.beforeTotal = before.reached, before.total
+			changed = append(changed, c)
+		} else {
+			added = append(added, c)
+		}
+	}
+	var removed []string
+	for _, pkg := range from.Packages {
+		if !seen[pkg.Name] {
+			removed = append(removed, pkg.Name)
+		}
+	}
+
+	fmt.Fprintf(w, "## Coverage changes %s...%s\n\n", fromName, toName)
+	fmt.Fprintf(w, "Total coverage: %s → %s (%s)\n",
+		formatPercentage(fromTotal.reached, fromTotal.total), formatPercentage(toTotal.reached, toTotal.total),
+		formatChange(fromTotal.reached, fromTotal.total, toTotal.reached, toTotal.total))
+
+	if len(added) > 0 {
+		fmt.Fprintf(w, "\n### New packages\n\n| Package | Coverage |\n| --- | ---: |\n")
+		for _, c := range added {
+			fmt.Fprintf(w, "| %s | %s |\n", c.name, formatPercentage(c.afterReached, c.afterTotal))
+		}
+	}
+	if len(removed) > 0 {
+		fmt.Fprintf(w, "\n### Removed packages\n\n")
+		for _, name := range removed {
+			fmt.Fprintf(w, "- %s\n", name)
+		}
+	}
+
+	sort.SliceStable(changed, func(i, j int) bool {
+		return changed[i].after-changed[i].before > changed[j].after-changed[j].before
+	})
+	printChanges := func(title string, changes []packageChange) {
+		if len(changes) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "\n### %s\n\n| Package | Before | After | Change |\n| --- | ---: | ---: | ---: |\n", title)
+		for _, c := range changes {
+			fmt.Fprintf(w, "| %s | %s | %s | %s |\n", c.name,
+				formatPercentage(c.beforeReached, c.beforeTotal), formatPercentage(c.afterReached, c.afterTotal),
+				formatChange(c.beforeReached, c.beforeTotal, c.afterReached, c.afterTotal))
+		}
+	}
+	var gains, losses []packageChange
+	for i := 0; i < len(changed) && len(gains) < top && changed[i].after > changed[i].before; i++ {
+		gains = append(gains, changed[i])
+	}
+	for i := len(changed) - 1; i >= 0 && len(losses) < top && changed[i].after < changed[i].before; i-- {
+		losses = append(losses, changed[i])
+	}
+	printChanges("Biggest gains", gains)
+	printChanges("Biggest losses", losses)
+	printFunctionChanges(w, gocovutil.DiffFunctions(from.Packages, to.Packages), top)
+}
+
+// qualifiedFunction returns the name of a function qualified by its package,
+// and the base name of its file.
+func qualifiedFunction(f gocovutil.FunctionRef) string {
+	return fmt.Sprintf("%s.%s (%s)", f.Package, f.Function, filepath.Base(f.File))
+}
+
+// printFunctionChanges writes the functions moved, added and removed
+// between two baselines, listing the top added and removed functions by
+// number of statements. Moved and renamed functions are listed as moves,
+// with their coverage before and after, rather than as a removed function
+// and an added one.
+func printFunctionChanges(w io.Writer, d *gocovutil.FunctionDiff, top int) {
+	if len(d.Moved) > 0 {
+		fmt.Fprintf(w, "\n### Moved functions\n\n| Function | Moved to | Before | After |\n| --- | --- | ---: | ---: |\n")
+		for _, m := range d.Moved {
+			fmt.Fprintf(w, "| %s | %s | %s | %s |\n", qualifiedFunction(m.From), qualifiedFunction(m.To),
+				formatPercentage(m.From.Reached, m.From.Statements), formatPercentage(m.To.Reached, m.To.Statements))
+		}
+	}
+	largest := func(functions []gocovutil.FunctionRef) []gocovutil.FunctionRef {
+		functions = append([]gocovutil.FunctionRef(nil), functions...)
+		sort.SliceStable(functions, func(i, j int) bool {
+			return functions[i].Statements > functions[j].Statements
+		})
+		return functions
+	}
+	printFunctions := func(title string, functions []gocovutil.FunctionRef) {
+		if len(functions) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "\n### %s\n\n| Function | Statements | Coverage |\n| --- | ---: | ---: |\n", title)
+		for i, f := range largest(functions) {
+			if i == top {
+				fmt.Fprintf(w, "\n… and %d more\n", len(functions)-top)
+				break
+			}
+			fmt.Fprintf(w, "| %s | %d | %s |\n", qualifiedFunction(f), f.Statements, formatPercentage(f.Reached, f.Statements))
+		}
+	}
+	printFunctions("New functions", d.Added)
+	printFunctions("Removed functions", d.Removed)
+}
+
+func releaseNotes() (rc int) {
+	releaseNotesFlags.Parse(os.Args[2:])
+	if *releaseNotesFromFlag == "" {
+		fmt.Fprintf(os.Stderr, "missing -from baseline\n")
+		return 1
+	}
+	testArgs := releaseNotesFlags.Args()
+	if len(testArgs) == 0 {
+		testArgs = []string{"./..."}
+	}
+	from, err := loadBaseline(*releaseNotesFromFlag, testArgs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	to, err := loadBaseline(*releaseNotesToFlag, testArgs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	if *releaseNotesByGroupFlag {
+		groups, err := loadGroups(*releaseNotesConfigFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		from, to = groups.groupDocument(from), groups.groupDocument(to)
+	}
+	printReleaseNotes(os.Stdout, *releaseNotesFromFlag, *releaseNotesToFlag, from, to, *releaseNotesTopFlag)
+	return 0
+}
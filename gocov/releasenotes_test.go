@@ -0,0 +1,56 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+func TestPrintFunctionChanges(t *testing.T) {
+	fn := func(name, file string, reached ...int64) *gocov.Function {
+		f := &gocov.Function{Name: name, File: file, ID: gocovutil.FunctionID("p", file, name, "()")}
+		for i, r := range reached {
+			f.Statements = append(f.Statements, &gocov.Statement{Start: i * 10, End: i*10 + 5, Reached: r})
+		}
+		return f
+	}
+	from := gocovutil.Packages{{Name: "p", Functions: []*gocov.Function{
+		fn("Old", "/src/p/p.go", 1, 1, 1, 0),
+	}}}
+	to := gocovutil.Packages{{Name: "p", Functions: []*gocov.Function{
+		fn("Renamed", "/src/p/q.go", 1, 1, 0, 0),
+		fn("A", "/src/p/p.go", 1),
+		fn("B", "/src/p/p.go", 0, 0),
+	}}}
+	var buf bytes.Buffer
+	printFunctionChanges(&buf, gocovutil.DiffFunctions(from, to), 1)
+	want := "\n### Moved functions\n\n| Function | Moved to | Before | After |\n| --- | --- | ---: | ---: |\n" +
+		"| p.Old (p.go) | p.Renamed (q.go) | 75.00% | 50.00% |\n" +
+		"\n### New functions\n\n| Function | Statements | Coverage |\n| --- | ---: | ---: |\n" +
+		"| p.B (p.go) | 2 | 0.00% |\n\n… and 1 more\n"
+	if buf.String() != want {
+		t.Errorf("printFunctionChanges = %q, want %q", buf.String(), want)
+	}
+}
@@ -23,24 +23,124 @@ package main
 import (
 	"flag"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io"
 	"io/ioutil"
-	"math"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 
 	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocov/convert"
+	"github.com/hihoak/gocov/gocovutil"
 )
 
+var (
+	reportFlags      = flag.NewFlagSet("report", flag.ExitOnError)
+	reportFormatFlag = reportFlags.String(
+		"format", "text",
+		"Output format: text, blame, breakdown, cobertura, dead-code, flaky, histogram, implementations, influx, missing-cases, smoke, test-order, or mutant-map")
+	reportExcludeMainsFlag = reportFlags.Bool(
+		"exclude-mains", false,
+		"Exclude main packages under the -mains-dir directory")
+	reportMainsDirFlag = reportFlags.String(
+		"mains-dir", "cmd",
+		"Directory whose main packages -exclude-mains excludes; empty for all")
+	reportSourceRootFlag = reportFlags.String(
+		"source-root", "",
+		"Read source files relative to this directory instead of the file system root")
+	reportFoldClosuresFlag = reportFlags.Bool(
+		"fold-closures", false,
+		"Roll the coverage of function literals into their enclosing functions")
+	reportMinStatementsFlag = reportFlags.Int(
+		"min-statements", 0,
+		"Ignore functions with fewer than this many statements")
+	reportVerifyKeyFlag = reportFlags.String(
+		"verify-key", "",
+		"Require inputs to be signed by the private key of this ed25519 public key file")
+	reportExclusionsFlag = reportFlags.String(
+		"exclusions-out", "",
+		"Write a JSON report of the packages and functions excluded from the report, and why, to this file")
+	reportTemplateFlag = reportFlags.String(
+		"template", "",
+		"Render the report with the text/template in this `file` instead of -format")
+	reportColumnsFlag = reportFlags.String(
+		"columns", "file,function,coverage",
+		"Comma-separated columns of the text report: file, function, coverage, percent, reached, statements, complexity")
+	reportSortFlag = reportFlags.String(
+		"sort", "-percent",
+		"Sort functions in the text report by this column, descending if prefixed with -")
+	reportInterfaceFlag = reportFlags.String(
+		"interface", "",
+		"The interface, as importpath.Name, whose implementations -format=implementations reports")
+	reportPrecisionFlag = reportFlags.Int(
+		"precision", 2,
+		"Decimal places of the coverage percentages in the text report")
+	reportPerMilleFlag = reportFlags.Bool(
+		"per-mille", false,
+		"Show coverage in the text report per mille (‰) instead of per cent")
+	reportLocaleFlag = reportFlags.String(
+		"locale", "",
+		"Format numbers in the text report for this locale, e.g. de_DE")
+	reportTagFlag = reportFlags.String(
+		"tag", "",
+		"Report only statements carrying this tag, e.g. error-path for coverage converted with -classify error-paths")
+	reportGithubSummaryFlag = reportFlags.Bool(
+		"github-summary", false,
+		"Also append a markdown coverage table to the GitHub Actions job summary ($GITHUB_STEP_SUMMARY)")
+	reportBuildkiteFlag = reportFlags.Bool(
+		"buildkite-annotate", false,
+		"Also annotate the Buildkite build with a markdown coverage table, using buildkite-agent")
+	reportCircleCIFlag = reportFlags.String(
+		"circleci-metadata", "",
+		"Also write the package coverage as JUnit XML to this `file`, for CircleCI's store_test_results")
+	reportBaseFlag = reportFlags.String(
+		"base", "",
+		"Coverage file or git revision whose changes -github-summary and -buildkite-annotate report")
+	reportByGroupFlag = reportFlags.Bool(
+		"by-group", false,
+		"Report the package groups of the configuration instead of packages")
+	reportConfigFlag = reportFlags.String(
+		"config", defaultConfigFile,
+		"Configuration `file` defining the package groups of -by-group and their weights")
+	reportSelect selectFlag
+)
+
+func init() {
+	reportFlags.IntVar(&sourceReadJobs, "read-jobs", sourceReadJobs, "Read up to this many source files concurrently")
+	reportFlags.Var(&reportSelect, "select", "Report only documents carrying all of the comma-separated key=value `labels`")
+}
+
 type report struct {
 	packages []*gocov.Package
+
+	// inputs names the inputs that statement attribution refers to.
+	inputs []string
+
+	// labels holds the labels of the reported documents.
+	labels map[string]string
+
+	// exclusions records what the report's filters removed.
+	exclusions []convert.Exclusion
+
+	// grouped holds the packages that -by-group combined into the
+	// report's group packages, from which the total coverage is counted,
+	// so that packages held by several groups count once.
+	grouped []*gocov.Package
+
+	// weight, if set, returns the weight of a package in the weighted
+	// total coverage.
+	weight func(pkg string) float64
 }
 
 type reportFunction struct {
 	*gocov.Function
+	pkg               *gocov.Package
 	statementsReached int
 }
 
@@ -50,7 +150,6 @@ func (l reportFunctionList) Len() int {
 	return len(l)
 }
 
-// TODO make sort method configurable?
 func (l reportFunctionList) Less(i, j int) bool {
 	var left, right float64
 	if len(l[i].Statements) > 0 {
@@ -97,6 +196,135 @@ func (r *report) addPackage(p *gocov.Package) {
 	}
 }
 
+// excludeMains removes the main packages whose import path has dir as a
+// path element from the report. If dir is empty, all main packages are
+// removed.
+func (r *report) excludeMains(dir string) error {
+	var packages []*gocov.Package
+	for _, pkg := range r.packages {
+		if dir != "" && !hasPathElement(pkg.Name, dir) {
+			packages = append(packages, pkg)
+			continue
+		}
+		isMain, err := isMainPackage(pkg)
+		if err != nil {
+			return err
+		}
+		if !isMain {
+			packages = append(packages, pkg)
+			continue
+		}
+		r.exclusions = append(r.exclusions, convert.Exclusion{Package: pkg.Name, Rule: "main package"})
+	}
+	r.packages = packages
+	return nil
+}
+
+// excludeTrivial removes functions with fewer than min statements, such
+// as getters and constructors, from the report.
+func (r *report) excludeTrivial(min int) {
+	for _, pkg := range r.packages {
+		functions := pkg.Functions[:0]
+		for _, fn := range pkg.Functions {
+			if len(fn.Statements) >= min {
+				functions = append(functions, fn)
+				continue
+			}
+			r.exclusions = append(r.exclusions, convert.Exclusion{
+				Package:  pkg.Name,
+				File:     filepath.Base(fn.File),
+				Function: fn.Name,
+				Rule:     fmt.Sprintf("fewer than %d statements", min),
+			})
+		}
+		pkg.Functions = functions
+	}
+}
+
+// selectTag removes the statements not carrying the tag from the report,
+// along with the functions left without statements.
+func (r *report) selectTag(tag string) {
+	for _, pkg := range r.packages {
+		functions := pkg.Functions[:0]
+		for _, fn := range pkg.Functions {
+			var stmts []*gocov.Statement
+			for _, stmt := range fn.Statements {
+				if hasTag(stmt, tag) {
+					stmts = append(stmts, stmt)
+				}
+			}
+			if len(stmts) == 0 {
+				r.exclusions = append(r.exclusions, convert.Exclusion{
+					Package:  pkg.Name,
+					File:     filepath.Base(fn.File),
+					Function: fn.Name,
+					Rule:     fmt.Sprintf("no statements tagged %s", tag),
+				})
+				continue
+			}
+			fn.Statements = stmts
+			functions = append(functions, fn)
+		}
+		pkg.Functions = functions
+	}
+}
+
+func hasTag(stmt *gocov.Statement, tag string) bool {
+	for _, t := range stmt.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// foldClosures rolls the statements of each function literal into the
+// top-level function enclosing it, so that functions are reported with
+// the coverage of their closures.
+func (r *report) foldClosures() {
+	type key struct{ file, name string }
+	for _, pkg := range r.packages {
+		byName := make(map[key]*gocov.Function, len(pkg.Functions))
+		for _, fn := range pkg.Functions {
+			byName[key{fn.File, fn.Name}] = fn
+		}
+		functions := pkg.Functions[:0]
+		for _, fn := range pkg.Functions {
+			outer := fn
+			for outer.Parent != "" && byName[key{fn.File, outer.Parent}] != nil {
+				outer = byName[key{fn.File, outer.Parent}]
+			}
+			if outer == fn {
+				functions = append(functions, fn)
+				continue
+			}
+			outer.Statements = append(outer.Statements, fn.Statements...)
+		}
+		pkg.Functions = functions
+	}
+}
+
+// hasPathElement reports whether the slash-separated path p contains the
+// element, which may itself contain slashes.
+func hasPathElement(p, elem string) bool {
+	elem = strings.Trim(elem, "/")
+	return strings.HasPrefix(p, elem+"/") || strings.Contains(p, "/"+elem+"/") ||
+		strings.HasSuffix(p, "/"+elem) || p == elem
+}
+
+// isMainPackage reports whether pkg is a main package, by reading the
+// package clause of one of its source files.
+func isMainPackage(pkg *gocov.Package) (bool, error) {
+	if len(pkg.Functions) == 0 || !pkg.IsGo() {
+		return false, nil
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), pkg.Functions[0].File, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return false, err
+	}
+	return file.Name.Name == "main", nil
+}
+
 // Clear clears the coverage information from the report.
 func (r *report) clear() {
 	r.packages = nil
@@ -113,19 +341,29 @@ func functionReports(pkg *gocov.Package) reportFunctionList {
 				reached++
 			}
 		}
-		functions[i] = reportFunction{fn, reached}
+		functions[i] = reportFunction{fn, pkg, reached}
 	}
 
 	return functions
 
 }
 
+// ungrouped returns the packages of the report, or those that -by-group
+// combined into its group packages.
+func (r *report) ungrouped() []*gocov.Package {
+	if r.grouped != nil {
+		return r.grouped
+	}
+	return r.packages
+}
+
 // printTotalCoverage outputs the combined coverage for each
 // package
-func (r *report) printTotalCoverage(w io.Writer) {
+func (r *report) printTotalCoverage(w io.Writer, numbers percentFormat) {
 	var totalStatements, totalReached int
 
-	for _, pkg := range r.packages {
+	packages := r.ungrouped()
+	for _, pkg := range packages {
 		functions := functionReports(pkg)
 		sort.Sort(reverse{functions})
 
@@ -136,29 +374,88 @@ func (r *report) printTotalCoverage(w io.Writer) {
 		}
 	}
 
-	coveragePercentage := float64(totalReached) / float64(totalStatements) * 100
-	if math.IsNaN(coveragePercentage) {
-		coveragePercentage = 0
+	fmt.Fprintf(w, "Total Coverage: %s (%d/%d)", numbers.format(totalReached, totalStatements), totalReached, totalStatements)
+	fmt.Fprintln(w)
+	for _, lang := range languageCounts(packages) {
+		fmt.Fprintf(w, "Coverage (%s): %s (%d/%d)", lang.name, numbers.format(lang.reached, lang.total), lang.reached, lang.total)
+		fmt.Fprintln(w)
+	}
+	if r.weight != nil {
+		reached, total := weightedCounts(packages, r.weight)
+		fmt.Fprintf(w, "Weighted Coverage: %s", numbers.format(reached, total))
+		fmt.Fprintln(w)
+	}
+
+	var exportedReached, exportedStatements int
+	for _, pkg := range packages {
+		reached, total := exportedCounts(pkg)
+		exportedReached += reached
+		exportedStatements += total
 	}
-	fmt.Fprintf(w, "Total Coverage: %.2f%% (%d/%d)", coveragePercentage, totalReached, totalStatements)
+	fmt.Fprintf(w, "Exported Coverage: %s (%d/%d)", numbers.format(exportedReached, exportedStatements), exportedReached, exportedStatements)
 	fmt.Fprintln(w)
 }
 
+// isPublicFunction reports whether fn is part of its package's public API:
+// an exported function, or an exported method of an exported type. Where
+// type information was recorded, it also tells apart methods promoted to
+// exported types from the unexported types they are declared on.
+func isPublicFunction(fn *gocov.Function) bool {
+	if fn.Parent != "" {
+		return false
+	}
+	if fn.Exported != nil {
+		return *fn.Exported
+	}
+	for _, name := range strings.Split(gocovutil.WithoutTypeParams(fn.Name), ".") {
+		if !ast.IsExported(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// exportedCounts returns the number of statements of the package's
+// public functions, and of those reached. Internal packages, and those of
+// languages other than Go, have no public API.
+func exportedCounts(pkg *gocov.Package) (reached, total int) {
+	if hasPathElement(pkg.Name, "internal") || !pkg.IsGo() {
+		return 0, 0
+	}
+	for _, fn := range pkg.Functions {
+		if !isPublicFunction(fn) {
+			continue
+		}
+		for _, stmt := range fn.Statements {
+			if stmt.Reached > 0 {
+				reached++
+			}
+		}
+		total += len(fn.Statements)
+	}
+	return reached, total
+}
+
 // PrintReport prints a coverage report to the given writer.
-func printReport(w io.Writer, r *report) {
+func printReport(w io.Writer, r *report, layout *textLayout) error {
 	w = tabwriter.NewWriter(w, 0, 8, 0, '\t', 0)
 	//fmt.Fprintln(w, "Package\tFunction\tStatements\t")
 	//fmt.Fprintln(w, "-------\t--------\t---------\t")
 	for _, pkg := range r.packages {
-		printPackage(w, pkg)
+		if err := printPackage(w, pkg, layout); err != nil {
+			return err
+		}
 		fmt.Fprintln(w)
 	}
-	r.printTotalCoverage(w)
+	r.printTotalCoverage(w, layout.numbers)
+	return nil
 }
 
-func printPackage(w io.Writer, pkg *gocov.Package) {
+func printPackage(w io.Writer, pkg *gocov.Package, layout *textLayout) error {
 	functions := functionReports(pkg)
-	sort.Sort(reverse{functions})
+	if err := layout.sort(functions); err != nil {
+		return err
+	}
 
 	var longestFunctionName int
 	var totalStatements, totalReached int
@@ -166,32 +463,84 @@ func printPackage(w io.Writer, pkg *gocov.Package) {
 		reached := fn.statementsReached
 		totalStatements += len(fn.Statements)
 		totalReached += reached
-		var stmtPercent float64 = 0
-		if len(fn.Statements) > 0 {
-			stmtPercent = float64(reached) / float64(len(fn.Statements)) * 100
-		}
 		if len(fn.Name) > longestFunctionName {
 			longestFunctionName = len(fn.Name)
 		}
-		fmt.Fprintf(w, "%s/%s\t %s\t %.2f%% (%d/%d)\n",
-			pkg.Name, filepath.Base(fn.File), fn.Name, stmtPercent,
-			reached, len(fn.Statements))
+		cells := make([]string, len(layout.columns))
+		for i, column := range layout.columns {
+			switch column {
+			case "file":
+				cells[i] = pkg.Name + "/" + filepath.Base(fn.File)
+			case "function":
+				cells[i] = fn.Name
+			case "complexity":
+				if !pkg.IsGo() {
+					break
+				}
+				complexity, err := layout.complexity(fn)
+				if err != nil {
+					return err
+				}
+				cells[i] = strconv.Itoa(complexity)
+			default:
+				cells[i] = layout.countCell(column, reached, len(fn.Statements))
+			}
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t "))
+	}
+
+	cells := make([]string, len(layout.columns))
+	for i, column := range layout.columns {
+		switch column {
+		case "file":
+			cells[i] = pkg.Name
+		case "function":
+			cells[i] = strings.Repeat("-", longestFunctionName)
+		case "complexity":
+		default:
+			cells[i] = layout.countCell(column, totalReached, totalStatements)
+		}
 	}
+	fmt.Fprintln(w, strings.Join(cells, "\t "))
+	return nil
+}
 
-	var funcPercent float64
-	if totalStatements > 0 {
-		funcPercent = float64(totalReached) / float64(totalStatements) * 100
+// printBreakdown prints the coverage of each input that statement
+// attribution refers to, followed by their combined coverage.
+func printBreakdown(w io.Writer, r *report) error {
+	if len(r.inputs) == 0 {
+		return fmt.Errorf("breakdown requires attributed coverage (see gocov merge -attribute)")
+	}
+	var totalStatements, totalReached int
+	reached := make([]int, len(r.inputs))
+	for _, pkg := range r.packages {
+		for _, fn := range pkg.Functions {
+			for _, stmt := range fn.Statements {
+				totalStatements++
+				if stmt.Reached > 0 {
+					totalReached++
+				}
+				for i := range r.inputs {
+					if stmt.AttributedTo(i) {
+						reached[i]++
+					}
+				}
+			}
+		}
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 0, '\t', 0)
+	for i, input := range r.inputs {
+		fmt.Fprintf(tw, "%s\t %s (%d/%d)\n", input, formatPercentage(reached[i], totalStatements), reached[i], totalStatements)
 	}
-	summaryLine := strings.Repeat("-", longestFunctionName)
-	fmt.Fprintf(w, "%s\t %s\t %.2f%% (%d/%d)\n",
-		pkg.Name, summaryLine, funcPercent,
-		totalReached, totalStatements)
+	fmt.Fprintf(tw, "Combined\t %s (%d/%d)\n", formatPercentage(totalReached, totalStatements), totalReached, totalStatements)
+	return tw.Flush()
 }
 
 func reportCoverage() (rc int) {
+	reportFlags.Parse(os.Args[2:])
 	files := make([]*os.File, 0, 1)
-	if flag.NArg() > 1 {
-		for _, name := range flag.Args()[1:] {
+	if reportFlags.NArg() > 0 {
+		for _, name := range reportFlags.Args() {
 			file, err := os.Open(name)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "failed to open file (%s): %s\n", name, err)
@@ -202,27 +551,218 @@ func reportCoverage() (rc int) {
 	} else {
 		files = append(files, os.Stdin)
 	}
+	key, err := verifyKey(*reportVerifyKeyFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read key: %s\n", err)
+		return 1
+	}
 	report := newReport()
 	for _, file := range files {
 		data, err := ioutil.ReadAll(file)
+		if file != os.Stdin {
+			file.Close()
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "failed to read coverage file: %s\n", err)
 			return 1
 		}
-		packages, err := unmarshalJson(data)
+		doc, err := unmarshalDocument(data)
 		if err != nil {
 			fmt.Fprintf(
 				os.Stderr, "failed to unmarshal coverage data: %s\n", err)
 			return 1
 		}
-		for _, pkg := range packages {
+		if key != nil {
+			if err := gocovutil.VerifyDocument(doc, key); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to verify coverage file (%s): %s\n", file.Name(), err)
+				return 1
+			}
+		}
+		if !reportSelect.selects(doc.Labels) {
+			continue
+		}
+		if len(doc.Inputs) > 0 {
+			if report.inputs != nil {
+				fmt.Fprintf(os.Stderr, "cannot report on more than one attributed coverage file\n")
+				return 1
+			}
+			report.inputs = doc.Inputs
+		}
+		for k, v := range doc.Labels {
+			if report.labels == nil {
+				report.labels = make(map[string]string)
+			}
+			report.labels[k] = v
+		}
+		for _, pkg := range doc.Packages {
 			report.addPackage(pkg)
 		}
-		if file != os.Stdin {
-			file.Close()
+	}
+	if *reportExcludeMainsFlag {
+		if err := report.excludeMains(*reportMainsDirFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to exclude main packages: %s\n", err)
+			return 1
+		}
+	}
+	if *reportFoldClosuresFlag {
+		report.foldClosures()
+	}
+	if *reportTagFlag != "" {
+		report.selectTag(*reportTagFlag)
+	}
+	if *reportMinStatementsFlag > 0 {
+		report.excludeTrivial(*reportMinStatementsFlag)
+	}
+	// Only -by-group, and the weighted total coverage of text reports
+	// and CI summaries, are configured.
+	cfg := &config{}
+	if *reportByGroupFlag || *reportFormatFlag == "text" || *reportGithubSummaryFlag || *reportBuildkiteFlag {
+		if cfg, err = loadConfig(*reportConfigFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read configuration: %s\n", err)
+			return 1
+		}
+	}
+	if len(cfg.Weights) > 0 {
+		if report.weight, err = cfg.Groups.weights(cfg.Weights); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+	}
+	if *reportByGroupFlag {
+		if len(cfg.Groups) == 0 {
+			fmt.Fprintf(os.Stderr, "no package groups configured in %s\n", *reportConfigFlag)
+			return 1
+		}
+		report.grouped = report.packages
+		report.packages = cfg.Groups.groupPackages(report.packages)
+	}
+	if *reportExclusionsFlag != "" {
+		if err := writeExclusions(*reportExclusionsFlag, report.exclusions); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write exclusions: %s\n", err)
+			return 1
+		}
+	}
+	var baseline *gocovutil.Document
+	if *reportBaseFlag != "" && (*reportGithubSummaryFlag || *reportBuildkiteFlag) {
+		if baseline, err = loadBaseline(*reportBaseFlag, []string{"./..."}); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+	}
+	if *reportGithubSummaryFlag {
+		if err := writeGithubSummary(report, *reportBaseFlag, baseline); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write GitHub summary: %s\n", err)
+			return 1
+		}
+	}
+	if *reportBuildkiteFlag {
+		if err := annotateBuildkite(report, *reportBaseFlag, baseline); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to annotate Buildkite build: %s\n", err)
+			return 1
+		}
+	}
+	if *reportCircleCIFlag != "" {
+		if err := writeCircleCIMetadata(report, *reportCircleCIFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write CircleCI test metadata: %s\n", err)
+			return 1
+		}
+	}
+	if *reportTemplateFlag != "" {
+		if err := printTemplate(os.Stdout, report, *reportTemplateFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to render template: %s\n", err)
+			return 1
+		}
+		return 0
+	}
+	switch *reportFormatFlag {
+	case "text":
+		layout, err := parseTextLayout(*reportColumnsFlag, *reportSortFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		layout.numbers, err = parsePercentFormat(*reportPrecisionFlag, *reportPerMilleFlag, *reportLocaleFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		fmt.Println()
+		if err := printReport(os.Stdout, report, layout); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to print report: %s\n", err)
+			return 1
+		}
+	case "blame":
+		if err := printBlame(os.Stdout, report); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to blame uncovered statements: %s\n", err)
+			return 1
+		}
+	case "breakdown":
+		if err := printBreakdown(os.Stdout, report); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to print breakdown: %s\n", err)
+			return 1
+		}
+	case "cobertura":
+		if err := printCobertura(os.Stdout, report); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write Cobertura report: %s\n", err)
+			return 1
+		}
+	case "dead-code":
+		if err := printDeadCode(os.Stdout, report, ""); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to find dead code: %s\n", err)
+			return 1
+		}
+	case "implementations":
+		if *reportInterfaceFlag == "" {
+			fmt.Fprintln(os.Stderr, "missing -interface")
+			return 1
+		}
+		impls, err := findImplementations(goPackageNames(report.packages), *reportInterfaceFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to find implementations: %s\n", err)
+			return 1
+		}
+		if err := printImplementations(os.Stdout, report, impls); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to print implementations: %s\n", err)
+			return 1
+		}
+	case "missing-cases":
+		if err := printMissingCases(os.Stdout, report); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to find missing cases: %s\n", err)
+			return 1
+		}
+	case "flaky":
+		if err := printFlaky(os.Stdout, report); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to find flaky coverage: %s\n", err)
+			return 1
+		}
+	case "histogram":
+		if err := printHistogram(os.Stdout, report); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to print histogram: %s\n", err)
+			return 1
+		}
+	case "influx":
+		if err := printInflux(os.Stdout, report); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write line protocol: %s\n", err)
+			return 1
+		}
+	case "smoke":
+		if err := printSmokeCoverage(os.Stdout, report); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to analyze smoke coverage: %s\n", err)
+			return 1
+		}
+	case "test-order":
+		if err := printTestOrder(os.Stdout, report); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to order tests: %s\n", err)
+			return 1
+		}
+	case "mutant-map":
+		if err := printMutantMap(os.Stdout, report); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write mutant map: %s\n", err)
+			return 1
 		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown report format %q\n", *reportFormatFlag)
+		return 1
 	}
-	fmt.Println()
-	printReport(os.Stdout, report)
 	return 0
 }
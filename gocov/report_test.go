@@ -0,0 +1,126 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestHasPathElement(t *testing.T) {
+	tests := []struct {
+		path, elem string
+		expect     bool
+	}{
+		{"example.com/cmd/tool", "cmd", true},
+		{"cmd/tool", "cmd", true},
+		{"example.com/cmd", "cmd", true},
+		{"example.com/cmdline", "cmd", false},
+		{"example.com/tools/cmd/tool", "tools/cmd/", true},
+		{"example.com/internal", "cmd", false},
+	}
+	for _, test := range tests {
+		if got := hasPathElement(test.path, test.elem); got != test.expect {
+			t.Errorf("hasPathElement(%q, %q) = %v, expected %v", test.path, test.elem, got, test.expect)
+		}
+	}
+}
+
+func TestExcludeTrivial(t *testing.T) {
+	r := newReport()
+	r.addPackage(&gocov.Package{Name: "p", Functions: []*gocov.Function{
+		{Name: "Get", Statements: []*gocov.Statement{{}}},
+		{Name: "Do", Statements: []*gocov.Statement{{}, {}, {}}},
+	}})
+	r.excludeTrivial(2)
+	functions := r.packages[0].Functions
+	if len(functions) != 1 || functions[0].Name != "Do" {
+		t.Errorf("Expected only Do to remain, got %v", functions)
+	}
+	if len(r.exclusions) != 1 || r.exclusions[0].Function != "Get" {
+		t.Errorf("Expected the exclusion of Get to be recorded, got %v", r.exclusions)
+	}
+}
+
+func TestFoldClosures(t *testing.T) {
+	r := newReport()
+	r.addPackage(&gocov.Package{Name: "p", Functions: []*gocov.Function{
+		{Name: "F", File: "a.go", Statements: []*gocov.Statement{{Reached: 1}}},
+		{Name: "F.func1", Parent: "F", File: "a.go", Statements: []*gocov.Statement{{}}},
+		{Name: "F.func1.1", Parent: "F.func1", File: "a.go", Statements: []*gocov.Statement{{}}},
+		{Name: "F", File: "b.go", Statements: []*gocov.Statement{{}}},
+	}})
+	r.foldClosures()
+	functions := r.packages[0].Functions
+	if len(functions) != 2 {
+		t.Fatalf("Expected 2 functions, got %d", len(functions))
+	}
+	if n := len(functions[0].Statements); n != 3 {
+		t.Errorf("Expected F of a.go to have 3 statements, got %d", n)
+	}
+	if n := len(functions[1].Statements); n != 1 {
+		t.Errorf("Expected F of b.go to have 1 statement, got %d", n)
+	}
+}
+
+func TestSelectTag(t *testing.T) {
+	r := newReport()
+	r.addPackage(&gocov.Package{Name: "p", Functions: []*gocov.Function{
+		{Name: "F", File: "a.go", Statements: []*gocov.Statement{{Tags: []string{"error-path"}}, {Reached: 1}}},
+		{Name: "G", File: "a.go", Statements: []*gocov.Statement{{Reached: 1}}},
+	}})
+	r.selectTag("error-path")
+	functions := r.packages[0].Functions
+	if len(functions) != 1 || functions[0].Name != "F" {
+		t.Fatalf("Expected only F to be left, got %v", functions)
+	}
+	if n := len(functions[0].Statements); n != 1 {
+		t.Errorf("Expected F to have 1 statement, got %d", n)
+	}
+	if len(r.exclusions) != 1 || r.exclusions[0].Function != "G" {
+		t.Errorf("Expected G to be excluded, got %v", r.exclusions)
+	}
+}
+
+func TestExportedCounts(t *testing.T) {
+	pkg := &gocov.Package{Name: "p", Functions: []*gocov.Function{
+		{Name: "F", Statements: []*gocov.Statement{{Reached: 1}, {}}},
+		{Name: "T[K].M", Statements: []*gocov.Statement{{Reached: 1}}},
+		{Name: "t.M", Statements: []*gocov.Statement{{Reached: 1}}},
+		{Name: "T.m", Statements: []*gocov.Statement{{}}},
+		{Name: "F.func1", Parent: "F", Statements: []*gocov.Statement{{}}},
+	}}
+	if reached, total := exportedCounts(pkg); reached != 2 || total != 3 {
+		t.Errorf("exportedCounts = %d, %d, want 2, 3", reached, total)
+	}
+
+	// Type information tells that t.M is promoted to an exported type.
+	exported := true
+	pkg.Functions[2].Exported = &exported
+	if reached, total := exportedCounts(pkg); reached != 3 || total != 4 {
+		t.Errorf("exportedCounts with type information = %d, %d, want 3, 4", reached, total)
+	}
+	pkg.Name = "p/internal/q"
+	if reached, total := exportedCounts(pkg); reached != 0 || total != 0 {
+		t.Errorf("exportedCounts of an internal package = %d, %d, want 0, 0", reached, total)
+	}
+}
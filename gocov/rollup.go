@@ -0,0 +1,134 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var (
+	rollupFlags         = flag.NewFlagSet("rollup", flag.ExitOnError)
+	rollupRepoLabelFlag = rollupFlags.String(
+		"repo-label", "repo",
+		"Document label naming the repository a coverage file belongs to")
+)
+
+// rollupEntry is the coverage of a repository in an organisation rollup.
+type rollupEntry struct {
+	repo           string
+	reached, total int
+}
+
+func (e *rollupEntry) percent() float64 {
+	return percentage(e.reached, e.total)
+}
+
+// repoName returns the repository a coverage document belongs to: the
+// value of its repo label, or else the file name without extension.
+func repoName(filename string, doc *gocovutil.Document, label string) string {
+	if name := doc.Labels[label]; name != "" {
+		return name
+	}
+	base := filepath.Base(filename)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// rankRepos sorts the entries into a league table, best covered first.
+func rankRepos(entries []*rollupEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if pi, pj := entries[i].percent(), entries[j].percent(); pi != pj {
+			return pi > pj
+		}
+		return entries[i].repo < entries[j].repo
+	})
+}
+
+// printRollup prints the league table of repositories, followed by the
+// organisation's total coverage.
+func printRollup(w io.Writer, entries []*rollupEntry) error {
+	var org rollupEntry
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(tw, "Rank\tRepository\tCoverage\n")
+	for i, e := range entries {
+		org.reached += e.reached
+		org.total += e.total
+		fmt.Fprintf(tw, "%d\t%s\t%s (%d/%d)\n", i+1, e.repo, formatPercentage(e.reached, e.total), e.reached, e.total)
+	}
+	fmt.Fprintf(tw, "\tOrganisation\t%s (%d/%d)\n", formatPercentage(org.reached, org.total), org.reached, org.total)
+	return tw.Flush()
+}
+
+// rollupCoverage aggregates the coverage documents of many repositories
+// into an organisation-level rollup with a per-repository league table.
+// The documents of a repository, e.g. its unit and integration coverage,
+// are merged before ranking.
+func rollupCoverage() (rc int) {
+	rollupFlags.Parse(os.Args[2:])
+	if rollupFlags.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "missing coverage file\n")
+		return 1
+	}
+	repos := make(map[string]*gocovutil.Packages)
+	var order []string
+	for _, filename := range rollupFlags.Args() {
+		doc, err := gocovutil.ReadDocument(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read coverage file (%s): %s\n", filename, err)
+			return 1
+		}
+		repo := repoName(filename, doc, *rollupRepoLabelFlag)
+		ps := repos[repo]
+		if ps == nil {
+			ps = &gocovutil.Packages{}
+			repos[repo] = ps
+			order = append(order, repo)
+		}
+		if err := ps.MergePackages(doc.Packages); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to merge coverage of %s: %s\n", repo, err)
+			return 1
+		}
+	}
+	var entries []*rollupEntry
+	for _, repo := range order {
+		e := &rollupEntry{repo: repo}
+		for _, pkg := range *repos[repo] {
+			reached, total := coverageCounts(pkg)
+			e.reached += reached
+			e.total += total
+		}
+		entries = append(entries, e)
+	}
+	rankRepos(entries)
+	if err := printRollup(os.Stdout, entries); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to print rollup: %s\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// knownSecrets holds the secrets that gocov has resolved, which are
+// redacted from its output.
+var knownSecrets struct {
+	sync.Mutex
+	values []string
+}
+
+// registerSecret records a secret to be redacted from output.
+func registerSecret(secret string) {
+	if secret == "" {
+		return
+	}
+	knownSecrets.Lock()
+	defer knownSecrets.Unlock()
+	for _, s := range knownSecrets.values {
+		if s == secret {
+			return
+		}
+	}
+	knownSecrets.values = append(knownSecrets.values, secret)
+	// Longer secrets go first, lest a secret within them be redacted
+	// and leave the rest of them readable.
+	sort.SliceStable(knownSecrets.values, func(i, j int) bool {
+		return len(knownSecrets.values[i]) > len(knownSecrets.values[j])
+	})
+}
+
+// redactSecrets replaces the known secrets in s, URL-encoded or not.
+func redactSecrets(s string) string {
+	knownSecrets.Lock()
+	defer knownSecrets.Unlock()
+	for _, secret := range knownSecrets.values {
+		s = strings.Replace(s, secret, redacted, -1)
+		s = strings.Replace(s, url.QueryEscape(secret), redacted, -1)
+	}
+	return s
+}
+
+// redactError returns err with the known secrets redacted from its
+// message.
+func redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if message := redactSecrets(err.Error()); message != err.Error() {
+		return errors.New(message)
+	}
+	return err
+}
+
+// resolveSecret resolves a reference to a secret, such as a token, given
+// by flag or in the configuration:
+//
+//	env:NAME               the environment variable NAME
+//	file:PATH              the contents of a file, e.g. a mounted secret
+//	keychain:SERVICE       the password of SERVICE in the keychain: the
+//	keychain:SERVICE:USER  macOS keychain, or the Secret Service on Linux
+//
+// Anything else is the secret itself, with environment variables
+// expanded. The secret is redacted from gocov's output.
+func resolveSecret(ref string) (string, error) {
+	var secret string
+	switch {
+	case strings.HasPrefix(ref, "env:"):
+		secret = os.Getenv(strings.TrimPrefix(ref, "env:"))
+	case strings.HasPrefix(ref, "file:"):
+		data, err := ioutil.ReadFile(strings.TrimPrefix(ref, "file:"))
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret: %v", err)
+		}
+		secret = strings.TrimRight(string(data), "\r\n")
+	case strings.HasPrefix(ref, "keychain:"):
+		var err error
+		if secret, err = keychainSecret(strings.TrimPrefix(ref, "keychain:")); err != nil {
+			return "", fmt.Errorf("failed to read secret from keychain: %v", err)
+		}
+	default:
+		secret = os.ExpandEnv(ref)
+	}
+	registerSecret(secret)
+	return secret, nil
+}
+
+// keychainSecret looks up the password of a keychain item named as
+// SERVICE or SERVICE:USER.
+func keychainSecret(item string) (string, error) {
+	service, user := item, ""
+	if i := strings.Index(item, ":"); i >= 0 {
+		service, user = item[:i], item[i+1:]
+	}
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		args := []string{"find-generic-password", "-s", service, "-w"}
+		if user != "" {
+			args = append(args, "-a", user)
+		}
+		cmd = exec.Command("security", args...)
+	case "linux", "freebsd", "openbsd":
+		args := []string{"lookup", "service", service}
+		if user != "" {
+			args = append(args, "username", user)
+		}
+		cmd = exec.Command("secret-tool", args...)
+	default:
+		return "", fmt.Errorf("no keychain support on %s", runtime.GOOS)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: %v: %s", cmd.Args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// expandURL expands environment variables in a URL given by flag or in
+// the configuration, registering the password of its user information
+// as a secret.
+func expandURL(rawurl string) string {
+	expanded := os.ExpandEnv(rawurl)
+	if u, err := url.Parse(expanded); err == nil {
+		if password, ok := u.User.Password(); ok {
+			registerSecret(password)
+		}
+	}
+	return expanded
+}
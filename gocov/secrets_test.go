@@ -0,0 +1,52 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSecret(t *testing.T) {
+	t.Setenv("GOCOV_TEST_TOKEN", "env-secret")
+	filename := filepath.Join(t.TempDir(), "token")
+	if err := ioutil.WriteFile(filename, []byte("file-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for ref, want := range map[string]string{
+		"env:GOCOV_TEST_TOKEN":  "env-secret",
+		"file:" + filename:      "file-secret",
+		"${GOCOV_TEST_TOKEN}-2": "env-secret-2",
+	} {
+		if got, err := resolveSecret(ref); err != nil || got != want {
+			t.Errorf("resolveSecret(%q) = %q, %v, want %q", ref, got, err, want)
+		}
+	}
+	if _, err := resolveSecret("file:" + filename + ".missing"); err == nil {
+		t.Error("Expected an error for a missing secret file")
+	}
+	err := redactError(fmt.Errorf("rejected file-secret"))
+	if err.Error() != "rejected REDACTED" {
+		t.Errorf("Expected the secret to be redacted, got %q", err)
+	}
+}
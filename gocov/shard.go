@@ -0,0 +1,53 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hihoak/gocov/gocov/convert"
+)
+
+var (
+	shardFlags      = flag.NewFlagSet("shard", flag.ExitOnError)
+	shardFlagValues = addConvertFlags(shardFlags)
+	shardIndexFlag  = shardFlags.Int(
+		"index", 0,
+		"Index of the shard to convert, counting from zero")
+	shardTotalFlag = shardFlags.Int(
+		"total", 1,
+		"Total number of shards")
+)
+
+// convertShard converts one shard of the packages in the given profiles,
+// so that conversion of large profile sets can be spread over parallel CI
+// jobs whose outputs are then combined with "gocov merge".
+func convertShard() (rc int) {
+	shardFlags.Parse(os.Args[2:])
+	if *shardTotalFlag < 1 || *shardIndexFlag < 0 || *shardIndexFlag >= *shardTotalFlag {
+		fmt.Fprintf(os.Stderr, "invalid shard %d of %d\n", *shardIndexFlag, *shardTotalFlag)
+		return 1
+	}
+	return runConvert(shardFlags.Args(), shardFlagValues,
+		convert.WithShard(*shardIndexFlag, *shardTotalFlag))
+}
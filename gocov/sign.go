@@ -0,0 +1,106 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var (
+	signFlags   = flag.NewFlagSet("sign", flag.ExitOnError)
+	signKeyFlag = signFlags.String(
+		"key", "",
+		"File holding the base64 encoded ed25519 private key to sign with")
+	signGenerateFlag = signFlags.String(
+		"generate-key", "",
+		"Generate a key pair, writing the private key to this file and the public key to the file with .pub appended")
+)
+
+// generateKey writes a new ed25519 private key to the named file, and its
+// public key to the file with ".pub" appended.
+func generateKey(filename string) error {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	encode := func(key []byte) []byte {
+		return []byte(base64.StdEncoding.EncodeToString(key) + "\n")
+	}
+	if err := ioutil.WriteFile(filename, encode(priv), 0600); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename+".pub", encode(pub), 0644)
+}
+
+// verifyKey reads the public key named by a -verify-key flag, or returns
+// nil if the flag is empty.
+func verifyKey(filename string) (ed25519.PublicKey, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	return gocovutil.ReadPublicKey(filename)
+}
+
+func signCoverage() (rc int) {
+	signFlags.Parse(os.Args[2:])
+	if *signGenerateFlag != "" {
+		if err := generateKey(*signGenerateFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to generate key: %s\n", err)
+			return 1
+		}
+		return 0
+	}
+	if *signKeyFlag == "" {
+		fmt.Fprintln(os.Stderr, "missing -key")
+		return 1
+	}
+	key, err := gocovutil.ReadPrivateKey(*signKeyFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read key: %s\n", err)
+		return 1
+	}
+	filename := "-"
+	if signFlags.NArg() > 0 {
+		filename = signFlags.Arg(0)
+	}
+	doc, err := gocovutil.ReadDocument(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read coverage file (%s): %s\n", filename, err)
+		return 1
+	}
+	if err := gocovutil.SignDocument(doc, key); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to sign coverage data: %s\n", err)
+		return 1
+	}
+	if err := gocovutil.WriteDocument(os.Stdout, doc); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write coverage data: %s\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// failureMethods are the methods of testing.TB that report a failure.
+var failureMethods = map[string]bool{
+	"Error": true, "Errorf": true,
+	"Fatal": true, "Fatalf": true,
+	"Fail": true, "FailNow": true,
+}
+
+// isAssertion reports whether a call looks like it verifies a result: a
+// failure method of one of the test's testing.TB parameters, named by tbs,
+// a call into an assertion package such as testify's assert or require,
+// or a helper named like an assertion.
+func isAssertion(call *ast.CallExpr, tbs map[string]bool) bool {
+	var name string
+	switch fun := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		x, ok := fun.X.(*ast.Ident)
+		if ok && failureMethods[fun.Sel.Name] && tbs[x.Name] {
+			return true
+		}
+		if ok && (x.Name == "assert" || x.Name == "require") {
+			return true
+		}
+		name = fun.Sel.Name
+	case *ast.Ident:
+		name = fun.Name
+	default:
+		return false
+	}
+	name = strings.ToLower(name)
+	for _, prefix := range []string{"assert", "require", "check", "expect", "verify"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// testingImport returns the name by which a file imports package testing,
+// or "" if it does not.
+func testingImport(file *ast.File) string {
+	for _, imp := range file.Imports {
+		if imp.Path.Value != `"testing"` {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return "testing"
+	}
+	return ""
+}
+
+// tbTypes are the types, in package testing, of the values whose failure
+// methods report test failures.
+var tbTypes = map[string]bool{"*T": true, "*B": true, "TB": true}
+
+// addTBParams adds to tbs the names of the parameters of type *testing.T,
+// *testing.B or testing.TB, with package testing imported as testing.
+func addTBParams(params *ast.FieldList, testing string, tbs map[string]bool) {
+	if params == nil || testing == "" {
+		return
+	}
+	for _, field := range params.List {
+		typ, pointer := field.Type, false
+		if star, ok := typ.(*ast.StarExpr); ok {
+			typ, pointer = star.X, true
+		}
+		sel, ok := typ.(*ast.SelectorExpr)
+		if !ok {
+			continue
+		}
+		if x, ok := sel.X.(*ast.Ident); !ok || x.Name != testing {
+			continue
+		}
+		name := sel.Sel.Name
+		if pointer {
+			name = "*" + name
+		}
+		if !tbTypes[name] {
+			continue
+		}
+		for _, ident := range field.Names {
+			tbs[ident.Name] = true
+		}
+	}
+}
+
+// assertingTests parses the test files in dir, returning for each test
+// function whether it contains an assertion. Failure methods count only
+// when called on the test's own testing.TB, or that of one of its
+// subtests, so that calls such as err.Error() are not taken for them.
+func assertingTests(dir string, result map[string]bool) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	for _, name := range files {
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			return err
+		}
+		testing := testingImport(file)
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Body == nil || !strings.HasPrefix(fn.Name.Name, "Test") {
+				continue
+			}
+			tbs := make(map[string]bool)
+			addTBParams(fn.Type.Params, testing, tbs)
+			asserts := false
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.FuncLit:
+					addTBParams(n.Type.Params, testing, tbs)
+				case *ast.CallExpr:
+					asserts = isAssertion(n, tbs)
+				}
+				return !asserts
+			})
+			result[fn.Name.Name] = result[fn.Name.Name] || asserts
+		}
+	}
+	return nil
+}
+
+// printSmokeCoverage lists the functions that were reached only by tests
+// containing no assertions: code that is executed but not verified.
+//
+// Per-test data is taken from the attribution recorded by "gocov merge
+// -attribute", with each input labelled "test=<name>". Test functions are
+// looked up in the directories of the reported packages; tests that cannot
+// be found are assumed to contain assertions.
+func printSmokeCoverage(w io.Writer, r *report) error {
+	if len(r.inputs) == 0 {
+		return fmt.Errorf("smoke coverage requires per-test attribution (see gocov merge -attribute)")
+	}
+	asserting := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, pkg := range r.packages {
+		for _, fn := range pkg.Functions {
+			dir := filepath.Dir(fn.File)
+			if dirs[dir] {
+				continue
+			}
+			dirs[dir] = true
+			if err := assertingTests(dir, asserting); err != nil {
+				return err
+			}
+		}
+	}
+	smoke := make([]bool, len(r.inputs))
+	for i, input := range r.inputs {
+		name := testName(input)
+		if asserts, ok := asserting[name]; ok && !asserts {
+			smoke[i] = true
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 0, '\t', 0)
+	for _, pkg := range r.packages {
+		functions := make(functionList, len(pkg.Functions))
+		copy(functions, pkg.Functions)
+		sort.Sort(functions)
+		for _, fn := range functions {
+			var tests []string
+			seen := make(map[int]bool)
+			verified := false
+			for _, stmt := range fn.Statements {
+				for i := range r.inputs {
+					if !stmt.AttributedTo(i) {
+						continue
+					}
+					if !smoke[i] {
+						verified = true
+					} else if !seen[i] {
+						seen[i] = true
+						tests = append(tests, testName(r.inputs[i]))
+					}
+				}
+			}
+			if verified || len(tests) == 0 {
+				continue
+			}
+			fmt.Fprintf(tw, "%s/%s\t %s\t %s\n",
+				pkg.Name, filepath.Base(fn.File), fn.Name, strings.Join(tests, ", "))
+		}
+	}
+	return tw.Flush()
+}
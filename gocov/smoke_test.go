@@ -0,0 +1,99 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIsAssertion(t *testing.T) {
+	tests := []struct {
+		expr   string
+		expect bool
+	}{
+		{`t.Errorf("x")`, true},
+		{`t.Fatal(err)`, true},
+		{`assert.Equal(t, 1, x)`, true},
+		{`require.NoError(t, err)`, true},
+		{`checkResult(t, x)`, true},
+		{`t.Log("x")`, false},
+		{`fmt.Println(x)`, false},
+		{`f()()`, false},
+		{`err.Error()`, false},
+		{`log.Fatal(err)`, false},
+		{`b.Fatalf("x")`, false},
+	}
+	for _, test := range tests {
+		expr, err := parser.ParseExpr(test.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := isAssertion(expr.(*ast.CallExpr), map[string]bool{"t": true}); got != test.expect {
+			t.Errorf("isAssertion(%s) = %v, expected %v", test.expr, got, test.expect)
+		}
+	}
+}
+
+func TestAssertingTests(t *testing.T) {
+	dir := t.TempDir()
+	src := `package p
+
+import (
+	"errors"
+	tst "testing"
+)
+
+func TestErrorString(t *tst.T) {
+	err := errors.New("x")
+	_ = err.Error()
+}
+
+func TestFails(t *tst.T) {
+	t.Error("x")
+}
+
+func TestSubtest(t *tst.T) {
+	t.Run("sub", func(st *tst.T) {
+		st.Fatal("x")
+	})
+}
+
+func TestHelper(tb tst.TB) {
+	tb.FailNow()
+}
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "p_test.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]bool)
+	if err := assertingTests(dir, got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"TestErrorString": false, "TestFails": true, "TestSubtest": true, "TestHelper": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("assertingTests = %v, expected %v", got, want)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+// maxOpenSources caps the number of source files open at once, across all
+// concurrent readers, to stay well within file descriptor limits.
+const maxOpenSources = 64
+
+// openSources holds a token for each source file open.
+var openSources = make(chan struct{}, maxOpenSources)
+
+// sourceReadJobs is the number of source files read concurrently, which
+// hides the latency of network file systems.
+var sourceReadJobs = 16
+
+// readSource reads a source file as gocovutil.ReadSource does, waiting
+// while too many source files are open.
+func readSource(fsys fs.FS, filename string) ([]byte, error) {
+	openSources <- struct{}{}
+	defer func() { <-openSources }()
+	return gocovutil.ReadSource(fsys, filename)
+}
+
+// readSources reads the named source files with up to jobs concurrent
+// readers, passing the contents of each that could be read to fn, one
+// file at a time, so that no more than jobs files are held at once.
+func readSources(fsys fs.FS, filenames []string, jobs int, fn func(name string, data []byte)) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	names := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < jobs && i < len(filenames); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range names {
+				if data, err := readSource(fsys, name); err == nil {
+					mu.Lock()
+					fn(name, data)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, name := range filenames {
+		names <- name
+	}
+	close(names)
+	wg.Wait()
+}
+
+// sourceFileNames returns the sorted names of the source files of the
+// packages' functions.
+func sourceFileNames(packages []*gocov.Package) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, pkg := range packages {
+		for _, fn := range pkg.Functions {
+			if !seen[fn.File] {
+				seen[fn.File] = true
+				names = append(names, fn.File)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sourceFS returns the file system rooted at root, or nil for the
+// operating system's file system if root is empty.
+func sourceFS(root string) fs.FS {
+	if root == "" {
+		return nil
+	}
+	return os.DirFS(root)
+}
+
+// sourceFiles caches line information for the source files referred to
+// by coverage data.
+type sourceFiles struct {
+	fset  *token.FileSet
+	files map[string]*token.File
+	fsys  fs.FS
+}
+
+func newSourceFiles() *sourceFiles {
+	return &sourceFiles{
+		fset:  token.NewFileSet(),
+		files: make(map[string]*token.File),
+		fsys:  sourceFS(*reportSourceRootFlag),
+	}
+}
+
+// add records the line information of a file.
+func (s *sourceFiles) add(filename string, data []byte) *token.File {
+	file := s.fset.AddFile(filename, s.fset.Base(), len(data))
+	file.SetLinesForContent(data)
+	s.files[filename] = file
+	return file
+}
+
+// preload reads the source files of the packages' functions
+// concurrently, so that their line information need not be read one file
+// at a time. Only the line information of each file is kept, not its
+// contents. Files that cannot be read are left for file to report.
+func (s *sourceFiles) preload(packages []*gocov.Package) {
+	var names []string
+	for _, name := range sourceFileNames(packages) {
+		if s.files[name] == nil {
+			names = append(names, name)
+		}
+	}
+	readSources(s.fsys, names, sourceReadJobs, func(name string, data []byte) {
+		s.add(name, data)
+	})
+}
+
+// file returns the line information of the named file.
+func (s *sourceFiles) file(filename string) (*token.File, error) {
+	file := s.files[filename]
+	if file == nil {
+		data, err := readSource(s.fsys, filename)
+		if err != nil {
+			return nil, err
+		}
+		file = s.add(filename, data)
+	}
+	return file, nil
+}
+
+// position returns the position of an offset in the named file.
+func (s *sourceFiles) position(filename string, offset int) (token.Position, error) {
+	file, err := s.file(filename)
+	if err != nil {
+		return token.Position{}, err
+	}
+	if offset < 0 || offset > file.Size() {
+		return token.Position{}, fmt.Errorf("offset %d is outside %s; has the file changed?", offset, filename)
+	}
+	return file.Position(file.Pos(offset)), nil
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestSourceFilesPreload(t *testing.T) {
+	fsys := fstest.MapFS{}
+	var packages []*gocov.Package
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("/src/p/f%d.go", i)
+		fsys[name[1:]] = &fstest.MapFile{Data: []byte(strings.Repeat("\n", i) + "x")}
+		packages = append(packages, &gocov.Package{Name: "p", Functions: []*gocov.Function{{File: name}, {File: name}}})
+	}
+	packages = append(packages, &gocov.Package{Name: "q", Functions: []*gocov.Function{{File: "/src/q/missing.go"}}})
+	s := &sourceFiles{fset: token.NewFileSet(), files: make(map[string]*token.File), fsys: fsys}
+	s.preload(packages)
+	if len(s.files) != 50 {
+		t.Fatalf("Expected 50 preloaded files, got %d", len(s.files))
+	}
+	for i := 0; i < 50; i++ {
+		if file, err := s.file(fmt.Sprintf("/src/p/f%d.go", i)); err != nil || file.LineCount() != i+1 {
+			t.Errorf("Unexpected line information of f%d.go: %v", i, err)
+		}
+	}
+	if _, err := s.file("/src/q/missing.go"); err == nil {
+		t.Error("Expected an error for a missing source file")
+	}
+}
+
+func TestReadSources(t *testing.T) {
+	fsys := fstest.MapFS{}
+	var names []string
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("/src/p/f%d.go", i)
+		fsys[name[1:]] = &fstest.MapFile{Data: []byte(name)}
+		names = append(names, name)
+	}
+	names = append(names, "/src/p/missing.go")
+	// The contents are passed on one file at a time, and so to code
+	// needing no locking of its own.
+	read := make(map[string]bool)
+	readSources(fsys, names, 8, func(name string, data []byte) {
+		if string(data) != name {
+			t.Errorf("Unexpected contents of %s: %q", name, data)
+		}
+		read[name] = true
+	})
+	if len(read) != 50 || read["/src/p/missing.go"] {
+		t.Errorf("Expected the 50 files that exist to be read, got %d", len(read))
+	}
+}
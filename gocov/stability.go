@@ -0,0 +1,181 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"text/tabwriter"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+var (
+	stabilityFlags         = flag.NewFlagSet("stability", flag.ExitOnError)
+	stabilityMaxStddevFlag = stabilityFlags.Float64(
+		"max-stddev", 0.5,
+		"Flag packages whose coverage varies by more than this standard deviation, in percentage points")
+	stabilityFailFlag = stabilityFlags.Bool(
+		"fail", false,
+		"Exit with status 5 if any package is unstable")
+)
+
+// tCritical holds the two-sided 95% critical values of Student's t
+// distribution for 1 to 30 degrees of freedom; beyond that the normal
+// distribution's 1.96 is close enough.
+var tCritical = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// packageStability summarizes the coverage of a package across runs.
+type packageStability struct {
+	name string
+
+	// percents holds the package's coverage in each run that covered it,
+	// and reached and total the statement counts it was computed from.
+	percents       []float64
+	reached, total []int
+}
+
+// exactMean returns the exact mean coverage of the package across runs,
+// for display.
+func (s *packageStability) exactMean() *big.Rat {
+	sum := new(big.Rat)
+	for i := range s.reached {
+		sum.Add(sum, gocovutil.ExactPercentage(s.reached[i], s.total[i]))
+	}
+	return sum.Quo(sum, big.NewRat(int64(len(s.reached)), 1))
+}
+
+// mean returns the mean coverage of the package across runs.
+func (s *packageStability) mean() float64 {
+	var sum float64
+	for _, p := range s.percents {
+		sum += p
+	}
+	return sum / float64(len(s.percents))
+}
+
+// stddev returns the sample standard deviation of the package's coverage
+// across runs, or 0 for a single run.
+func (s *packageStability) stddev() float64 {
+	n := len(s.percents)
+	if n < 2 {
+		return 0
+	}
+	mean := s.mean()
+	var sum float64
+	for _, p := range s.percents {
+		sum += (p - mean) * (p - mean)
+	}
+	return math.Sqrt(sum / float64(n-1))
+}
+
+// interval returns the half-width of the 95% confidence interval of the
+// package's mean coverage, or 0 for a single run.
+func (s *packageStability) interval() float64 {
+	n := len(s.percents)
+	if n < 2 {
+		return 0
+	}
+	t := 1.96
+	if n-1 <= len(tCritical) {
+		t = tCritical[n-2]
+	}
+	return t * s.stddev() / math.Sqrt(float64(n))
+}
+
+// collectStability records the coverage of each package of a run's
+// packages, adding packages not seen in earlier runs to order.
+func collectStability(stability map[string]*packageStability, order []*packageStability, packages gocovutil.Packages) []*packageStability {
+	for _, pkg := range packages {
+		s := stability[pkg.Name]
+		if s == nil {
+			s = &packageStability{name: pkg.Name}
+			stability[pkg.Name] = s
+			order = append(order, s)
+		}
+		reached, total := coverageCounts(pkg)
+		s.percents = append(s.percents, percentage(reached, total))
+		s.reached = append(s.reached, reached)
+		s.total = append(s.total, total)
+	}
+	return order
+}
+
+// printStability prints the mean, standard deviation and confidence
+// interval of each package's coverage, flagging the packages whose
+// standard deviation exceeds maxStddev, and returns their number.
+func printStability(w io.Writer, packages []*packageStability, maxStddev float64) (int, error) {
+	unstable := 0
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(tw, "Package\tRuns\tMean\tStddev\t95%% CI\t\n")
+	for _, s := range packages {
+		mean, stddev, interval := s.mean(), s.stddev(), s.interval()
+		marker := ""
+		if stddev > maxStddev {
+			marker = "UNSTABLE"
+			unstable++
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%.2f\t%.2f%%-%.2f%%\t%s\n", s.name, len(s.percents),
+			defaultPercentFormat.formatExact(s.exactMean()), stddev, mean-interval, mean+interval, marker)
+	}
+	if err := tw.Flush(); err != nil {
+		return 0, err
+	}
+	return unstable, nil
+}
+
+// stabilityCoverage aggregates the coverage of repeated runs, such as the
+// last builds of the main branch, flagging the packages whose coverage
+// varies between runs, a sign of flaky or timing-dependent tests.
+func stabilityCoverage() (rc int) {
+	stabilityFlags.Parse(os.Args[2:])
+	if stabilityFlags.NArg() < 2 {
+		fmt.Fprintf(os.Stderr, "at least two coverage files are required\n")
+		return 1
+	}
+	stability := make(map[string]*packageStability)
+	var order []*packageStability
+	for _, filename := range stabilityFlags.Args() {
+		doc, err := gocovutil.ReadDocument(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read coverage file (%s): %s\n", filename, err)
+			return 1
+		}
+		order = collectStability(stability, order, doc.Packages)
+	}
+	unstable, err := printStability(os.Stdout, order, *stabilityMaxStddevFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to print stability: %s\n", err)
+		return 1
+	}
+	if unstable > 0 && *stabilityFailFlag {
+		return exitUnstable
+	}
+	return 0
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+func TestPrintStability(t *testing.T) {
+	pkg := func(name string, reached, total int) *gocov.Package {
+		fn := &gocov.Function{Name: "f"}
+		for i := 0; i < total; i++ {
+			stmt := &gocov.Statement{}
+			if i < reached {
+				stmt.Reached = 1
+			}
+			fn.Statements = append(fn.Statements, stmt)
+		}
+		return &gocov.Package{Name: name, Functions: []*gocov.Function{fn}}
+	}
+	stability := make(map[string]*packageStability)
+	var order []*packageStability
+	for _, reached := range []int{50, 60, 55} {
+		order = collectStability(stability, order, gocovutil.Packages{pkg("stable", 80, 100), pkg("flaky", reached, 100)})
+	}
+	order = collectStability(stability, order, gocovutil.Packages{pkg("new", 1, 2)})
+
+	var buf bytes.Buffer
+	unstable, err := printStability(&buf, order, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unstable != 1 {
+		t.Errorf("Expected 1 unstable package, got %d", unstable)
+	}
+	expected := `Package Runs Mean   Stddev 95% CI        
+stable  3    80.00% 0.00   80.00%-80.00% 
+flaky   3    55.00% 5.00   42.58%-67.42% UNSTABLE
+new     1    50.00% 0.00   50.00%-50.00% 
+`
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+
+	"github.com/hihoak/gocov/gocovutil"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	suggestFlags      = flag.NewFlagSet("suggest-thresholds", flag.ExitOnError)
+	suggestMarginFlag = suggestFlags.Float64(
+		"margin", 5,
+		"Set each threshold this many percentage points below the package's current coverage")
+	suggestConfigFlag = suggestFlags.String(
+		"config", defaultConfigFile,
+		"Configuration `file` -write adds the thresholds to")
+	suggestWriteFlag = suggestFlags.Bool(
+		"write", false,
+		"Add the thresholds of packages that have none to the configuration file, instead of printing them")
+)
+
+// suggestThresholds returns per-package coverage thresholds the margin
+// below the current coverage of each package, rounded down to a whole
+// percentage. Packages without statements have no threshold.
+func suggestThresholds(doc *gocovutil.Document, margin float64) map[string]float64 {
+	thresholds := make(map[string]float64)
+	for _, pkg := range doc.Packages {
+		reached, total := coverageCounts(pkg)
+		if total == 0 {
+			continue
+		}
+		thresholds[pkg.Name] = math.Max(0, floorPercentage(reached, total, margin, 1))
+	}
+	return thresholds
+}
+
+// suggestCoverageThresholds prints a configuration file whose thresholds,
+// enforced by "gocov check", hold coverage near its current level. With
+// -write, the thresholds of packages not yet configured are added to the
+// configuration file instead, keeping those configured and the rest of
+// the file.
+func suggestCoverageThresholds() (rc int) {
+	suggestFlags.Parse(os.Args[2:])
+	filename := "-"
+	if suggestFlags.NArg() > 0 {
+		filename = suggestFlags.Arg(0)
+	}
+	doc, err := gocovutil.ReadDocument(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read coverage file (%s): %s\n", filename, err)
+		return 1
+	}
+	thresholds := suggestThresholds(doc, *suggestMarginFlag)
+	if *suggestWriteFlag {
+		if err := addThresholds(*suggestConfigFlag, thresholds); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write configuration: %s\n", err)
+			return 1
+		}
+		return 0
+	}
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(&config{Thresholds: thresholds}); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode thresholds: %s\n", err)
+		return 1
+	}
+	if err := enc.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode thresholds: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// addThresholds adds the thresholds of packages that have none to the
+// named configuration file, which is created if it does not exist.
+func addThresholds(filename string, thresholds map[string]float64) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	cfg := &config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return err
+	}
+	added := make(map[string]float64)
+	for name, threshold := range thresholds {
+		if _, ok := cfg.Thresholds[name]; !ok {
+			added[name] = threshold
+		}
+	}
+	data, err = setThresholds(data, added)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+// printSummary writes a markdown table of the report's package coverage,
+// for the job summaries and annotations of CI providers, followed by the
+// changes since the baseline if there is one.
+func printSummary(w io.Writer, r *report, baseName string, baseline *gocovutil.Document) {
+	// Reports mixing languages name the language of each package.
+	languages := languageCounts(r.ungrouped())
+	if languages == nil {
+		fmt.Fprintf(w, "## Coverage\n\n| Package | Coverage | Statements |\n| --- | ---: | ---: |\n")
+	} else {
+		fmt.Fprintf(w, "## Coverage\n\n| Package | Language | Coverage | Statements |\n| --- | --- | ---: | ---: |\n")
+	}
+	for _, pkg := range r.packages {
+		pkgReached, pkgTotal := coverageCounts(pkg)
+		if languages == nil {
+			fmt.Fprintf(w, "| %s | %s | %d/%d |\n", pkg.Name, formatPercentage(pkgReached, pkgTotal), pkgReached, pkgTotal)
+		} else {
+			fmt.Fprintf(w, "| %s | %s | %s | %d/%d |\n", pkg.Name, packageLanguage(pkg), formatPercentage(pkgReached, pkgTotal), pkgReached, pkgTotal)
+		}
+	}
+	for _, lang := range languages {
+		fmt.Fprintf(w, "| **Total** | **%s** | **%s** | **%d/%d** |\n", lang.name, formatPercentage(lang.reached, lang.total), lang.reached, lang.total)
+	}
+	cell := ""
+	if languages != nil {
+		cell = " |"
+	}
+	var reached, total int
+	for _, pkg := range r.ungrouped() {
+		pkgReached, pkgTotal := coverageCounts(pkg)
+		reached += pkgReached
+		total += pkgTotal
+	}
+	fmt.Fprintf(w, "| **Total** |%s **%s** | **%d/%d** |\n", cell, formatPercentage(reached, total), reached, total)
+	if r.weight != nil {
+		reached, total := weightedCounts(r.ungrouped(), r.weight)
+		fmt.Fprintf(w, "| **Weighted** |%s **%s** | |\n", cell, formatPercentage(reached, total))
+	}
+	if baseline != nil {
+		fmt.Fprintln(w)
+		printReleaseNotes(w, baseName, "HEAD", baseline, &gocovutil.Document{Packages: r.packages}, 10)
+	}
+}
+
+// writeGithubSummary appends the report's summary to the file named by
+// $GITHUB_STEP_SUMMARY, with the changes since the baseline named base, if
+// any.
+func writeGithubSummary(r *report, base string, baseline *gocovutil.Document) error {
+	filename := os.Getenv("GITHUB_STEP_SUMMARY")
+	if filename == "" {
+		return fmt.Errorf("GITHUB_STEP_SUMMARY is not set; -github-summary only works in GitHub Actions")
+	}
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	printSummary(f, r, base, baseline)
+	return f.Close()
+}
+
+// annotateBuildkite adds the report's summary to the Buildkite build as
+// an annotation, with the buildkite-agent CLI available to build steps.
+// Annotating again replaces the annotation, which has the context
+// "gocov".
+func annotateBuildkite(r *report, base string, baseline *gocovutil.Document) error {
+	var buf bytes.Buffer
+	printSummary(&buf, r, base, baseline)
+	cmd := exec.Command("buildkite-agent", "annotate", "--context", "gocov", "--style", "info")
+	cmd.Stdin = &buf
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("buildkite-agent annotate: %v: %s", err, msg)
+		}
+		return fmt.Errorf("buildkite-agent annotate: %v", err)
+	}
+	return nil
+}
+
+// junitTestSuite is the JUnit XML test report in which package coverage
+// is presented to CircleCI's test metadata.
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string `xml:"classname,attr"`
+	Name      string `xml:"name,attr"`
+	SystemOut string `xml:"system-out,omitempty"`
+}
+
+// printCircleCIMetadata writes the report's package coverage as a JUnit
+// XML test suite of a test case per package, named with its coverage,
+// for CircleCI's store_test_results step to display.
+func printCircleCIMetadata(w io.Writer, r *report) error {
+	suite := junitTestSuite{Name: "coverage"}
+	for _, pkg := range r.packages {
+		reached, total := coverageCounts(pkg)
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			ClassName: "gocov",
+			Name:      fmt.Sprintf("%s: %s (%d/%d)", pkg.Name, formatPercentage(reached, total), reached, total),
+			SystemOut: fmt.Sprintf("coverage of %s: %s of %d statements", pkg.Name, formatPercentage(reached, total), total),
+		})
+	}
+	suite.Tests = len(suite.TestCases)
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeCircleCIMetadata writes the report's CircleCI test metadata to the
+// named file, creating its directory.
+func writeCircleCIMetadata(r *report, filename string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := printCircleCIMetadata(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hihoak/gocov"
+	"github.com/hihoak/gocov/gocovutil"
+)
+
+func TestPrintSummary(t *testing.T) {
+	r := newReport()
+	r.addPackage(&gocov.Package{Name: "p", Functions: []*gocov.Function{
+		{Statements: []*gocov.Statement{{Reached: 1}, {}, {}}},
+	}})
+	var buf bytes.Buffer
+	printSummary(&buf, r, "", nil)
+	want := "## Coverage\n\n| Package | Coverage | Statements |\n| --- | ---: | ---: |\n" +
+		"| p | 33.33% | 1/3 |\n| **Total** | **33.33%** | **1/3** |\n"
+	if buf.String() != want {
+		t.Errorf("printSummary = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	baseline := &gocovutil.Document{Packages: gocovutil.Packages{{Name: "p", Functions: []*gocov.Function{
+		{Statements: []*gocov.Statement{{}, {}, {}}},
+	}}}}
+	printSummary(&buf, r, "main", baseline)
+	if !strings.Contains(buf.String(), "## Coverage changes main...HEAD") {
+		t.Errorf("Expected a diff section, got:\n%s", buf.String())
+	}
+}
+
+func TestPrintCircleCIMetadata(t *testing.T) {
+	r := newReport()
+	r.addPackage(&gocov.Package{Name: "p", Functions: []*gocov.Function{
+		{Statements: []*gocov.Statement{{Reached: 1}, {}}},
+	}})
+	var buf bytes.Buffer
+	if err := printCircleCIMetadata(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="coverage" tests="1">
+  <testcase classname="gocov" name="p: 50.00% (1/2)">
+    <system-out>coverage of p: 50.00% of 2 statements</system-out>
+  </testcase>
+</testsuite>
+`
+	if buf.String() != want {
+		t.Errorf("printCircleCIMetadata = %s, want %s", buf.String(), want)
+	}
+}
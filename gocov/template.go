@@ -0,0 +1,170 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/hihoak/gocov"
+)
+
+// templateData is the data report templates are executed with.
+type templateData struct {
+	Packages []*gocov.Package
+	Labels   map[string]string
+	Inputs   []string
+}
+
+// statementCounts returns the number of reached and total statements of a
+// package or function.
+func statementCounts(v interface{}) (reached, total int, err error) {
+	switch v := v.(type) {
+	case *gocov.Package:
+		reached, total = coverageCounts(v)
+	case *gocov.Function:
+		reached, total = coverageCounts(&gocov.Package{Functions: []*gocov.Function{v}})
+	case []*gocov.Package:
+		for _, pkg := range v {
+			r, t := coverageCounts(pkg)
+			reached += r
+			total += t
+		}
+	default:
+		return 0, 0, fmt.Errorf("cannot count statements of %T", v)
+	}
+	return reached, total, nil
+}
+
+// formatPercent is the formatPercent helper of templates, formatting the
+// coverage of a package, function or list of packages, or reached and
+// total statement counts, as formatPercentage does.
+func formatPercent(args ...interface{}) (string, error) {
+	switch len(args) {
+	case 1:
+		reached, total, err := statementCounts(args[0])
+		return formatPercentage(reached, total), err
+	case 2:
+		reached, rok := args[0].(int)
+		total, tok := args[1].(int)
+		if rok && tok {
+			return formatPercentage(reached, total), nil
+		}
+	}
+	return "", fmt.Errorf("formatPercent takes a package, function or list of packages, or reached and total counts")
+}
+
+// templateFuncs are the helper functions available to report templates.
+var templateFuncs = template.FuncMap{
+	"reached": func(v interface{}) (int, error) {
+		reached, _, err := statementCounts(v)
+		return reached, err
+	},
+	"statements": func(v interface{}) (int, error) {
+		_, total, err := statementCounts(v)
+		return total, err
+	},
+	"percent": func(v interface{}) (float64, error) {
+		reached, total, err := statementCounts(v)
+		return percentage(reached, total), err
+	},
+	"formatPercent": formatPercent,
+	"base":          filepath.Base,
+	"join":          strings.Join,
+	"sortPackages": func(key string, pkgs []*gocov.Package) ([]*gocov.Package, error) {
+		sorted := make([]*gocov.Package, len(pkgs))
+		copy(sorted, pkgs)
+		less, err := templateLess(key, func(i int) (string, interface{}) { return sorted[i].Name, sorted[i] })
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(sorted, less)
+		return sorted, nil
+	},
+	"sortFunctions": func(key string, fns []*gocov.Function) ([]*gocov.Function, error) {
+		sorted := make([]*gocov.Function, len(fns))
+		copy(sorted, fns)
+		less, err := templateLess(key, func(i int) (string, interface{}) { return sorted[i].Name, sorted[i] })
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(sorted, less)
+		return sorted, nil
+	},
+}
+
+// templateLess returns a less function ordering elements by key: "name",
+// "percent" or "statements", descending if prefixed with "-". The item
+// function returns the name and the package or function at an index.
+func templateLess(key string, item func(int) (string, interface{})) (func(i, j int) bool, error) {
+	desc := strings.HasPrefix(key, "-")
+	key = strings.TrimPrefix(key, "-")
+	var value func(int) float64
+	switch key {
+	case "name":
+	case "percent":
+		value = func(i int) float64 {
+			_, v := item(i)
+			reached, total, _ := statementCounts(v)
+			return percentage(reached, total)
+		}
+	case "statements":
+		value = func(i int) float64 {
+			_, v := item(i)
+			_, total, _ := statementCounts(v)
+			return float64(total)
+		}
+	default:
+		return nil, fmt.Errorf("unknown sort key %q", key)
+	}
+	return func(i, j int) bool {
+		if value != nil {
+			if vi, vj := value(i), value(j); vi != vj {
+				return (vi < vj) != desc
+			}
+		}
+		ni, _ := item(i)
+		nj, _ := item(j)
+		if desc && value == nil {
+			return ni > nj
+		}
+		return ni < nj
+	}, nil
+}
+
+// printTemplate renders the report with the text/template in the named
+// file.
+func printTemplate(w io.Writer, r *report, filename string) error {
+	text, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New(filepath.Base(filename)).Funcs(templateFuncs).Parse(string(text))
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, &templateData{Packages: r.packages, Labels: r.labels, Inputs: r.inputs})
+}
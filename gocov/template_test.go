@@ -0,0 +1,55 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestPrintTemplate(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "report.tmpl")
+	text := `{{range sortPackages "-percent" .Packages}}{{.Name}} {{percent .}} {{reached .}}/{{statements .}}
+{{end}}{{range sortFunctions "name" (index .Packages 0).Functions}}{{.Name}} {{end}}{{formatPercent .Packages}} {{formatPercent 1 3}}`
+	if err := ioutil.WriteFile(filename, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := newReport()
+	r.addPackage(&gocov.Package{Name: "a", Functions: []*gocov.Function{
+		{Name: "g", Statements: []*gocov.Statement{{}}},
+		{Name: "f", Statements: []*gocov.Statement{{Reached: 1}}},
+	}})
+	r.addPackage(&gocov.Package{Name: "b", Functions: []*gocov.Function{
+		{Name: "h", Statements: []*gocov.Statement{{Reached: 1}}},
+	}})
+	var buf bytes.Buffer
+	if err := printTemplate(&buf, r, filename); err != nil {
+		t.Fatal(err)
+	}
+	want := "b 100 1/1\na 50 1/2\nf g 66.66% 33.33%"
+	if buf.String() != want {
+		t.Errorf("printTemplate = %q, want %q", buf.String(), want)
+	}
+}
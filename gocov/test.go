@@ -34,11 +34,49 @@ import (
 	"github.com/hihoak/gocov/gocov/internal/testflag"
 )
 
+// benchmarkLabels label coverage gathered by running benchmarks, so that
+// it can be told apart from unit test coverage when merged or compared.
+var benchmarkLabels = map[string]string{"kind": "benchmark"}
+
+// runsBenchmarks reports whether the test flags select benchmarks to run.
+func runsBenchmarks(testFlags []string) bool {
+	for i, arg := range testFlags {
+		arg = strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		arg = strings.TrimPrefix(arg, "test.")
+		switch {
+		case arg == "bench":
+			return i+1 < len(testFlags) && testFlags[i+1] != ""
+		case strings.HasPrefix(arg, "bench="):
+			return arg != "bench="
+		}
+	}
+	return false
+}
+
+// buildTags returns the -tags flag among the test flags, if any, as the
+// build flags with which packages are listed and profiles converted.
+func buildTags(testFlags []string) []string {
+	var tags []string
+	for i, arg := range testFlags {
+		arg = strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		switch {
+		case arg == "tags" && i+1 < len(testFlags):
+			tags = []string{"-tags=" + testFlags[i+1]}
+		case strings.HasPrefix(arg, "tags="):
+			tags = []string{"-" + arg}
+		}
+	}
+	return tags
+}
+
 // resolvePackages returns a slice of resolved package names, given a slice of
 // package names that could be relative or recursive.
-func resolvePackages(pkgs []string) ([]string, error) {
+func resolvePackages(dir string, pkgs []string, buildFlags, env []string) ([]string, error) {
 	var buf bytes.Buffer
-	cmd := exec.Command("go", append([]string{"list", "-e"}, pkgs...)...)
+	args := append(append([]string{"list", "-e"}, buildFlags...), pkgs...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = &buf
 	cmd.Stderr = os.Stderr
@@ -58,15 +96,27 @@ func resolvePackages(pkgs []string) ([]string, error) {
 }
 
 func runTests(args []string) error {
+	out, err := testCoverage("", args, nil)
+	os.Stdout.Write(out)
+	return err
+}
+
+// testCoverage runs "go test" in dir with the given arguments and
+// additional environment variables, returning the converted coverage.
+// An empty dir means the current directory. Packages are resolved for
+// conversion with the same build tags and environment as they were
+// tested with.
+func testCoverage(dir string, args []string, env []string) ([]byte, error) {
 	pkgs, testFlags := testflag.Split(args)
-	pkgs, err := resolvePackages(pkgs)
+	tags := buildTags(testFlags)
+	pkgs, err := resolvePackages(dir, pkgs, tags, env)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	tmpDir, err := ioutil.TempDir("", "gocov")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
 		err := os.RemoveAll(tmpDir)
@@ -82,6 +132,8 @@ func runTests(args []string) error {
 		cmdArgs := append([]string{"test", "-coverprofile", coverFile}, testFlags...)
 		cmdArgs = append(cmdArgs, pkg)
 		cmd := exec.Command("go", cmdArgs...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), env...)
 		cmd.Stdin = nil
 		// Write all test command output to stderr so as not to interfere with
 		// the JSON coverage output.
@@ -89,7 +141,7 @@ func runTests(args []string) error {
 		cmd.Stderr = os.Stderr
 		err := cmd.Run()
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -97,11 +149,13 @@ func runTests(args []string) error {
 	// ones that were created.
 	files, err := filepath.Glob(filepath.Join(tmpDir, "test*.cov"))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Merge the profiles.
-	out, err := convert.ConvertProfiles(files...)
-	os.Stdout.Write(out)
-	return err
+	opts := []convert.Option{convert.WithDir(dir), convert.WithBuildFlags(tags...), convert.WithEnv(env...)}
+	if runsBenchmarks(testFlags) {
+		opts = append(opts, convert.WithLabels(benchmarkLabels))
+	}
+	return convert.Convert(files, opts...)
 }
@@ -0,0 +1,64 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRunsBenchmarks(t *testing.T) {
+	tests := []struct {
+		flags []string
+		want  bool
+	}{
+		{nil, false},
+		{[]string{"-v"}, false},
+		{[]string{"-bench", "."}, true},
+		{[]string{"-bench=."}, true},
+		{[]string{"--test.bench=Foo"}, true},
+		{[]string{"-bench="}, false},
+		{[]string{"-benchmem"}, false},
+	}
+	for _, test := range tests {
+		if got := runsBenchmarks(test.flags); got != test.want {
+			t.Errorf("runsBenchmarks(%q) = %v, want %v", test.flags, got, test.want)
+		}
+	}
+}
+
+func TestBuildTags(t *testing.T) {
+	tests := []struct {
+		flags []string
+		want  []string
+	}{
+		{nil, nil},
+		{[]string{"-v", "-run", "Foo"}, nil},
+		{[]string{"-tags", "integration"}, []string{"-tags=integration"}},
+		{[]string{"--tags=a,b", "-v"}, []string{"-tags=a,b"}},
+		{[]string{"-tags"}, nil},
+	}
+	for _, test := range tests {
+		if got := buildTags(test.flags); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("buildTags(%q) = %q, want %q", test.flags, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// testPriority ranks a test by the most recent change it covers, then by
+// how many statements changed at that time it reaches.
+type testPriority struct {
+	name   string
+	latest int64
+	count  int
+}
+
+// prioritizeTests orders the tests most recently changed code first.
+func prioritizeTests(tests []testPriority) {
+	sort.SliceStable(tests, func(i, j int) bool {
+		if tests[i].latest != tests[j].latest {
+			return tests[i].latest > tests[j].latest
+		}
+		if tests[i].count != tests[j].count {
+			return tests[i].count > tests[j].count
+		}
+		return tests[i].name < tests[j].name
+	})
+}
+
+// printTestOrder prints the names of the tests of a per-test attributed
+// merge, one per line, ordered so that tests reaching the most recently
+// changed statements (according to "git blame") come first. Runners can
+// execute tests in this order to fail fast on risky changes.
+func printTestOrder(w io.Writer, r *report) error {
+	if len(r.inputs) == 0 {
+		return fmt.Errorf("test order requires per-test attribution (see gocov merge -attribute)")
+	}
+	sources := newSourceFiles()
+	sources.preload(r.packages)
+	blames := make(map[string]map[int]blameLine)
+	tests := make([]testPriority, len(r.inputs))
+	for i, input := range r.inputs {
+		tests[i].name = testName(input)
+	}
+	for _, pkg := range r.packages {
+		for _, fn := range pkg.Functions {
+			lines, ok := blames[fn.File]
+			if !ok {
+				var err error
+				lines, err = blameFile(fn.File)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+				}
+				blames[fn.File] = lines
+			}
+			for _, stmt := range fn.Statements {
+				if stmt.Reached == 0 {
+					continue
+				}
+				pos, err := sources.position(fn.File, stmt.Start)
+				if err != nil {
+					return err
+				}
+				changed := lines[pos.Line].time
+				for i := range tests {
+					if !stmt.AttributedTo(i) {
+						continue
+					}
+					switch {
+					case changed > tests[i].latest:
+						tests[i].latest, tests[i].count = changed, 1
+					case changed == tests[i].latest:
+						tests[i].count++
+					}
+				}
+			}
+		}
+	}
+	prioritizeTests(tests)
+	for _, test := range tests {
+		if _, err := fmt.Fprintln(w, test.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
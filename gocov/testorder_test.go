@@ -0,0 +1,41 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestPrioritizeTests(t *testing.T) {
+	tests := []testPriority{
+		{name: "TestOld", latest: 100, count: 5},
+		{name: "TestNewFew", latest: 200, count: 1},
+		{name: "TestNewMany", latest: 200, count: 3},
+		{name: "TestNone"},
+	}
+	prioritizeTests(tests)
+	want := []string{"TestNewMany", "TestNewFew", "TestOld", "TestNone"}
+	for i, test := range tests {
+		if test.name != want[i] {
+			t.Errorf("tests[%d] = %s, want %s", i, test.name, want[i])
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2026 The Gocov Authors.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to
+// deal in the Software without restriction, including without limitation the
+// rights to use, copy, modify, merge, publish, distribute, sublicense, and/or
+// sell copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS
+// IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/hihoak/gocov/gocov/convert"
+	"golang.org/x/tools/cover"
+)
+
+var (
+	verifyFlags      = flag.NewFlagSet("verify", flag.ExitOnError)
+	verifyFlagValues = addConvertFlags(verifyFlags)
+)
+
+// profileDifferences describes how the profiles got differ from those
+// wanted, which are keyed by file name as parsed by cover.ParseProfiles.
+func profileDifferences(want, got []*cover.Profile) []string {
+	gotByName := make(map[string]*cover.Profile, len(got))
+	for _, p := range got {
+		gotByName[p.FileName] = p
+	}
+	var diffs []string
+	for _, p := range want {
+		g := gotByName[p.FileName]
+		delete(gotByName, p.FileName)
+		switch {
+		case g == nil:
+			diffs = append(diffs, fmt.Sprintf("%s: missing from conversion", p.FileName))
+		case g.Mode != p.Mode:
+			diffs = append(diffs, fmt.Sprintf("%s: mode %s, want %s", p.FileName, g.Mode, p.Mode))
+		case !reflect.DeepEqual(g.Blocks, p.Blocks):
+			diffs = append(diffs, fmt.Sprintf("%s: blocks differ", p.FileName))
+		}
+	}
+	for _, p := range got {
+		if gotByName[p.FileName] != nil {
+			diffs = append(diffs, fmt.Sprintf("%s: not in profile", p.FileName))
+		}
+	}
+	return diffs
+}
+
+// verifyProfile converts the named profile, reconstructs a profile from
+// the result, and returns the differences between the two.
+func verifyProfile(filename string, opts []convert.Option) ([]string, error) {
+	want, err := cover.ParseProfiles(filename)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, convert.WithBlocks())
+	out, err := convert.Convert([]string{filename}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := unmarshalDocument(out)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := convert.WriteProfile(&buf, doc.Packages); err != nil {
+		return nil, err
+	}
+	got, err := cover.ParseProfilesFromReader(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return profileDifferences(want, got), nil
+}
+
+// verifyProfiles checks that converting each of the named profiles loses
+// no information, by reconstructing them from the converted blocks.
+func verifyProfiles() (rc int) {
+	verifyFlags.Parse(os.Args[2:])
+	if verifyFlags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "missing cover profile")
+		return 1
+	}
+	opts, err := verifyFlagValues.options()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	for _, filename := range verifyFlags.Args() {
+		diffs, err := verifyProfile(filename, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to verify %s: %s\n", filename, err)
+			printResolveHint(os.Stderr, err)
+			return 1
+		}
+		for _, diff := range diffs {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", filename, diff)
+			rc = 1
+		}
+	}
+	return rc
+}
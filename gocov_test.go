@@ -136,3 +136,80 @@ func TestAccumulateStatement(t *testing.T) {
 		t.Errorf("Expected an error")
 	}
 }
+
+func TestAccumulateFiles(t *testing.T) {
+	block := func(count int64) *Block {
+		return &Block{StartLine: 1, StartCol: 2, EndLine: 3, EndCol: 4, NumStmt: 1, Count: count}
+	}
+	p1 := &Package{Name: "p", Files: []*File{{File: "a.go", Blocks: []*Block{block(1)}}}}
+	p2 := &Package{Name: "p", Files: []*File{
+		{File: "a.go", Blocks: []*Block{block(2)}},
+		{File: "b.go", Blocks: []*Block{block(5)}},
+	}}
+	if err := p1.Accumulate(p2); err != nil {
+		t.Fatal(err)
+	}
+	if len(p1.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(p1.Files))
+	}
+	if count := p1.Files[0].Blocks[0].Count; count != 3 {
+		t.Errorf("Expected count 3, got %d", count)
+	}
+
+	// Should fail: ranges are not the same.
+	f := &File{File: "a.go", Blocks: []*Block{{StartLine: 9}}}
+	if err := p1.Files[0].Accumulate(f); err == nil {
+		t.Errorf("Expected an error")
+	}
+}
+
+func TestAccumulateAttribution(t *testing.T) {
+	p := registerPackage("p1")
+	f := registerFunction(p, "f1", "file.go", 0, 1)
+	s1 := registerStatement(f, 0, 1)
+	s2 := &Statement{Start: 0, End: 1}
+	s1.Attribute(0)
+	s2.Attribute(70)
+
+	if err := s1.Accumulate(s2); err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{0, 70} {
+		if !s1.AttributedTo(i) {
+			t.Errorf("Expected statement to be attributed to input %d", i)
+		}
+	}
+	if s1.AttributedTo(1) {
+		t.Errorf("Did not expect statement to be attributed to input 1")
+	}
+	if f.UniquelyAttributedTo(0) {
+		t.Errorf("Did not expect input 0 to uniquely cover the function")
+	}
+
+	registerStatement(f, 1, 2).Attribute(0)
+	if !f.UniquelyAttributedTo(0) {
+		t.Errorf("Expected input 0 to uniquely cover the function")
+	}
+}
+
+func TestSourceFiles(t *testing.T) {
+	p := &Package{Name: "p", Functions: []*Function{
+		{Name: "f", File: "b.go", Statements: []*Statement{{Reached: 1}, {}}},
+		{Name: "g", File: "a.go", Statements: []*Statement{{}}},
+		{Name: "h", File: "b.go", Statements: []*Statement{{Reached: 2}}},
+	}}
+	files := p.SourceFiles()
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+	if files[0].File != "a.go" || len(files[0].Functions) != 1 {
+		t.Errorf("Unexpected first file: %+v", files[0])
+	}
+	b := files[1]
+	if len(b.Functions) != 2 || b.Functions[0].Name != "f" || b.Functions[1].Name != "h" {
+		t.Errorf("Unexpected functions of b.go: %+v", b.Functions)
+	}
+	if b.Statements != 3 || b.Reached != 2 {
+		t.Errorf("Expected 2/3 statements reached in b.go, got %d/%d", b.Reached, b.Statements)
+	}
+}
@@ -0,0 +1,335 @@
+package gocovutil
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hihoak/gocov"
+)
+
+// StatementChange identifies a statement whose coverage changed between
+// two sets of packages, by its package, function, file and source range.
+type StatementChange struct {
+	Package  string
+	Function string
+	File     string
+
+	// Start and End are the offsets of the statement in File.
+	Start, End int
+}
+
+// StatementDiff holds the statements whose coverage changed between two
+// sets of packages.
+type StatementDiff struct {
+	// Covered lists the statements reached in the new packages but not
+	// in the old, including statements new since the old packages.
+	Covered []StatementChange
+
+	// Uncovered lists the statements reached in the old packages but not
+	// in the new. Statements that no longer exist are not listed.
+	Uncovered []StatementChange
+}
+
+// DiffStatements compares the coverage of the statements of packages a and
+// b, and returns those that became covered or uncovered in b. Statements
+// are matched by the stable identity of their function (see StableID)
+// and their source range relative to the function's, so that functions
+// moving within or between files do not make their statements appear
+// changed. Changes are ordered by package, file and offset.
+func DiffStatements(a, b Packages) *StatementDiff {
+	type key struct {
+		function   string
+		start, end int
+	}
+	var ids Identities
+	reached := make(map[key]bool)
+	for _, pkg := range a {
+		for _, fn := range pkg.Functions {
+			id := StableID(pkg, fn)
+			ids.Add(id)
+			for _, stmt := range fn.Statements {
+				k := key{id, stmt.Start - fn.Start, stmt.End - fn.Start}
+				reached[k] = reached[k] || stmt.Reached > 0
+			}
+		}
+	}
+	d := &StatementDiff{}
+	for _, pkg := range b {
+		for _, fn := range pkg.Functions {
+			id, _ := ids.Match(StableID(pkg, fn))
+			for _, stmt := range fn.Statements {
+				before := reached[key{id, stmt.Start - fn.Start, stmt.End - fn.Start}]
+				if before == (stmt.Reached > 0) {
+					continue
+				}
+				change := StatementChange{pkg.Name, fn.Name, fn.File, stmt.Start, stmt.End}
+				if before {
+					d.Uncovered = append(d.Uncovered, change)
+				} else {
+					d.Covered = append(d.Covered, change)
+				}
+			}
+		}
+	}
+	sortChanges(d.Covered)
+	sortChanges(d.Uncovered)
+	return d
+}
+
+func sortChanges(changes []StatementChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Start < b.Start
+	})
+}
+
+// FunctionRef identifies a function of a set of packages, with its
+// coverage.
+type FunctionRef struct {
+	Package  string
+	Function string
+	File     string
+
+	// Reached and Statements count the function's statements reached
+	// and in total.
+	Reached, Statements int
+}
+
+// FunctionMove records that a function was moved to another file or
+// package, or renamed.
+type FunctionMove struct {
+	From, To FunctionRef
+
+	// Similarity is the similarity of the statements of the two
+	// functions, from 0 to 1.
+	Similarity float64
+}
+
+// FunctionDiff holds the functions added, removed and moved between two
+// sets of packages. Functions that kept their identity, or only changed
+// signature, are not listed.
+type FunctionDiff struct {
+	Added   []FunctionRef
+	Removed []FunctionRef
+	Moved   []FunctionMove
+}
+
+// minSimilarity is the similarity above which a removed function and an
+// added one are taken to be the same function, moved or renamed.
+const minSimilarity = 0.8
+
+// DiffFunctions compares the functions of packages a and b. Functions are
+// matched by stable identity (see StableID); failing that, by identity
+// but for their file, as when their file was renamed, or but for their
+// signature, as when it changed. The functions that remain are taken to
+// be moved or renamed when they have the same signature, or in the
+// absence of signatures the same name, and statements of similar kinds
+// and lengths. Functions of fewer than three statements, whose similarity
+// says little, must keep their name to be matched this way. Changes are
+// ordered by package and function.
+func DiffFunctions(a, b Packages) *FunctionDiff {
+	type function struct {
+		ref   FunctionRef
+		fn    *gocov.Function
+		id    string
+		match int
+	}
+	collect := func(ps Packages) []*function {
+		var functions []*function
+		for _, pkg := range ps {
+			for _, fn := range pkg.Functions {
+				ref := FunctionRef{Package: pkg.Name, Function: fn.Name, File: fn.File, Statements: len(fn.Statements)}
+				for _, stmt := range fn.Statements {
+					if stmt.Reached > 0 {
+						ref.Reached++
+					}
+				}
+				functions = append(functions, &function{ref, fn, StableID(pkg, fn), -1})
+			}
+		}
+		return functions
+	}
+	from, to := collect(a), collect(b)
+	d := &FunctionDiff{}
+
+	// matchBy matches the unmatched functions whose keys are equal, and
+	// unique among the unmatched functions of their set.
+	matchBy := func(key func(id string) string, moved bool) {
+		unique := func(functions []*function) map[string]int {
+			m := make(map[string]int)
+			for i, f := range functions {
+				if f.match >= 0 {
+					continue
+				}
+				k := key(f.id)
+				if _, ok := m[k]; ok {
+					m[k] = -1
+				} else {
+					m[k] = i
+				}
+			}
+			return m
+		}
+		fromKeys, toKeys := unique(from), unique(to)
+		for k, j := range toKeys {
+			if i, ok := fromKeys[k]; ok && i >= 0 && j >= 0 {
+				from[i].match, to[j].match = j, i
+				if moved {
+					d.Moved = append(d.Moved, FunctionMove{from[i].ref, to[j].ref, shapeSimilarity(from[i].fn, to[j].fn)})
+				}
+			}
+		}
+	}
+	// Identities need not be unique, as the variants of a function
+	// merged from several revisions share one; all functions of an
+	// identity in either set match.
+	ids := make(map[string]int)
+	for i, f := range from {
+		if _, ok := ids[f.id]; !ok {
+			ids[f.id] = i
+		}
+	}
+	matched := make(map[string]int)
+	for j, f := range to {
+		if i, ok := ids[f.id]; ok {
+			f.match = i
+			matched[f.id] = j
+		}
+	}
+	for _, f := range from {
+		if j, ok := matched[f.id]; ok {
+			f.match = j
+		}
+	}
+	matchBy(withoutFile, true)
+	matchBy(withoutSignature, false)
+
+	// Pair the remaining functions by similarity, most similar first.
+	type pair struct {
+		i, j       int
+		similarity float64
+	}
+	group := func(f *function) string {
+		if hash := signatureHash(f.id); hash != "" {
+			return hash
+		}
+		return "name:" + shortName(f.ref.Function)
+	}
+	candidates := make(map[string][]int)
+	for i, f := range from {
+		if f.match < 0 && len(f.fn.Statements) > 0 {
+			candidates[group(f)] = append(candidates[group(f)], i)
+		}
+	}
+	var pairs []pair
+	for j, f := range to {
+		if f.match >= 0 || len(f.fn.Statements) == 0 {
+			continue
+		}
+		for _, i := range candidates[group(f)] {
+			short := len(f.fn.Statements) < 3 || len(from[i].fn.Statements) < 3
+			if short && shortName(f.ref.Function) != shortName(from[i].ref.Function) {
+				continue
+			}
+			if s := shapeSimilarity(from[i].fn, f.fn); s >= minSimilarity {
+				pairs = append(pairs, pair{i, j, s})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(x, y int) bool { return pairs[x].similarity > pairs[y].similarity })
+	for _, p := range pairs {
+		if from[p.i].match >= 0 || to[p.j].match >= 0 {
+			continue
+		}
+		from[p.i].match, to[p.j].match = p.j, p.i
+		d.Moved = append(d.Moved, FunctionMove{from[p.i].ref, to[p.j].ref, p.similarity})
+	}
+
+	for _, f := range from {
+		if f.match < 0 {
+			d.Removed = append(d.Removed, f.ref)
+		}
+	}
+	for _, f := range to {
+		if f.match < 0 {
+			d.Added = append(d.Added, f.ref)
+		}
+	}
+	sortFunctions(d.Added)
+	sortFunctions(d.Removed)
+	sort.SliceStable(d.Moved, func(i, j int) bool {
+		return lessFunction(d.Moved[i].From, d.Moved[j].From)
+	})
+	return d
+}
+
+// withoutSignature returns a function identity with its signature hash
+// removed.
+func withoutSignature(id string) string {
+	if i := strings.LastIndex(id, "#"); i >= 0 {
+		return id[:i]
+	}
+	return id
+}
+
+// signatureHash returns the signature hash of a function identity, or ""
+// if it has none.
+func signatureHash(id string) string {
+	if i := strings.LastIndex(id, "#"); i >= 0 {
+		return id[i+1:]
+	}
+	return ""
+}
+
+// shortName returns a function's name without its receiver type or
+// enclosing functions.
+func shortName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// shapeSimilarity returns the similarity of the statements of two
+// functions: twice the length of the longest common subsequence of their
+// statements' kinds and lengths, over their total number of statements.
+func shapeSimilarity(f1, f2 *gocov.Function) float64 {
+	n1, n2 := len(f1.Statements), len(f2.Statements)
+	if n1+n2 == 0 {
+		return 1
+	}
+	same := func(s1, s2 *gocov.Statement) bool {
+		return s1.Kind == s2.Kind && s1.End-s1.Start == s2.End-s2.Start
+	}
+	prev, cur := make([]int, n2+1), make([]int, n2+1)
+	for i := 1; i <= n1; i++ {
+		for j := 1; j <= n2; j++ {
+			switch {
+			case same(f1.Statements[i-1], f2.Statements[j-1]):
+				cur[j] = prev[j-1] + 1
+			case prev[j] > cur[j-1]:
+				cur[j] = prev[j]
+			default:
+				cur[j] = cur[j-1]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return 2 * float64(prev[n2]) / float64(n1+n2)
+}
+
+func lessFunction(a, b FunctionRef) bool {
+	if a.Package != b.Package {
+		return a.Package < b.Package
+	}
+	return a.Function < b.Function
+}
+
+func sortFunctions(functions []FunctionRef) {
+	sort.SliceStable(functions, func(i, j int) bool {
+		return lessFunction(functions[i], functions[j])
+	})
+}
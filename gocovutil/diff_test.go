@@ -0,0 +1,124 @@
+package gocovutil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestDiffStatements(t *testing.T) {
+	stmt := func(start int, reached int64) *gocov.Statement {
+		return &gocov.Statement{Start: start, End: start + 5, Reached: reached}
+	}
+	a := Packages{{Name: "p", Functions: []*gocov.Function{
+		{Name: "f", File: "f.go", Statements: []*gocov.Statement{stmt(10, 1), stmt(20, 0), stmt(30, 1)}},
+		{Name: "gone", File: "f.go", Statements: []*gocov.Statement{stmt(90, 1)}},
+	}}}
+	b := Packages{{Name: "p", Functions: []*gocov.Function{
+		{Name: "f", File: "f.go", Statements: []*gocov.Statement{stmt(10, 3), stmt(20, 1), stmt(30, 0)}},
+		{Name: "added", File: "f.go", Statements: []*gocov.Statement{stmt(50, 1), stmt(60, 0)}},
+	}}}
+	d := DiffStatements(a, b)
+	covered := []StatementChange{
+		{"p", "f", "f.go", 20, 25},
+		{"p", "added", "f.go", 50, 55},
+	}
+	uncovered := []StatementChange{
+		{"p", "f", "f.go", 30, 35},
+	}
+	if !reflect.DeepEqual(d.Covered, covered) {
+		t.Errorf("Covered = %v, want %v", d.Covered, covered)
+	}
+	if !reflect.DeepEqual(d.Uncovered, uncovered) {
+		t.Errorf("Uncovered = %v, want %v", d.Uncovered, uncovered)
+	}
+}
+
+func TestDiffStatementsMoved(t *testing.T) {
+	fn := func(file string, start int, reached int64) *gocov.Function {
+		return &gocov.Function{Name: "f", File: file, Start: start, End: start + 20,
+			ID:         FunctionID("p", file, "f", "(int) error"),
+			Statements: []*gocov.Statement{{Start: start + 5, End: start + 10, Reached: reached}}}
+	}
+
+	// A function moving within its file, or to a renamed file, keeps
+	// the coverage of its statements.
+	a := Packages{{Name: "p", Functions: []*gocov.Function{fn("f.go", 10, 1)}}}
+	for _, file := range []string{"f.go", "g.go"} {
+		b := Packages{{Name: "p", Functions: []*gocov.Function{fn(file, 200, 1)}}}
+		if d := DiffStatements(a, b); len(d.Covered) != 0 || len(d.Uncovered) != 0 {
+			t.Errorf("Expected no changes moving f to %s, got %v", file, d)
+		}
+	}
+
+	b := Packages{{Name: "p", Functions: []*gocov.Function{fn("g.go", 200, 0)}}}
+	want := []StatementChange{{"p", "f", "g.go", 205, 210}}
+	if d := DiffStatements(a, b); !reflect.DeepEqual(d.Uncovered, want) {
+		t.Errorf("Uncovered = %v, want %v", d.Uncovered, want)
+	}
+
+	// A baseline converted before identities were recorded lines up
+	// with coverage converted since.
+	old := Packages{{Name: "p", Functions: []*gocov.Function{{Name: "f", File: "/src/p/f.go", Start: 10, End: 30,
+		Statements: []*gocov.Statement{{Start: 15, End: 20, Reached: 1}}}}}}
+	b = Packages{{Name: "p", Functions: []*gocov.Function{fn("f.go", 10, 1)}}}
+	b[0].Functions[0].File = "/src/p/f.go"
+	if d := DiffStatements(old, b); len(d.Covered) != 0 || len(d.Uncovered) != 0 {
+		t.Errorf("Expected no changes from an old baseline, got %v", d)
+	}
+	b[0].Functions[0].Statements[0].Reached = 0
+	want = []StatementChange{{"p", "f", "/src/p/f.go", 15, 20}}
+	if d := DiffStatements(old, b); !reflect.DeepEqual(d.Uncovered, want) || len(d.Covered) != 0 {
+		t.Errorf("DiffStatements from an old baseline = %v, want uncovered %v", d, want)
+	}
+}
+
+func TestDiffFunctions(t *testing.T) {
+	fn := func(name, file, signature string, kinds ...string) *gocov.Function {
+		f := &gocov.Function{Name: name, File: file, ID: FunctionID("p", file, name, signature)}
+		for i, kind := range kinds {
+			f.Statements = append(f.Statements, &gocov.Statement{Start: i * 10, End: i*10 + 5, Kind: kind, Reached: 1})
+		}
+		return f
+	}
+	a := Packages{{Name: "p", Functions: []*gocov.Function{
+		fn("Same", "p.go", "()", "call"),
+		fn("Renamed", "p.go", "(int) error", "if", "return", "call", "return"),
+		fn("Refiled", "old.go", "()", "call"),
+		fn("Resigned", "p.go", "()", "call"),
+		fn("Gone", "p.go", "(string)", "call"),
+		fn("Get", "p.go", "() int", "return"),
+	}}}
+	b := Packages{{Name: "p", Functions: []*gocov.Function{
+		fn("Same", "p.go", "()", "call"),
+		fn("Checked", "q.go", "(int) error", "if", "return", "call", "return"),
+		fn("Refiled", "new.go", "()", "call"),
+		fn("Resigned", "p.go", "(bool)", "call"),
+		fn("New", "p.go", "(string)", "assign", "for", "call"),
+		fn("Value", "p.go", "() int", "return"),
+	}}}
+	d := DiffFunctions(a, b)
+	var moves []string
+	for _, m := range d.Moved {
+		moves = append(moves, m.From.Function+" "+m.From.File+" -> "+m.To.Function+" "+m.To.File)
+	}
+	if want := []string{"Refiled old.go -> Refiled new.go", "Renamed p.go -> Checked q.go"}; !reflect.DeepEqual(moves, want) {
+		t.Errorf("Moved = %q, want %q", moves, want)
+	}
+	name := func(functions []FunctionRef) []string {
+		var names []string
+		for _, f := range functions {
+			names = append(names, f.Function)
+		}
+		return names
+	}
+	// Get and Value look alike, but are too short to be matched by
+	// their statements alone.
+	if got, want := name(d.Added), []string{"New", "Value"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Added = %q, want %q", got, want)
+	}
+	if got, want := name(d.Removed), []string{"Get", "Gone"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Removed = %q, want %q", got, want)
+	}
+}
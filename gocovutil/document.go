@@ -0,0 +1,71 @@
+package gocovutil
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Document is the top-level object of gocov's JSON interchange format.
+type Document struct {
+	Packages Packages
+
+	// Labels holds optional metadata describing the run that produced
+	// the coverage, e.g. "suite": "integration".
+	Labels map[string]string `json:",omitempty"`
+
+	// Inputs names the inputs of a merge performed with attribution;
+	// bit i of a statement's Attribution refers to Inputs[i].
+	Inputs []string `json:",omitempty"`
+
+	// Signature optionally holds a base64 encoded ed25519 signature of
+	// the rest of the document; see SignDocument.
+	Signature string `json:",omitempty"`
+}
+
+// LabelString formats the document's labels as a sorted, comma-separated
+// list of key=value pairs.
+func (d *Document) LabelString() string {
+	keys := make([]string, 0, len(d.Labels))
+	for k := range d.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + d.Labels[k]
+	}
+	return strings.Join(pairs, ",")
+}
+
+// ReadDocument reads and parses the named file as a Document.
+//
+// The special filename "-" may be used to indicate standard input.
+func ReadDocument(filename string) (*Document, error) {
+	var r io.Reader = os.Stdin
+	if filename != "-" {
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r = file
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	doc := &Document{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// WriteDocument writes the document to w in JSON form.
+func WriteDocument(w io.Writer, d *Document) error {
+	return json.NewEncoder(w).Encode(d)
+}
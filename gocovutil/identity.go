@@ -0,0 +1,123 @@
+package gocovutil
+
+import (
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"strings"
+
+	"github.com/hihoak/gocov"
+)
+
+// FunctionID returns the stable identity of a function, of the form
+// "package:file:name#hash", where file is the name of the function's
+// source file relative to the package's directory and hash is a hash of
+// the function's signature. The identity does not depend on where the
+// function is declared in its file, so it survives declarations being
+// reordered and code around them changing. An empty signature, as for
+// functions of languages other than Go, omits the hash.
+func FunctionID(pkg, file, name, signature string) string {
+	id := pkg + ":" + file + ":" + name
+	if signature != "" {
+		h := fnv.New32a()
+		h.Write([]byte(signature))
+		id += fmt.Sprintf("#%08x", h.Sum32())
+	}
+	return id
+}
+
+// StableID returns the stable identity of a function of the package: the
+// ID recorded for it when it was converted, or else one derived from its
+// package, file and name, for coverage converted before identities were
+// recorded.
+func StableID(pkg *gocov.Package, fn *gocov.Function) string {
+	if fn.ID != "" {
+		return fn.ID
+	}
+	return FunctionID(pkg.Name, filepath.Base(fn.File), fn.Name, "")
+}
+
+// withoutFile returns a function identity with its file removed, which
+// identifies the function as long as its name and signature are unique
+// in its package.
+func withoutFile(id string) string {
+	rest, hash := id, ""
+	if i := strings.LastIndex(id, "#"); i >= 0 {
+		rest, hash = id[:i], id[i:]
+	}
+	name := strings.LastIndex(rest, ":")
+	if name < 0 {
+		return id
+	}
+	file := strings.LastIndex(rest[:name], ":")
+	if file < 0 {
+		return id
+	}
+	return rest[:file] + ":" + rest[name:] + hash
+}
+
+// Identities is a set of stable function identities, for matching the
+// functions of coverage gathered at different revisions. The zero value
+// is an empty set.
+type Identities struct {
+	ids map[string]bool
+
+	// moved maps identities without their file to the identity they
+	// came from, or to "" if several did, and unsigned likewise maps
+	// identities without their signature hash.
+	moved    map[string]string
+	unsigned map[string]string
+}
+
+// Add adds an identity to the set.
+func (x *Identities) Add(id string) {
+	if x.ids == nil {
+		x.ids = make(map[string]bool)
+		x.moved = make(map[string]string)
+		x.unsigned = make(map[string]string)
+	}
+	x.ids[id] = true
+	index := func(m map[string]string, key string) {
+		if other, ok := m[key]; ok && other != id {
+			m[key] = ""
+		} else {
+			m[key] = id
+		}
+	}
+	index(x.moved, withoutFile(id))
+	index(x.unsigned, withoutSignature(id))
+}
+
+// Match returns the identity in the set matching id: id itself if the set
+// holds it, or else the only identity in the set differing from it in
+// file alone, as when the function's file was renamed, or else the only
+// one differing from it in having a signature hash where it has none, or
+// none where it has one, as when one of them was converted before
+// identities recorded signatures. It reports false if there is no such
+// identity.
+func (x *Identities) Match(id string) (string, bool) {
+	if x.ids[id] {
+		return id, true
+	}
+	if other := x.moved[withoutFile(id)]; other != "" {
+		return other, true
+	}
+	if other := x.unsigned[withoutSignature(id)]; other != "" && (signatureHash(id) == "" || signatureHash(other) == "") {
+		return other, true
+	}
+	return "", false
+}
+
+// WithoutTypeParams strips type parameters from a function name, so that
+// the methods of generic types, named T[K].M, match their names in type
+// information.
+func WithoutTypeParams(name string) string {
+	for {
+		i := strings.Index(name, "[")
+		j := strings.Index(name, "]")
+		if i < 0 || j < i {
+			return name
+		}
+		name = name[:i] + name[j+1:]
+	}
+}
@@ -0,0 +1,72 @@
+package gocovutil
+
+import (
+	"testing"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestFunctionID(t *testing.T) {
+	id := FunctionID("example.com/p", "p.go", "T.F", "(*T)(int) error")
+	if id != FunctionID("example.com/p", "p.go", "T.F", "(*T)(int) error") {
+		t.Errorf("Expected identities of the same function to be equal")
+	}
+	if id == FunctionID("example.com/p", "p.go", "T.F", "(*T)(string) error") {
+		t.Errorf("Expected identities of different signatures to differ")
+	}
+	if got, want := withoutFile(id), "example.com/p::T.F"+id[len(id)-9:]; got != want {
+		t.Errorf("withoutFile(%q) = %q, want %q", id, got, want)
+	}
+	if got := FunctionID("p", "p.js", "@file", ""); got != "p:p.js:@file" {
+		t.Errorf("Expected an identity without signature hash, got %q", got)
+	}
+
+	pkg := &gocov.Package{Name: "p"}
+	if got := StableID(pkg, &gocov.Function{Name: "F", File: "/src/p/p.go"}); got != "p:p.go:F" {
+		t.Errorf("Expected an identity derived from package, file and name, got %q", got)
+	}
+	if got := StableID(pkg, &gocov.Function{Name: "F", File: "/src/p/p.go", ID: id}); got != id {
+		t.Errorf("Expected the recorded identity, got %q", got)
+	}
+}
+
+func TestWithoutTypeParams(t *testing.T) {
+	for name, want := range map[string]string{
+		"F":               "F",
+		"T[K].M":          "T.M",
+		"T[K, V].M.func1": "T.M.func1",
+	} {
+		if got := WithoutTypeParams(name); got != want {
+			t.Errorf("WithoutTypeParams(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestIdentities(t *testing.T) {
+	var ids Identities
+	if _, ok := ids.Match("p:p.go:F"); ok {
+		t.Errorf("Expected no match in the empty set")
+	}
+	ids.Add("p:p.go:F")
+	ids.Add("p:a.go:init")
+	ids.Add("p:b.go:init")
+	ids.Add("p:s.go:G#89abcdef")
+	for _, test := range []struct {
+		id, want string
+	}{
+		{"p:p.go:F", "p:p.go:F"},
+		{"p:q.go:F", "p:p.go:F"},
+		{"p:c.go:init", ""},
+		{"q:p.go:F", ""},
+		// Identities recorded before signatures were match those
+		// recorded since, and the other way round.
+		{"p:p.go:F#0123abcd", "p:p.go:F"},
+		{"p:s.go:G", "p:s.go:G#89abcdef"},
+		{"p:s.go:G#01234567", ""},
+	} {
+		got, ok := ids.Match(test.id)
+		if got != test.want || ok != (test.want != "") {
+			t.Errorf("Match(%q) = %q, %v, want %q", test.id, got, ok, test.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package gocovutil
+
+import (
+	"io/fs"
+	"sort"
+	"sync"
+
+	"github.com/hihoak/gocov"
+)
+
+// Index provides lookups of the packages and functions of a set of
+// packages, for programs that query coverage at scale. It is built once,
+// in linear time, and reflects the packages at that time; lookups by
+// import path take constant time, and lookups by position binary search
+// the functions of the file. An Index may be used by multiple goroutines.
+type Index struct {
+	packages  map[string]*gocov.Package
+	functions map[string][]*gocov.Function // by file, in order of start
+
+	// fsys is the file system from which sources are read to map lines
+	// to offsets; see ReadSource.
+	fsys fs.FS
+
+	mu    sync.Mutex
+	lines map[string][]int // offsets of the line starts of each file
+}
+
+// NewIndex indexes the packages. Sources are read from fsys, or from the
+// operating system's file system if fsys is nil, as for ReadSource.
+func NewIndex(ps Packages, fsys fs.FS) *Index {
+	x := &Index{
+		packages:  make(map[string]*gocov.Package, len(ps)),
+		functions: make(map[string][]*gocov.Function),
+		fsys:      fsys,
+		lines:     make(map[string][]int),
+	}
+	for _, p := range ps {
+		x.packages[p.Name] = p
+		for _, f := range p.Functions {
+			x.functions[f.File] = append(x.functions[f.File], f)
+		}
+	}
+	for _, functions := range x.functions {
+		sort.SliceStable(functions, func(i, j int) bool { return functions[i].Start < functions[j].Start })
+	}
+	return x
+}
+
+// ByImportPath returns the package with the given import path, or nil.
+func (x *Index) ByImportPath(path string) *gocov.Package {
+	return x.packages[path]
+}
+
+// FunctionAt returns the innermost function of the file that spans the
+// given 1-based line, such as a function literal rather than the function
+// enclosing it, or nil if there is none. The file's source is read once,
+// to locate its lines.
+func (x *Index) FunctionAt(file string, line int) (*gocov.Function, error) {
+	functions := x.functions[file]
+	if len(functions) == 0 || line < 1 {
+		return nil, nil
+	}
+	starts, err := x.lineStarts(file)
+	if err != nil {
+		return nil, err
+	}
+	if line > len(starts) {
+		return nil, nil
+	}
+	start, end := starts[line-1], -1
+	if line < len(starts) {
+		end = starts[line]
+	}
+	// Only functions starting before the end of the line can span it;
+	// of those, the last to start that has not ended is the innermost.
+	n := len(functions)
+	if end >= 0 {
+		n = sort.Search(len(functions), func(i int) bool { return functions[i].Start >= end })
+	}
+	for i := n - 1; i >= 0; i-- {
+		if functions[i].End >= start {
+			return functions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// lineStarts returns the offsets of the starts of the lines of a file.
+func (x *Index) lineStarts(file string) ([]int, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if starts, ok := x.lines[file]; ok {
+		return starts, nil
+	}
+	src, err := ReadSource(x.fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	starts := []int{0}
+	for i, b := range src {
+		if b == '\n' && i+1 < len(src) {
+			starts = append(starts, i+1)
+		}
+	}
+	x.lines[file] = starts
+	return starts, nil
+}
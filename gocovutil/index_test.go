@@ -0,0 +1,42 @@
+package gocovutil
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestIndex(t *testing.T) {
+	src := "package p\n\nfunc F() {\n\tg := func() {\n\t}\n\tg()\n}\n\nfunc G() {}\n"
+	fsys := fstest.MapFS{"src/p/p.go": {Data: []byte(src)}}
+	// Offsets of "func F", "func() {" and "func G".
+	f := &gocov.Function{Name: "F", File: "/src/p/p.go", Start: 11, End: 46}
+	lit := &gocov.Function{Name: "F.func1", Parent: "F", File: "/src/p/p.go", Start: 28, End: 39}
+	g := &gocov.Function{Name: "G", File: "/src/p/p.go", Start: 48, End: 59}
+	var ps Packages
+	ps.AddPackage(&gocov.Package{Name: "example.com/p", Functions: []*gocov.Function{g, f, lit}})
+	ps.AddPackage(&gocov.Package{Name: "example.com/q"})
+
+	x := NewIndex(ps, fsys)
+	if p := x.ByImportPath("example.com/p"); p != ps[0] {
+		t.Errorf("ByImportPath(example.com/p) = %v, expected %v", p, ps[0])
+	}
+	if p := x.ByImportPath("example.com/r"); p != nil {
+		t.Errorf("ByImportPath(example.com/r) = %v, expected nil", p)
+	}
+	for line, expected := range map[int]*gocov.Function{
+		1: nil, 3: f, 4: lit, 5: lit, 6: f, 7: f, 8: nil, 9: g, 10: nil,
+	} {
+		fn, err := x.FunctionAt("/src/p/p.go", line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fn != expected {
+			t.Errorf("FunctionAt(%d) = %v, expected %v", line, fn, expected)
+		}
+	}
+	if fn, err := x.FunctionAt("/src/p/other.go", 1); fn != nil || err != nil {
+		t.Errorf("FunctionAt of an unknown file = %v, %v", fn, err)
+	}
+}
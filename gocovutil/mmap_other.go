@@ -0,0 +1,13 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris)
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package gocovutil
+
+import "io/ioutil"
+
+// mapFile reads the named file, as memory mapping is not supported on
+// this platform.
+func mapFile(filename string) ([]byte, func(), error) {
+	data, err := ioutil.ReadFile(filename)
+	return data, func() {}, err
+}
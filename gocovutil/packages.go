@@ -2,6 +2,7 @@ package gocovutil
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/hihoak/gocov"
 	"io/ioutil"
 	"os"
@@ -13,7 +14,7 @@ import (
 // coverage results into the set.
 type Packages []*gocov.Package
 
-// AddPackage adds a package's coverage information to the
+// AddPackage adds a package's coverage information to the set.
 func (ps *Packages) AddPackage(p *gocov.Package) {
 	i := sort.Search(len(*ps), func(i int) bool {
 		return (*ps)[i].Name >= p.Name
@@ -21,12 +22,149 @@ func (ps *Packages) AddPackage(p *gocov.Package) {
 	if i < len(*ps) && (*ps)[i].Name == p.Name {
 		(*ps)[i].Accumulate(p)
 	} else {
-		head := (*ps)[:i]
-		tail := append([]*gocov.Package{p}, (*ps)[i:]...)
-		*ps = append(head, tail...)
+		*ps = append(*ps, nil)
+		copy((*ps)[i+1:], (*ps)[i:])
+		(*ps)[i] = p
 	}
 }
 
+// AddPackages adds the coverage information of several packages to the
+// set, as AddPackage does for each of them. The set is indexed and sorted
+// once for all of the packages, rather than searched and shifted for each,
+// which makes merging large sets, such as the shards of a big repository,
+// take time linear in their size.
+func (ps *Packages) AddPackages(packages []*gocov.Package) {
+	ps.addAll(packages, func(existing, p *gocov.Package) error {
+		existing.Accumulate(p)
+		return nil
+	})
+}
+
+// MergePackage is like AddPackage, but tolerates packages whose sets of
+// functions differ, as when coverage is gathered for several platforms
+// or sets of build tags: matching functions are accumulated, and the rest
+// are added to the package.
+func (ps *Packages) MergePackage(p *gocov.Package) error {
+	i := sort.Search(len(*ps), func(i int) bool {
+		return (*ps)[i].Name >= p.Name
+	})
+	if i == len(*ps) || (*ps)[i].Name != p.Name {
+		ps.AddPackage(p)
+		return nil
+	}
+	return mergePackage((*ps)[i], p)
+}
+
+// MergePackages merges several packages into the set, as MergePackage
+// does for each of them, indexing and sorting the set once as AddPackages
+// does.
+func (ps *Packages) MergePackages(packages []*gocov.Package) error {
+	return ps.addAll(packages, func(existing, p *gocov.Package) error {
+		if err := mergePackage(existing, p); err != nil {
+			return fmt.Errorf("package %s: %v", p.Name, err)
+		}
+		return nil
+	})
+}
+
+// addAll adds packages to the set, combining each with the package of the
+// same name already in the set, if any, using combine. The set is left
+// sorted even if combine fails.
+func (ps *Packages) addAll(packages []*gocov.Package, combine func(existing, p *gocov.Package) error) error {
+	byName := make(map[string]*gocov.Package, len(*ps)+len(packages))
+	for _, p := range *ps {
+		byName[p.Name] = p
+	}
+	n := len(*ps)
+	var err error
+	for _, p := range packages {
+		if existing := byName[p.Name]; existing != nil {
+			if err = combine(existing, p); err != nil {
+				break
+			}
+			continue
+		}
+		byName[p.Name] = p
+		*ps = append(*ps, p)
+	}
+	if len(*ps) > n {
+		sort.Slice(*ps, func(i, j int) bool {
+			return (*ps)[i].Name < (*ps)[j].Name
+		})
+	}
+	return err
+}
+
+// mergePackage merges p into existing, a package of the same name:
+// matching functions and files are accumulated, and the rest are added.
+// Functions are matched by source range, or failing that by stable
+// identity (see StableID) if their statements are alike, as when they
+// were converted at revisions or in checkouts where the function had
+// moved within its file. Functions in files of different names are never
+// matched, as they may be the variants of a function for different
+// platforms.
+func mergePackage(existing, p *gocov.Package) error {
+	type funcKey struct {
+		file, name string
+		start, end int
+	}
+	functions := make(map[funcKey]*gocov.Function, len(existing.Functions))
+	byID := make(map[string]*gocov.Function, len(existing.Functions))
+	for _, f := range existing.Functions {
+		functions[funcKey{f.File, f.Name, f.Start, f.End}] = f
+		byID[StableID(existing, f)] = f
+	}
+	for _, f := range p.Functions {
+		if f2 := functions[funcKey{f.File, f.Name, f.Start, f.End}]; f2 != nil {
+			if err := f2.Accumulate(f); err != nil {
+				return err
+			}
+			continue
+		}
+		if f2 := byID[StableID(p, f)]; f2 != nil && accumulateMoved(f2, f) {
+			continue
+		}
+		existing.Functions = append(existing.Functions, f)
+	}
+	files := make(map[string]*gocov.File, len(existing.Files))
+	for _, f := range existing.Files {
+		files[f.File] = f
+	}
+	for _, f := range p.Files {
+		if f2 := files[f.File]; f2 != nil {
+			if err := f2.Accumulate(f); err != nil {
+				return err
+			}
+		} else {
+			existing.Files = append(existing.Files, f)
+		}
+	}
+	return nil
+}
+
+// accumulateMoved accumulates the coverage of f2 into f, the function it
+// was at another revision, if their statements have the same ranges
+// relative to the functions', and reports whether it did.
+func accumulateMoved(f, f2 *gocov.Function) bool {
+	if len(f.Statements) != len(f2.Statements) {
+		return false
+	}
+	for i, s := range f.Statements {
+		s2 := f2.Statements[i]
+		if s.Start-f.Start != s2.Start-f2.Start || s.End-f.Start != s2.End-f2.Start {
+			return false
+		}
+	}
+	delta := f.Start - f2.Start
+	for i, s := range f.Statements {
+		s2 := *f2.Statements[i]
+		s2.Start += delta
+		s2.End += delta
+		s.Accumulate(&s2)
+	}
+	return true
+}
+
 // ReadPackages takes a list of filenames and parses their
 // contents as a Packages object.
 //
@@ -74,9 +212,7 @@ func ReadPackages(filenames []string) (ps Packages, err error) {
 		if err != nil {
 			return nil, err
 		}
-		for _, p := range result.Packages {
-			ps.AddPackage(p)
-		}
+		ps.AddPackages(result.Packages)
 	}
 	return ps, nil
 }
@@ -0,0 +1,178 @@
+package gocovutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestMergePackage(t *testing.T) {
+	linux := &gocov.Package{Name: "p", Functions: []*gocov.Function{
+		{Name: "f", File: "f.go", Statements: []*gocov.Statement{{Reached: 1}}},
+		{Name: "g", File: "g_linux.go", Statements: []*gocov.Statement{{Reached: 1}}},
+	}}
+	windows := &gocov.Package{Name: "p", Functions: []*gocov.Function{
+		{Name: "f", File: "f.go", Statements: []*gocov.Statement{{Reached: 2}}},
+		{Name: "g", File: "g_windows.go", Statements: []*gocov.Statement{{Reached: 0}}},
+	}}
+
+	var ps Packages
+	if err := ps.MergePackage(linux); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.MergePackage(windows); err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 1 {
+		t.Fatalf("Expected 1 package, got %d", len(ps))
+	}
+	functions := ps[0].Functions
+	if len(functions) != 3 {
+		t.Fatalf("Expected 3 functions, got %d", len(functions))
+	}
+	if reached := functions[0].Statements[0].Reached; reached != 3 {
+		t.Errorf("Expected f to be reached 3 times, got %d", reached)
+	}
+	if functions[2].File != "g_windows.go" {
+		t.Errorf("Expected g_windows.go to be added, got %s", functions[2].File)
+	}
+}
+
+func TestAddPackages(t *testing.T) {
+	shard := func(reached int64, names ...string) []*gocov.Package {
+		var packages []*gocov.Package
+		for _, name := range names {
+			packages = append(packages, &gocov.Package{Name: name, Functions: []*gocov.Function{
+				{Name: "f", File: "f.go", Statements: []*gocov.Statement{{Reached: reached}}},
+			}})
+		}
+		return packages
+	}
+
+	var ps Packages
+	ps.AddPackage(shard(1, "b")[0])
+	ps.AddPackages(shard(2, "c", "a", "b"))
+	ps.AddPackages(shard(4, "a", "d"))
+	var names []string
+	for _, p := range ps {
+		names = append(names, p.Name)
+	}
+	if got := strings.Join(names, ","); got != "a,b,c,d" {
+		t.Fatalf("Expected packages a,b,c,d, got %s", got)
+	}
+	for i, want := range []int64{6, 3, 2, 4} {
+		if got := ps[i].Functions[0].Statements[0].Reached; got != want {
+			t.Errorf("Expected %s to be reached %d times, got %d", ps[i].Name, want, got)
+		}
+	}
+
+	// AddPackage keeps the set sorted as AddPackages does.
+	ps2 := Packages{}
+	for _, p := range shard(1, "c", "a", "d", "b") {
+		ps2.AddPackage(p)
+	}
+	for i, p := range ps2 {
+		if p.Name != names[i] {
+			t.Errorf("Expected package %d to be %s, got %s", i, names[i], p.Name)
+		}
+	}
+}
+
+func TestMergePackages(t *testing.T) {
+	ps := Packages{{Name: "p", Functions: []*gocov.Function{
+		{Name: "f", File: "f.go", Statements: []*gocov.Statement{{Reached: 1}}},
+	}}}
+	err := ps.MergePackages([]*gocov.Package{
+		{Name: "q"},
+		{Name: "p", Functions: []*gocov.Function{
+			{Name: "f", File: "f.go", Statements: []*gocov.Statement{{Reached: 1}, {Reached: 1}}},
+		}},
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "package p: ") {
+		t.Fatalf("Expected an error merging p, got %v", err)
+	}
+	if len(ps) != 2 || ps[0].Name != "p" || ps[1].Name != "q" {
+		t.Errorf("Expected the set to hold p and q, got %d packages", len(ps))
+	}
+}
+
+// BenchmarkAddPackages merges 100 shards of the coverage of a repository
+// of 5000 packages.
+func BenchmarkAddPackages(b *testing.B) {
+	const shards, packages = 100, 5000
+	for _, bench := range []struct {
+		name string
+		add  func(ps *Packages, shard []*gocov.Package)
+	}{
+		{"AddPackage", func(ps *Packages, shard []*gocov.Package) {
+			for _, p := range shard {
+				ps.AddPackage(p)
+			}
+		}},
+		{"AddPackages", func(ps *Packages, shard []*gocov.Package) {
+			ps.AddPackages(shard)
+		}},
+		{"MergePackages", func(ps *Packages, shard []*gocov.Package) {
+			if err := ps.MergePackages(shard); err != nil {
+				b.Fatal(err)
+			}
+		}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				b.StopTimer()
+				input := make([][]*gocov.Package, shards)
+				for i := range input {
+					input[i] = benchmarkShard(packages, i)
+				}
+				b.StartTimer()
+				var ps Packages
+				for _, shard := range input {
+					bench.add(&ps, shard)
+				}
+			}
+		})
+	}
+}
+
+// benchmarkShard returns the coverage of one shard of a repository, with
+// its packages in an order depending on the shard.
+func benchmarkShard(packages, seed int) []*gocov.Package {
+	shard := make([]*gocov.Package, packages)
+	for i := range shard {
+		name := fmt.Sprintf("example.com/repo/pkg%d", (i*7919+seed*104729)%packages)
+		p := &gocov.Package{Name: name}
+		for j := 0; j < 4; j++ {
+			p.Functions = append(p.Functions, &gocov.Function{
+				Name:       fmt.Sprintf("F%d", j),
+				File:       "f.go",
+				Start:      j * 100,
+				End:        j*100 + 99,
+				Statements: []*gocov.Statement{{Reached: 1}, {Reached: 0}, {Reached: int64(seed)}},
+			})
+		}
+		shard[i] = p
+	}
+	return shard
+}
+
+func TestMergePackageMoved(t *testing.T) {
+	fn := func(start int, reached int64) *gocov.Function {
+		return &gocov.Function{Name: "f", File: "/src/p/f.go", Start: start, End: start + 20,
+			ID:         FunctionID("p", "f.go", "f", "()"),
+			Statements: []*gocov.Statement{{Start: start + 5, End: start + 10, Reached: reached}}}
+	}
+	ps := Packages{{Name: "p", Functions: []*gocov.Function{fn(10, 1)}}}
+	if err := ps.MergePackage(&gocov.Package{Name: "p", Functions: []*gocov.Function{fn(100, 2)}}); err != nil {
+		t.Fatal(err)
+	}
+	functions := ps[0].Functions
+	if len(functions) != 1 {
+		t.Fatalf("Expected the moved function to be merged, got %d functions", len(functions))
+	}
+	if s := functions[0].Statements[0]; s.Start != 15 || s.Reached != 3 {
+		t.Errorf("Expected the statement at 15 to be reached 3 times, got %d at %d", s.Reached, s.Start)
+	}
+}
@@ -0,0 +1,49 @@
+package gocovutil
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ExactPercentage returns reached as an exact percentage of total, which
+// is 0 if total is 0.
+func ExactPercentage(reached, total int) *big.Rat {
+	if total == 0 {
+		return new(big.Rat)
+	}
+	return big.NewRat(int64(reached)*100, int64(total))
+}
+
+// ExactDecimal returns the decimal a percentage such as a configured
+// threshold was written as, such as 57.1, rather than the float64
+// nearest to it.
+func ExactDecimal(f float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return r
+}
+
+// FormatDecimal formats r with the given number of decimal places and
+// decimal separator, rounded toward zero, so that a percentage is never
+// shown higher than it is: 79.999 is formatted as 79.99 with two decimal
+// places.
+func FormatDecimal(r *big.Rat, precision int, separator string) string {
+	// The value in units of the last decimal place.
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)
+	q := new(big.Rat).Mul(r, new(big.Rat).SetInt(pow))
+	n := new(big.Int).Quo(q.Num(), q.Denom())
+	sign := ""
+	if q.Sign() < 0 {
+		sign = "-"
+		n.Neg(n)
+	}
+	digits := n.String()
+	if precision == 0 {
+		return sign + digits
+	}
+	if len(digits) <= precision {
+		digits = strings.Repeat("0", precision-len(digits)+1) + digits
+	}
+	i := len(digits) - precision
+	return sign + digits[:i] + separator + digits[i:]
+}
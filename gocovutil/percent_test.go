@@ -0,0 +1,34 @@
+package gocovutil
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		r         *big.Rat
+		precision int
+		separator string
+		want      string
+	}{
+		{ExactPercentage(2, 3), 2, ".", "66.66"},
+		{ExactPercentage(57, 100), 2, ".", "57.00"},
+		{ExactPercentage(1, 1000000), 2, ".", "0.00"},
+		{ExactPercentage(0, 0), 1, ".", "0.0"},
+		{ExactPercentage(7995, 10000), 3, ",", "79,950"},
+		{ExactPercentage(7995, 10000), 0, ".", "79"},
+		{big.NewRat(-1, 1000), 2, ".", "-0.00"},
+	}
+	for _, test := range tests {
+		if got := FormatDecimal(test.r, test.precision, test.separator); got != test.want {
+			t.Errorf("FormatDecimal(%s, %d, %q) = %q, want %q", test.r, test.precision, test.separator, got, test.want)
+		}
+	}
+	if ExactPercentage(57, 100).Cmp(ExactDecimal(57)) != 0 {
+		t.Errorf("Expected 57 of 100 to be exactly 57%%")
+	}
+	if ExactDecimal(57.1).Cmp(big.NewRat(571, 10)) != 0 {
+		t.Errorf("ExactDecimal(57.1) = %s, want 571/10", ExactDecimal(57.1))
+	}
+}
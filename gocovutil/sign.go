@@ -0,0 +1,84 @@
+package gocovutil
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// ErrUnsigned is returned by VerifyDocument for documents that carry no
+// signature.
+var ErrUnsigned = errors.New("document is not signed")
+
+// signedContent returns the content a document's signature covers: its
+// JSON encoding without the signature.
+func signedContent(d *Document) ([]byte, error) {
+	unsigned := *d
+	unsigned.Signature = ""
+	return json.Marshal(&unsigned)
+}
+
+// SignDocument signs the document with the ed25519 private key, storing
+// the signature in the document.
+func SignDocument(d *Document, key ed25519.PrivateKey) error {
+	data, err := signedContent(d)
+	if err != nil {
+		return err
+	}
+	d.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+	return nil
+}
+
+// VerifyDocument checks that the document carries a valid signature by
+// the ed25519 public key's private key, so that coverage used for gating
+// cannot be tampered with between the job that produced it and the one
+// that reads it.
+func VerifyDocument(d *Document, key ed25519.PublicKey) error {
+	if d.Signature == "" {
+		return ErrUnsigned
+	}
+	sig, err := base64.StdEncoding.DecodeString(d.Signature)
+	if err != nil {
+		return fmt.Errorf("malformed signature: %v", err)
+	}
+	data, err := signedContent(d)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, data, sig) {
+		return errors.New("signature verification failed")
+	}
+	return nil
+}
+
+// readKey reads a base64 encoded key of the given size from a file.
+func readKey(filename string, size int) ([]byte, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("malformed key in %s: %v", filename, err)
+	}
+	if len(key) != size {
+		return nil, fmt.Errorf("malformed key in %s: got %d bytes, want %d", filename, len(key), size)
+	}
+	return key, nil
+}
+
+// ReadPrivateKey reads a base64 encoded ed25519 private key from a file.
+func ReadPrivateKey(filename string) (ed25519.PrivateKey, error) {
+	key, err := readKey(filename, ed25519.PrivateKeySize)
+	return ed25519.PrivateKey(key), err
+}
+
+// ReadPublicKey reads a base64 encoded ed25519 public key from a file.
+func ReadPublicKey(filename string) (ed25519.PublicKey, error) {
+	key, err := readKey(filename, ed25519.PublicKeySize)
+	return ed25519.PublicKey(key), err
+}
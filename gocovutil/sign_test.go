@@ -0,0 +1,30 @@
+package gocovutil
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/hihoak/gocov"
+)
+
+func TestSignDocument(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &Document{Packages: Packages{{Name: "p"}}, Labels: map[string]string{"suite": "unit"}}
+	if err := VerifyDocument(d, pub); err != ErrUnsigned {
+		t.Errorf("Expected ErrUnsigned, got %v", err)
+	}
+	if err := SignDocument(d, priv); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyDocument(d, pub); err != nil {
+		t.Errorf("Expected a valid signature, got %v", err)
+	}
+
+	d.Packages = append(d.Packages, &gocov.Package{Name: "q"})
+	if err := VerifyDocument(d, pub); err == nil {
+		t.Errorf("Expected tampering to be detected")
+	}
+}
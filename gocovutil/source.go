@@ -0,0 +1,53 @@
+package gocovutil
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadSource reads the source file with the given absolute path. If fsys
+// is nil the operating system's file system is used; otherwise the file
+// is opened in fsys by its path without the leading slash or volume name,
+// as for os.DirFS("/"), so that sources may come from embedded snapshots,
+// archives of CI workspaces or overlays.
+func ReadSource(fsys fs.FS, abspath string) ([]byte, error) {
+	if fsys == nil {
+		return ioutil.ReadFile(abspath)
+	}
+	return fs.ReadFile(fsys, SourcePath(abspath))
+}
+
+// MapSource returns the contents of the source file with the given
+// absolute path, as for ReadSource, memory-mapping files of the operating
+// system's file system on platforms that support it, which saves copying
+// large sources into memory. The contents are read-only and only valid
+// until release is called.
+func MapSource(fsys fs.FS, abspath string) (data []byte, release func(), err error) {
+	if fsys != nil {
+		data, err := ReadSource(fsys, abspath)
+		return data, func() {}, err
+	}
+	return mapFile(abspath)
+}
+
+// SourcePath converts an absolute path to the form used to open it in a
+// file system passed to ReadSource.
+func SourcePath(abspath string) string {
+	abspath = filepath.ToSlash(abspath)
+	if len(abspath) >= 2 && abspath[1] == ':' {
+		abspath = abspath[2:]
+	}
+	return strings.TrimPrefix(abspath, "/")
+}
+
+// OpenSource opens the source file with the given absolute path, as for
+// ReadSource, for callers that read it incrementally.
+func OpenSource(fsys fs.FS, abspath string) (fs.File, error) {
+	if fsys == nil {
+		return os.Open(abspath)
+	}
+	return fsys.Open(SourcePath(abspath))
+}
@@ -0,0 +1,55 @@
+package gocovutil
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSourcePath(t *testing.T) {
+	for _, name := range []string{"/src/foo.go", "C:/src/foo.go"} {
+		if got := SourcePath(name); got != "src/foo.go" {
+			t.Errorf("SourcePath(%q) = %q, expected %q", name, got, "src/foo.go")
+		}
+	}
+}
+
+func TestReadSource(t *testing.T) {
+	fsys := fstest.MapFS{"src/foo.go": {Data: []byte("package foo\n")}}
+	data, err := ReadSource(fsys, "/src/foo.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "package foo\n" {
+		t.Errorf("Unexpected source %q", data)
+	}
+}
+
+func TestMapSource(t *testing.T) {
+	dir := t.TempDir()
+	for _, content := range []string{"package foo\n", ""} {
+		name := filepath.Join(dir, "foo.go")
+		if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		data, release, err := MapSource(nil, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("Unexpected source %q, expected %q", data, content)
+		}
+		release()
+	}
+
+	fsys := fstest.MapFS{"src/foo.go": {Data: []byte("package foo\n")}}
+	data, release, err := MapSource(fsys, "/src/foo.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	if string(data) != "package foo\n" {
+		t.Errorf("Unexpected source %q", data)
+	}
+}